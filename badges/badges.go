@@ -0,0 +1,179 @@
+// Package badges defines the achievement badges and the engine that awards
+// them. Handlers evaluate the badges an event can affect as it happens; the
+// evaluate-badges job re-checks everything to backfill and to catch badges
+// that depend on reputation.
+package badges
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/google/uuid"
+)
+
+const (
+	FirstPost        = "first_post"
+	HundredUpvotes   = "hundred_upvotes"
+	TopContributor   = "top_contributor"
+	HelpfulCommenter = "helpful_commenter"
+)
+
+const (
+	hundredUpvotesThreshold  = 100
+	helpfulCommentsThreshold = 10
+	topContributorMaxRank    = 3
+	topContributorMinScore   = 100
+	topContributorWindow     = 30 * 24 * time.Hour
+	badgeAwardedNotification = "badge_awarded"
+)
+
+type Definition struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// PerField badges are awarded once for each expertise field.
+	PerField bool `json:"per_field"`
+}
+
+var Definitions = []Definition{
+	{
+		Key:         FirstPost,
+		Name:        "First Post",
+		Description: "Published a first post.",
+	},
+	{
+		Key:         HundredUpvotes,
+		Name:        "Hundred Upvotes",
+		Description: fmt.Sprintf("Received %d upvotes from other readers.", hundredUpvotesThreshold),
+	},
+	{
+		Key:         TopContributor,
+		Name:        "Top Contributor",
+		Description: fmt.Sprintf("Ranked in the top %d of an expertise field by reputation earned over 30 days.", topContributorMaxRank),
+		PerField:    true,
+	},
+	{
+		Key:         HelpfulCommenter,
+		Name:        "Helpful Commenter",
+		Description: fmt.Sprintf("Wrote %d comments that other people replied to.", helpfulCommentsThreshold),
+	},
+}
+
+// Lookup returns the definition for a badge key.
+func Lookup(key string) (Definition, bool) {
+	for _, def := range Definitions {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Evaluate awards the user every badge among keys they now qualify for, or
+// checks all badges when no keys are given. Badges are never taken away.
+// It returns the badges newly awarded and notifies the user about each.
+func Evaluate(ctx context.Context, db *database.Queries, userID uuid.UUID, keys ...string) ([]database.UserBadge, error) {
+	check := map[string]bool{}
+	for _, def := range Definitions {
+		check[def.Key] = len(keys) == 0
+	}
+	for _, key := range keys {
+		check[key] = true
+	}
+
+	var earned []database.UserBadge
+	now := time.Now().UTC()
+
+	if check[FirstPost] || check[HundredUpvotes] || check[HelpfulCommenter] {
+		progress, err := db.GetBadgeProgress(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get badge progress: %v", err)
+		}
+		if check[FirstPost] && progress.PostCount >= 1 {
+			earned = append(earned, database.UserBadge{UserID: userID, Badge: FirstPost})
+		}
+		if check[HundredUpvotes] && progress.UpvotesReceived >= hundredUpvotesThreshold {
+			earned = append(earned, database.UserBadge{UserID: userID, Badge: HundredUpvotes})
+		}
+		if check[HelpfulCommenter] && progress.HelpfulComments >= helpfulCommentsThreshold {
+			earned = append(earned, database.UserBadge{UserID: userID, Badge: HelpfulCommenter})
+		}
+	}
+
+	if check[TopContributor] {
+		fields, err := db.ListTopContributorFields(ctx, database.ListTopContributorFieldsParams{
+			UserID:   userID,
+			Since:    now.Add(-topContributorWindow),
+			MaxRank:  topContributorMaxRank,
+			MinScore: topContributorMinScore,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to rank contributor: %v", err)
+		}
+		for _, field := range fields {
+			earned = append(earned, database.UserBadge{UserID: userID, Badge: TopContributor, Field: field})
+		}
+	}
+
+	var awarded []database.UserBadge
+	for _, badge := range earned {
+		badge.AwardedAt = now
+		// A badge and the notification about it are saved together, so a
+		// failed notification doesn't leave a badge nobody was told about.
+		var isNew bool
+		err := db.RunInTx(ctx, func(q *database.Queries) error {
+			rows, err := q.AwardBadge(ctx, database.AwardBadgeParams{
+				UserID:    badge.UserID,
+				Badge:     badge.Badge,
+				Field:     badge.Field,
+				AwardedAt: badge.AwardedAt,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to award %s: %v", badge.Badge, err)
+			}
+			if rows == 0 {
+				return nil // already held
+			}
+			isNew = true
+
+			if err := notify(ctx, q, badge); err != nil {
+				return fmt.Errorf("failed to notify about %s: %v", badge.Badge, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return awarded, err
+		}
+		if isNew {
+			awarded = append(awarded, badge)
+		}
+	}
+	return awarded, nil
+}
+
+func notify(ctx context.Context, db *database.Queries, badge database.UserBadge) error {
+	def, _ := Lookup(badge.Badge)
+	message := fmt.Sprintf("You earned the %s badge", def.Name)
+	if badge.Field != "" {
+		message += " in " + badge.Field
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"badge": badge.Badge,
+		"field": badge.Field,
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.CreateNotification(ctx, database.CreateNotificationParams{
+		NotificationID: uuid.New(),
+		UserID:         badge.UserID,
+		Type:           badgeAwardedNotification,
+		Message:        message,
+		Data:           data,
+	})
+}
@@ -13,10 +13,13 @@ require (
 
 require (
 	github.com/cloudinary/cloudinary-go/v2 v2.9.0
+	github.com/coreos/go-oidc/v3 v3.11.0
 	github.com/golang-jwt/jwt/v5 v5.2.1
+	golang.org/x/oauth2 v0.24.0
 )
 
 require (
 	github.com/creasty/defaults v1.7.0 // indirect
+	github.com/go-jose/go-jose/v4 v4.0.2 // indirect
 	github.com/gorilla/schema v1.4.1 // indirect
 )
@@ -0,0 +1,468 @@
+package handlers
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// accountDeletionGracePeriod is how long a deletion request can be cancelled
+// before the purge job removes the account for good.
+const accountDeletionGracePeriod = 30 * 24 * time.Hour
+
+type ReturnedAccountDeletion struct {
+	Scheduled    bool       `json:"scheduled"`
+	RequestedAt  *time.Time `json:"requested_at,omitempty"`
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+}
+
+type exportedProfile struct {
+	UserID      uuid.UUID         `json:"user_id"`
+	Name        string            `json:"name"`
+	Username    string            `json:"username"`
+	Email       string            `json:"email"`
+	Bio         string            `json:"bio"`
+	Headline    string            `json:"headline"`
+	Location    string            `json:"location"`
+	Website     string            `json:"website"`
+	Pronouns    string            `json:"pronouns"`
+	AvatarURL   string            `json:"avatar_url"`
+	SocialLinks map[string]string `json:"social_links"`
+	CreatedAt   *time.Time        `json:"created_at"`
+	UpdatedAt   *time.Time        `json:"updated_at"`
+}
+
+type exportedPost struct {
+	PostID    uuid.UUID  `json:"post_id"`
+	Slug      string     `json:"slug"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type exportedComment struct {
+	CommentID       uuid.UUID  `json:"comment_id"`
+	PostID          uuid.UUID  `json:"post_id"`
+	PostSlug        string     `json:"post_slug"`
+	ParentCommentID *uuid.UUID `json:"parent_comment_id"`
+	Content         string     `json:"content"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type exportedUpvote struct {
+	PostID    uuid.UUID  `json:"post_id"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+type exportedUser struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Name     string    `json:"name"`
+	Username string    `json:"username"`
+}
+
+type exportedReport struct {
+	ReportID        uuid.UUID  `json:"report_id"`
+	TargetUserID    uuid.UUID  `json:"target_user_id"`
+	TargetPostID    *uuid.UUID `json:"target_post_id"`
+	TargetCommentID *uuid.UUID `json:"target_comment_id"`
+	Reason          string     `json:"reason"`
+	Status          string     `json:"status"`
+	CreatedAt       *time.Time `json:"created_at"`
+	ReviewedAt      *time.Time `json:"reviewed_at"`
+}
+
+type exportedAppeal struct {
+	AppealID       uuid.UUID  `json:"appeal_id"`
+	TargetReportID uuid.UUID  `json:"target_report_id"`
+	Reason         string     `json:"reason"`
+	Status         string     `json:"status"`
+	CreatedAt      *time.Time `json:"created_at"`
+	ReviewedAt     *time.Time `json:"reviewed_at"`
+}
+
+type exportedIdentity struct {
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// accountExport is the full archive handed to a user asking for their data.
+// Each field becomes its own file in the ZIP version.
+type accountExport struct {
+	ExportedAt    time.Time              `json:"exported_at"`
+	Profile       exportedProfile        `json:"profile"`
+	Posts         []exportedPost         `json:"posts"`
+	Comments      []exportedComment      `json:"comments"`
+	Upvotes       []exportedUpvote       `json:"upvotes"`
+	SavedPosts    []exportedPost         `json:"saved_posts"`
+	Following     []exportedUser         `json:"following"`
+	Followers     []exportedUser         `json:"followers"`
+	ReportsFiled  []exportedReport       `json:"reports_filed"`
+	Appeals       []exportedAppeal       `json:"appeals"`
+	Identities    []exportedIdentity     `json:"linked_accounts"`
+	APIKeys       []ReturnedAPIKey       `json:"api_keys"`
+	Sessions      []ReturnedSession      `json:"sessions"`
+	Notifications []ReturnedNotification `json:"notifications"`
+}
+
+func nullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func buildAccountExport(db *database.Queries, r *http.Request, user database.User) (accountExport, error) {
+	ctx := r.Context()
+	export := accountExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: exportedProfile{
+			UserID:    user.UserID,
+			Name:      user.Name,
+			Username:  user.Username,
+			Email:     user.Email,
+			CreatedAt: nullTimePtr(user.CreatedAt),
+			UpdatedAt: nullTimePtr(user.UpdatedAt),
+		},
+	}
+
+	profile, err := getUserProfile(ctx, db, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("profile: %v", err)
+	}
+	export.Profile.Bio = profile.Bio
+	export.Profile.Headline = profile.Headline
+	export.Profile.Location = profile.Location
+	export.Profile.Website = profile.Website
+	export.Profile.Pronouns = profile.Pronouns
+	export.Profile.AvatarURL = profile.AvatarUrl
+	export.Profile.SocialLinks = decodeSocialLinks(profile.SocialLinks)
+
+	posts, err := db.GetPostsByContributor(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("posts: %v", err)
+	}
+	for _, post := range posts {
+		export.Posts = append(export.Posts, exportedPost{
+			PostID:    post.PostID,
+			Slug:      post.Slug,
+			Title:     post.Title,
+			Content:   post.Content,
+			CreatedAt: nullTimePtr(post.CreatedAt),
+			UpdatedAt: nullTimePtr(post.UpdatedAt),
+		})
+	}
+
+	comments, err := db.ExportCommentsByUser(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("comments: %v", err)
+	}
+	for _, comment := range comments {
+		export.Comments = append(export.Comments, exportedComment{
+			CommentID:       comment.CommentID,
+			PostID:          comment.PostID,
+			PostSlug:        comment.PostSlug,
+			ParentCommentID: nullUUIDPtr(comment.ParentCommentID),
+			Content:         comment.Content,
+			CreatedAt:       comment.CreatedAt,
+			UpdatedAt:       comment.UpdatedAt,
+		})
+	}
+
+	upvotes, err := db.ListUpvotesByUser(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("upvotes: %v", err)
+	}
+	for _, upvote := range upvotes {
+		export.Upvotes = append(export.Upvotes, exportedUpvote{
+			PostID:    upvote.PostID,
+			CreatedAt: nullTimePtr(upvote.CreatedAt),
+		})
+	}
+
+	savedPosts, err := db.ListSavedPostsByID(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("saved posts: %v", err)
+	}
+	for _, post := range savedPosts {
+		export.SavedPosts = append(export.SavedPosts, exportedPost{
+			PostID:    post.PostID,
+			Slug:      post.Slug,
+			Title:     post.Title,
+			Content:   post.Content,
+			CreatedAt: nullTimePtr(post.CreatedAt),
+			UpdatedAt: nullTimePtr(post.UpdatedAt),
+		})
+	}
+
+	following, err := db.GetFollowingList(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("following: %v", err)
+	}
+	for _, f := range following {
+		export.Following = append(export.Following, exportedUser{UserID: f.FollowingID, Name: f.Name, Username: f.Username})
+	}
+
+	followers, err := db.ExportFollowersByUser(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("followers: %v", err)
+	}
+	for _, f := range followers {
+		export.Followers = append(export.Followers, exportedUser{UserID: f.FollowerID, Name: f.Name, Username: f.Username})
+	}
+
+	reports, err := db.ExportReportsFiledByUser(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("reports: %v", err)
+	}
+	for _, report := range reports {
+		export.ReportsFiled = append(export.ReportsFiled, exportedReport{
+			ReportID:        report.ReportID,
+			TargetUserID:    report.TargetUserID,
+			TargetPostID:    nullUUIDPtr(report.TargetPostID),
+			TargetCommentID: nullUUIDPtr(report.TargetCommentID),
+			Reason:          report.Reason,
+			Status:          report.Status.String,
+			CreatedAt:       nullTimePtr(report.CreatedAt),
+			ReviewedAt:      nullTimePtr(report.ReviewedAt),
+		})
+	}
+
+	appeals, err := db.ExportAppealsByUser(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("appeals: %v", err)
+	}
+	for _, appeal := range appeals {
+		export.Appeals = append(export.Appeals, exportedAppeal{
+			AppealID:       appeal.AppealID,
+			TargetReportID: appeal.TargetReportID,
+			Reason:         appeal.Reason,
+			Status:         appeal.Status.String,
+			CreatedAt:      nullTimePtr(appeal.CreatedAt),
+			ReviewedAt:     nullTimePtr(appeal.ReviewedAt),
+		})
+	}
+
+	identities, err := db.ListUserIdentitiesByUser(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("linked accounts: %v", err)
+	}
+	for _, identity := range identities {
+		export.Identities = append(export.Identities, exportedIdentity{
+			Provider:    identity.Provider,
+			Email:       identity.Email.String,
+			CreatedAt:   identity.CreatedAt,
+			LastLoginAt: nullTimePtr(identity.LastLoginAt),
+		})
+	}
+
+	apiKeys, err := db.ListAPIKeysByOwner(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("api keys: %v", err)
+	}
+	for _, key := range apiKeys {
+		export.APIKeys = append(export.APIKeys, ReturnedAPIKey{
+			APIKeyID:   key.ApiKeyID,
+			Name:       key.Name,
+			KeyPrefix:  key.KeyPrefix,
+			Scopes:     key.Scopes,
+			ExpiresAt:  nullTimePtr(key.ExpiresAt),
+			LastUsedAt: nullTimePtr(key.LastUsedAt),
+			RevokedAt:  nullTimePtr(key.RevokedAt),
+			CreatedAt:  key.CreatedAt,
+		})
+	}
+
+	sessions, err := db.ListActiveSessionsByOwner(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("sessions: %v", err)
+	}
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, ReturnedSession{
+			SessionID:    session.SessionID,
+			Device:       session.Device,
+			UserAgent:    session.UserAgent,
+			IPAddress:    session.IpAddress,
+			Location:     session.Location.String,
+			CreatedAt:    session.CreatedAt,
+			LastActiveAt: session.LastActiveAt,
+			ExpiresAt:    session.ExpiresAt,
+		})
+	}
+
+	notifications, err := db.ListNotificationsByUser(ctx, user.UserID)
+	if err != nil {
+		return export, fmt.Errorf("notifications: %v", err)
+	}
+	for _, notification := range notifications {
+		export.Notifications = append(export.Notifications, ReturnedNotification{
+			NotificationID: notification.NotificationID,
+			Type:           notification.Type,
+			Message:        notification.Message,
+			Data:           notification.Data,
+			ReadAt:         nullTimePtr(notification.ReadAt),
+			CreatedAt:      notification.CreatedAt,
+		})
+	}
+
+	return export, nil
+}
+
+// ExportAccountHandler lets a user download everything we hold about them. The
+// default is a ZIP with one JSON file per section; ?format=json returns a
+// single document instead.
+func ExportAccountHandler(db *database.Queries, user database.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		export, err := buildAccountExport(db, r, user)
+		if err != nil {
+			http.Error(w, "Couldn't export account data", http.StatusInternalServerError)
+			return
+		}
+
+		filename := fmt.Sprintf("expertly-%s-%s", user.Username, export.ExportedAt.Format("20060102"))
+
+		if r.URL.Query().Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".json"))
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			encoder.Encode(export)
+			return
+		}
+
+		files := []struct {
+			name string
+			data interface{}
+		}{
+			{"profile.json", export.Profile},
+			{"posts.json", export.Posts},
+			{"comments.json", export.Comments},
+			{"upvotes.json", export.Upvotes},
+			{"saved_posts.json", export.SavedPosts},
+			{"following.json", export.Following},
+			{"followers.json", export.Followers},
+			{"reports_filed.json", export.ReportsFiled},
+			{"appeals.json", export.Appeals},
+			{"linked_accounts.json", export.Identities},
+			{"api_keys.json", export.APIKeys},
+			{"sessions.json", export.Sessions},
+			{"notifications.json", export.Notifications},
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+
+		archive := zip.NewWriter(w)
+		for _, file := range files {
+			f, err := archive.CreateHeader(&zip.FileHeader{
+				Name:     file.name,
+				Method:   zip.Deflate,
+				Modified: export.ExportedAt,
+			})
+			if err != nil {
+				return
+			}
+			encoder := json.NewEncoder(f)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(file.data); err != nil {
+				return
+			}
+		}
+		archive.Close()
+	}
+}
+
+func GetAccountDeletionHandler(db *database.Queries, user database.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		returned := ReturnedAccountDeletion{}
+
+		deletion, err := db.GetAccountDeletion(r.Context(), user.UserID)
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, "Couldn't get account deletion", http.StatusInternalServerError)
+			return
+		}
+		if err == nil {
+			returned = ReturnedAccountDeletion{
+				Scheduled:    true,
+				RequestedAt:  &deletion.RequestedAt,
+				ScheduledFor: &deletion.ScheduledFor,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	}
+}
+
+// ScheduleAccountDeletionHandler queues the account for deletion after the
+// grace period. Password accounts must confirm with their password; accounts
+// created through social login have none to confirm with.
+func ScheduleAccountDeletionHandler(db *database.Queries, user database.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		type parameters struct {
+			Password string `json:"password"`
+		}
+		params := parameters{}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+				http.Error(w, "Invalid request payload", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if user.Password != "" {
+			if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(params.Password)); err != nil {
+				http.Error(w, "Incorrect password", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		deletion, err := db.ScheduleAccountDeletion(r.Context(), database.ScheduleAccountDeletionParams{
+			UserID:       user.UserID,
+			ScheduledFor: time.Now().UTC().Add(accountDeletionGracePeriod),
+		})
+		if err != nil {
+			http.Error(w, "Couldn't schedule account deletion", http.StatusInternalServerError)
+			return
+		}
+
+		notifyUser(r.Context(), db, user.UserID, "account_deletion_scheduled",
+			"Your account is scheduled for deletion. You can cancel until "+deletion.ScheduledFor.Format("2 January 2006")+".",
+			map[string]interface{}{"scheduled_for": deletion.ScheduledFor})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(ReturnedAccountDeletion{
+			Scheduled:    true,
+			RequestedAt:  &deletion.RequestedAt,
+			ScheduledFor: &deletion.ScheduledFor,
+		})
+	}
+}
+
+func CancelAccountDeletionHandler(db *database.Queries, user database.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rows, err := db.CancelAccountDeletion(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't cancel account deletion", http.StatusInternalServerError)
+			return
+		}
+		if rows == 0 {
+			http.Error(w, "Account deletion is not scheduled", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ReturnedAccountDeletion{Scheduled: false})
+	}
+}
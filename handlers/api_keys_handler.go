@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	maxActiveAPIKeys          = 10
+	maxAPIKeyLifetimeDays     = 365
+	defaultAPIKeyLifetimeDays = 90
+)
+
+type ReturnedAPIKey struct {
+	APIKeyID   uuid.UUID  `json:"api_key_id"`
+	Name       string     `json:"name"`
+	KeyPrefix  string     `json:"key_prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// accountOwner returns the ID of whichever account is making the request, along
+// with the user_id/moderator_id column it belongs in.
+func accountOwner(user database.User, moderator database.Moderator) (uuid.UUID, uuid.NullUUID, uuid.NullUUID) {
+	if user.UserID != uuid.Nil {
+		return user.UserID, uuid.NullUUID{UUID: user.UserID, Valid: true}, uuid.NullUUID{}
+	}
+	return moderator.ModeratorID, uuid.NullUUID{}, uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true}
+}
+
+func CreateAPIKeyHandler(db *database.Queries, user database.User, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type parameters struct {
+			Name          string   `json:"name"`
+			Scopes        []string `json:"scopes"`
+			ExpiresInDays int      `json:"expires_in_days"`
+		}
+
+		var params parameters
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		params.Name = strings.TrimSpace(params.Name)
+		if params.Name == "" {
+			http.Error(w, "Name is required", http.StatusBadRequest)
+			return
+		}
+		if len(params.Scopes) == 0 {
+			http.Error(w, "At least one scope is required", http.StatusBadRequest)
+			return
+		}
+		for _, scope := range params.Scopes {
+			if !utils.IsValidAPIKeyScope(scope) {
+				http.Error(w, "Invalid scope: "+scope, http.StatusBadRequest)
+				return
+			}
+		}
+		// Every key expires; leaving expires_in_days out gives the default.
+		if params.ExpiresInDays == 0 {
+			params.ExpiresInDays = defaultAPIKeyLifetimeDays
+		}
+		if params.ExpiresInDays < 1 || params.ExpiresInDays > maxAPIKeyLifetimeDays {
+			http.Error(w, "expires_in_days must be between 1 and 365", http.StatusBadRequest)
+			return
+		}
+
+		ownerID, userID, moderatorID := accountOwner(user, moderator)
+
+		activeKeys, err := db.CountActiveAPIKeysByOwner(r.Context(), ownerID)
+		if err != nil {
+			http.Error(w, "Couldn't count api keys", http.StatusInternalServerError)
+			return
+		}
+		if activeKeys >= maxActiveAPIKeys {
+			http.Error(w, "Too many active api keys, revoke one first", http.StatusConflict)
+			return
+		}
+
+		key, prefix, err := utils.GenerateAPIKey()
+		if err != nil {
+			http.Error(w, "Couldn't generate api key", http.StatusInternalServerError)
+			return
+		}
+
+		expiresAt := sql.NullTime{Time: time.Now().UTC().AddDate(0, 0, params.ExpiresInDays), Valid: true}
+
+		apiKey, err := db.CreateAPIKey(r.Context(), database.CreateAPIKeyParams{
+			ApiKeyID:    uuid.New(),
+			UserID:      userID,
+			ModeratorID: moderatorID,
+			Name:        params.Name,
+			KeyPrefix:   prefix,
+			KeyHash:     utils.HashToken(key),
+			Scopes:      params.Scopes,
+			ExpiresAt:   expiresAt,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't create api key", http.StatusInternalServerError)
+			return
+		}
+
+		// The plaintext key is only ever returned here; we keep just its hash.
+		response := map[string]interface{}{
+			"api_key": ReturnedAPIKey{
+				APIKeyID:  apiKey.ApiKeyID,
+				Name:      apiKey.Name,
+				KeyPrefix: apiKey.KeyPrefix,
+				Scopes:    apiKey.Scopes,
+				ExpiresAt: nullTimePtr(apiKey.ExpiresAt),
+				CreatedAt: apiKey.CreatedAt,
+			},
+			"key": key,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(response)
+	})
+}
+
+func GetAPIKeysHandler(db *database.Queries, user database.User, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ownerID, _, _ := accountOwner(user, moderator)
+
+		keys, err := db.ListAPIKeysByOwner(r.Context(), ownerID)
+		if err != nil {
+			http.Error(w, "Couldn't get api keys", http.StatusInternalServerError)
+			return
+		}
+
+		returnedKeys := make([]ReturnedAPIKey, len(keys))
+		for i, key := range keys {
+			returnedKeys[i] = ReturnedAPIKey{
+				APIKeyID:   key.ApiKeyID,
+				Name:       key.Name,
+				KeyPrefix:  key.KeyPrefix,
+				Scopes:     key.Scopes,
+				ExpiresAt:  nullTimePtr(key.ExpiresAt),
+				LastUsedAt: nullTimePtr(key.LastUsedAt),
+				RevokedAt:  nullTimePtr(key.RevokedAt),
+				CreatedAt:  key.CreatedAt,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returnedKeys)
+	})
+}
+
+func RevokeAPIKeyHandler(db *database.Queries, user database.User, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKeyID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid api key ID", http.StatusBadRequest)
+			return
+		}
+
+		ownerID, _, _ := accountOwner(user, moderator)
+
+		revoked, err := db.RevokeAPIKey(r.Context(), database.RevokeAPIKeyParams{
+			ApiKeyID: apiKeyID,
+			OwnerID:  ownerID,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't revoke api key", http.StatusInternalServerError)
+			return
+		}
+		if revoked == 0 {
+			http.Error(w, "Api key not found", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/MyoMyatMin/expertly-backend/pkg/database"
 	"github.com/go-chi/chi/v5"
@@ -53,6 +52,8 @@ func UpdateAppealStatus(db *database.Queries, moderator database.Moderator) http
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		type parameters struct {
 			Status string `json:"status"`
+			// Reason is kept in the moderation audit log.
+			Reason string `json:"reason"`
 		}
 
 		var params parameters
@@ -71,9 +72,27 @@ func UpdateAppealStatus(db *database.Queries, moderator database.Moderator) http
 			http.Error(w, "Invalid appeal ID", http.StatusBadRequest)
 			return
 		}
+		if !checkModerationClaim(w, r, db, moderator, QueueAppeal, appealID) {
+			return
+		}
+
+		appeal, err := db.GetAppealById(r.Context(), appealID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// The decision and everything an upheld appeal undoes are saved
+		// together with their audit entries.
+		tx, q, err := db.Begin(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
 
 		status := sql.NullString{String: params.Status, Valid: params.Status != ""}
-		_, err = db.UpdateAppealStatus(r.Context(), database.UpdateAppealStatusParams{
+		_, err = q.UpdateAppealStatus(r.Context(), database.UpdateAppealStatusParams{
 			AppealID:   appealID,
 			Status:     status,
 			Reviewedby: uuid.NullUUID(uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true}),
@@ -83,43 +102,55 @@ func UpdateAppealStatus(db *database.Queries, moderator database.Moderator) http
 			return
 		}
 
+		if err := recordModerationAction(r.Context(), q, moderator.ModeratorID, moderationAction{
+			Action:        ActionAppealDecided,
+			TargetType:    "appeal",
+			TargetID:      appealID,
+			SubjectUserID: uuid.NullUUID{UUID: appeal.AppealedBy, Valid: true},
+			Before:        map[string]interface{}{"status": appeal.AppealStatus.String},
+			After:         map[string]interface{}{"status": params.Status},
+			Reason:        params.Reason,
+		}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var notices []notification
 		if params.Status == "resolved" {
-			appeal, err := db.GetAppealById(r.Context(), appealID)
+			report, err := q.GetReportById(r.Context(), appeal.TargetReportID)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
 
-			report, err := db.GetReportById(r.Context(), appeal.TargetReportID)
-			if err != nil {
+			// Only the suspensions from the appealed report, or from its
+			// case as a whole, are lifted; any others the user has keep
+			// running.
+			if err := liftReportSuspensions(r.Context(), q, moderator, report.CaseID, report.ReportID, appealID, params.Reason); err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
 
-			user, err := db.GetUserById(r.Context(), appeal.AppealedBy)
+			// An upheld appeal also brings back content taken down in the case
+			// and takes back its strike.
+			notices, err = restoreCaseContent(r.Context(), q, moderator, report.CaseID, appeal.AppealedBy, params.Reason)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
-
-			var newSuspendedUntil sql.NullTime
-			if report.SuspendDays.Int32 == 0 {
-				newSuspendedUntil = sql.NullTime{Valid: false}
-			} else {
-				newSuspendedUntil = sql.NullTime{Time: user.SuspendedUntil.Time.AddDate(0, 0, -int(report.SuspendDays.Int32)), Valid: true}
-				if newSuspendedUntil.Time.Before(time.Now()) {
-					newSuspendedUntil = sql.NullTime{Valid: false}
-				}
-			}
-			err = db.UpdateUserSuspension(r.Context(), database.UpdateUserSuspensionParams{
-				UserID:         user.UserID,
-				SuspendedUntil: newSuspendedUntil,
-			})
-			if err != nil {
+			if err := revokeCaseStrike(r.Context(), q, moderator, report.CaseID, params.Reason); err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
 		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendNotifications(r.Context(), db, notices)
+		if validStatuses[params.Status] {
+			releaseDecidedItem(r.Context(), db, QueueAppeal, appealID)
+		}
 
 		w.WriteHeader(http.StatusOK)
 	})
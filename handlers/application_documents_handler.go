@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Application document kinds.
+const (
+	DocumentIdentity  = "identity"
+	DocumentExpertise = "expertise"
+)
+
+const (
+	maxDocumentSize = 10 << 20 // 10 MB
+	// maxExpertiseDocuments caps the supporting files on one application.
+	maxExpertiseDocuments = 5
+	// documentURLExpiry is how long a moderator's signed link stays valid.
+	documentURLExpiry = 15 * time.Minute
+	// identityDocumentRetention is how long identity documents are kept
+	// after an application is approved or rejected.
+	identityDocumentRetention = 30 * 24 * time.Hour
+)
+
+// allowedDocumentTypes maps the sniffed content type to the extension the
+// file is stored with.
+var allowedDocumentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+}
+
+type ReturnedApplicationDocument struct {
+	DocumentID    uuid.UUID  `json:"document_id"`
+	Kind          string     `json:"kind"`
+	ContentType   string     `json:"content_type"`
+	SizeBytes     int64      `json:"size_bytes"`
+	OriginalName  string     `json:"original_name"`
+	CreatedAt     time.Time  `json:"created_at"`
+	URL           string     `json:"url,omitempty"`
+	URLExpiresAt  *time.Time `json:"url_expires_at,omitempty"`
+	DeleteAfter   *time.Time `json:"delete_after"`
+	ApplicationID *uuid.UUID `json:"application_id"`
+}
+
+func toReturnedApplicationDocument(doc database.ApplicationDocument) ReturnedApplicationDocument {
+	return ReturnedApplicationDocument{
+		DocumentID:    doc.DocumentID,
+		Kind:          doc.Kind,
+		ContentType:   doc.ContentType,
+		SizeBytes:     doc.SizeBytes,
+		OriginalName:  doc.OriginalName,
+		CreatedAt:     doc.CreatedAt,
+		DeleteAfter:   nullTimePtr(doc.DeleteAfter),
+		ApplicationID: nullUUIDPtr(doc.ApplicationID),
+	}
+}
+
+// UploadApplicationDocumentHandler accepts a multipart "file" (PDF, JPEG, PNG
+// or WebP, up to 10 MB) and a "kind" of identity or expertise. The file goes
+// to private storage; its ID is then passed when applying.
+func UploadApplicationDocumentHandler(db *database.Queries, store storage.Storage, user database.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if store == nil {
+			http.Error(w, "Document uploads aren't available", http.StatusServiceUnavailable) // 503
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxDocumentSize+1<<10)
+		if err := r.ParseMultipartForm(maxDocumentSize); err != nil {
+			http.Error(w, "Document must be at most 10 MB", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		kind := r.FormValue("kind")
+		if kind != DocumentIdentity && kind != DocumentExpertise {
+			http.Error(w, "kind must be identity or expertise", http.StatusBadRequest)
+			return
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Missing file", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		if header.Size > maxDocumentSize {
+			http.Error(w, "Document must be at most 10 MB", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if header.Size == 0 {
+			http.Error(w, "Document is empty", http.StatusBadRequest)
+			return
+		}
+
+		// Trust the bytes, not the client-supplied Content-Type.
+		sniff := make([]byte, 512)
+		n, _ := io.ReadFull(file, sniff)
+		contentType := http.DetectContentType(sniff[:n])
+		ext, ok := allowedDocumentTypes[contentType]
+		if !ok {
+			http.Error(w, "Document must be a PDF, JPEG, PNG or WebP file", http.StatusUnsupportedMediaType)
+			return
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			http.Error(w, "Couldn't read document", http.StatusInternalServerError)
+			return
+		}
+
+		documentID := uuid.New()
+		key := fmt.Sprintf("applications/%s/%s%s", user.UserID, documentID, ext)
+		if err := store.Put(r.Context(), key, file, header.Size, contentType); err != nil {
+			fmt.Println(err)
+			http.Error(w, "Couldn't upload document", http.StatusBadGateway)
+			return
+		}
+
+		document, err := db.CreateApplicationDocument(r.Context(), database.CreateApplicationDocumentParams{
+			DocumentID:   documentID,
+			UserID:       uuid.NullUUID{UUID: user.UserID, Valid: true},
+			Kind:         kind,
+			StorageKey:   key,
+			ContentType:  contentType,
+			SizeBytes:    header.Size,
+			OriginalName: filepath.Base(header.Filename),
+			CreatedAt:    time.Now().UTC(),
+		})
+		if err != nil {
+			store.Delete(context.Background(), key)
+			http.Error(w, "Couldn't save document", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated) // 201
+		json.NewEncoder(w).Encode(toReturnedApplicationDocument(document))
+	}
+}
+
+// checkApplicationDocuments makes sure the documents named in an application
+// belong to the user, have the right kind and aren't attached to another
+// application. It writes the error response itself.
+func checkApplicationDocuments(w http.ResponseWriter, r *http.Request, db *database.Queries, userID uuid.UUID, applicationID uuid.NullUUID, params applicationParameters) ([]uuid.UUID, bool) {
+	if len(params.ExpertiseDocumentIDs) > maxExpertiseDocuments {
+		http.Error(w, fmt.Sprintf("Attach at most %d expertise documents", maxExpertiseDocuments), http.StatusBadRequest)
+		return nil, false
+	}
+
+	kinds := map[uuid.UUID]string{}
+	for _, id := range params.ExpertiseDocumentIDs {
+		kinds[id] = DocumentExpertise
+	}
+	if params.IdentityDocumentID != nil {
+		kinds[*params.IdentityDocumentID] = DocumentIdentity
+	}
+	if len(kinds) == 0 {
+		return nil, true
+	}
+
+	ids := make([]uuid.UUID, 0, len(kinds))
+	for id := range kinds {
+		ids = append(ids, id)
+	}
+
+	documents, err := db.ListApplicationDocumentsByIDs(r.Context(), database.ListApplicationDocumentsByIDsParams{
+		UserID:      uuid.NullUUID{UUID: userID, Valid: true},
+		DocumentIds: ids,
+	})
+	if err != nil {
+		http.Error(w, "Couldn't get documents", http.StatusInternalServerError)
+		return nil, false
+	}
+	if len(documents) != len(ids) {
+		http.Error(w, "Unknown document", http.StatusBadRequest)
+		return nil, false
+	}
+	for _, doc := range documents {
+		if doc.Kind != kinds[doc.DocumentID] {
+			http.Error(w, fmt.Sprintf("Document %s is not an %s document", doc.DocumentID, kinds[doc.DocumentID]), http.StatusBadRequest)
+			return nil, false
+		}
+		if doc.ApplicationID.Valid && doc.ApplicationID != applicationID {
+			http.Error(w, fmt.Sprintf("Document %s belongs to another application", doc.DocumentID), http.StatusConflict)
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+func attachApplicationDocuments(ctx context.Context, db *database.Queries, userID, applicationID uuid.UUID, documentIDs []uuid.UUID) error {
+	if len(documentIDs) == 0 {
+		return nil
+	}
+	_, err := db.AttachApplicationDocuments(ctx, database.AttachApplicationDocumentsParams{
+		ApplicationID: uuid.NullUUID{UUID: applicationID, Valid: true},
+		UserID:        uuid.NullUUID{UUID: userID, Valid: true},
+		DocumentIds:   documentIDs,
+	})
+	return err
+}
+
+// canSeeApplicationDocuments reports whether the moderator is reviewing the
+// open application, either as its reviewer or by holding its queue claim or
+// assignment.
+func canSeeApplicationDocuments(ctx context.Context, db *database.Queries, moderator database.Moderator, application database.GetContributorApplicationRow) (bool, error) {
+	if !isOpenApplication(applicationStatus(application.Status)) {
+		return false, nil
+	}
+	if application.ReviewedBy == (uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true}) {
+		return true, nil
+	}
+
+	claim, err := db.GetModerationClaim(ctx, database.GetModerationClaimParams{
+		ItemType:  QueueApplication,
+		ItemID:    application.ContriAppID,
+		ExpiresAt: time.Now().UTC(),
+	})
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return claim.ClaimedBy == moderator.ModeratorID, nil
+}
+
+// GetApplicationDocumentsHandler lists an application's documents with
+// short-lived signed links. Only the moderator reviewing the application, or
+// the one holding it in the moderation queue, can see them, and only while
+// it's open.
+func GetApplicationDocumentsHandler(db *database.Queries, store storage.Storage, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parsedID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid contributor application ID", http.StatusBadRequest)
+			return
+		}
+
+		application, err := db.GetContributorApplication(r.Context(), parsedID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Couldn't get application", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get application", http.StatusInternalServerError)
+			return
+		}
+
+		allowed, err := canSeeApplicationDocuments(r.Context(), db, moderator, application)
+		if err != nil {
+			http.Error(w, "Couldn't check the application's claim", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, "Only the moderator reviewing this application can see its documents", http.StatusForbidden)
+			return
+		}
+
+		documents, err := db.ListApplicationDocuments(r.Context(), uuid.NullUUID{UUID: parsedID, Valid: true})
+		if err != nil {
+			http.Error(w, "Couldn't get documents", http.StatusInternalServerError)
+			return
+		}
+
+		if store == nil {
+			http.Error(w, "Document storage isn't available", http.StatusServiceUnavailable) // 503
+			return
+		}
+
+		expiresAt := time.Now().UTC().Add(documentURLExpiry)
+		returned := make([]ReturnedApplicationDocument, len(documents))
+		for i, doc := range documents {
+			url, err := store.SignedURL(r.Context(), doc.StorageKey, documentURLExpiry)
+			if err != nil {
+				fmt.Println(err)
+				http.Error(w, "Couldn't sign document links", http.StatusInternalServerError)
+				return
+			}
+			returned[i] = toReturnedApplicationDocument(doc)
+			returned[i].URL = url
+			returned[i].URLExpiresAt = &expiresAt
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"documents": returned,
+		})
+	})
+}
+
+// ServeStoredFileHandler serves files from the local storage backend to
+// holders of a valid signed URL. Other backends sign their own URLs, so the
+// route 404s for them.
+func ServeStoredFileHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		local, ok := store.(*storage.Local)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		key := chi.URLParam(r, "*")
+		file, err := local.Open(key, r.URL.Query().Get("expires"), r.URL.Query().Get("signature"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			http.Error(w, "Couldn't read file", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, filepath.Base(key), info.ModTime(), file)
+	}
+}
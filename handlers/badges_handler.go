@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/badges"
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ReturnedBadgeDefinition struct {
+	badges.Definition
+	Holders int64 `json:"holders"`
+}
+
+type ReturnedUserBadge struct {
+	Badge       string    `json:"badge"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Field       string    `json:"field,omitempty"`
+	AwardedAt   time.Time `json:"awarded_at"`
+}
+
+// awardBadges runs the badge engine for the badges an event can affect.
+// Failures are only logged: the request already succeeded, and the
+// evaluate-badges job will award anything missed here.
+func awardBadges(ctx context.Context, db *database.Queries, userID uuid.UUID, keys ...string) {
+	if _, err := badges.Evaluate(ctx, db, userID, keys...); err != nil {
+		fmt.Printf("Failed to evaluate badges for %s: %v\n", userID, err)
+	}
+}
+
+// GetBadgeDefinitionsHandler lists every badge that can be earned and how
+// many users hold it.
+func GetBadgeDefinitionsHandler(db *database.Queries) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counts, err := db.CountBadgeHolders(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't get badges", http.StatusInternalServerError)
+			return
+		}
+		holders := map[string]int64{}
+		for _, c := range counts {
+			holders[c.Badge] = c.Holders
+		}
+
+		returned := make([]ReturnedBadgeDefinition, len(badges.Definitions))
+		for i, def := range badges.Definitions {
+			returned[i] = ReturnedBadgeDefinition{
+				Definition: def,
+				Holders:    holders[def.Key],
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
+
+func GetUserBadgesHandler(db *database.Queries) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := db.GetIDbyUsername(r.Context(), chi.URLParam(r, "username"))
+		if err != nil {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+
+		userBadges, err := db.ListUserBadges(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Couldn't get badges", http.StatusInternalServerError)
+			return
+		}
+
+		returned := make([]ReturnedUserBadge, 0, len(userBadges))
+		for _, b := range userBadges {
+			def, ok := badges.Lookup(b.Badge)
+			if !ok {
+				continue // retired badge
+			}
+			returned = append(returned, ReturnedUserBadge{
+				Badge:       b.Badge,
+				Name:        def.Name,
+				Description: def.Description,
+				Field:       b.Field,
+				AwardedAt:   b.AwardedAt,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
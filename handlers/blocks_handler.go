@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ReturnedBlockedUser struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// hiddenUserSet returns the users whose posts and comments the viewer has
+// chosen not to see, i.e. everyone they muted or blocked.
+func hiddenUserSet(ctx context.Context, db *database.Queries, viewerID uuid.UUID) (map[uuid.UUID]bool, error) {
+	hidden := map[uuid.UUID]bool{}
+	if viewerID == uuid.Nil {
+		return hidden, nil
+	}
+
+	userIDs, err := db.ListHiddenUserIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range userIDs {
+		hidden[userID] = true
+	}
+	return hidden, nil
+}
+
+// isBlockedBy reports whether blockerID has blocked userID.
+func isBlockedBy(ctx context.Context, db *database.Queries, blockerID, userID uuid.UUID) (bool, error) {
+	return db.IsBlocked(ctx, database.IsBlockedParams{
+		BlockerID: blockerID,
+		BlockedID: userID,
+	})
+}
+
+// targetUserID resolves the {username} URL parameter, rejecting the caller's
+// own account.
+func targetUserID(w http.ResponseWriter, r *http.Request, db *database.Queries, user database.User) (uuid.UUID, bool) {
+	targetID, err := db.GetIDbyUsername(r.Context(), chi.URLParam(r, "username"))
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return uuid.Nil, false
+	}
+	if targetID == user.UserID {
+		http.Error(w, "You can't do that to yourself", http.StatusUnprocessableEntity)
+		return uuid.Nil, false
+	}
+	return targetID, true
+}
+
+// BlockUserHandler blocks a user: they can no longer follow the blocker or
+// comment on their posts and comments, and any follow in either direction is
+// removed.
+func BlockUserHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blockedID, ok := targetUserID(w, r, db, user)
+		if !ok {
+			return
+		}
+
+		// The block and the follows it ends land together, so a failure can't
+		// leave a block with the follows still in place.
+		var rows int64
+		err := db.RunInTx(r.Context(), func(q *database.Queries) error {
+			var err error
+			rows, err = q.CreateBlock(r.Context(), database.CreateBlockParams{
+				BlockerID: user.UserID,
+				BlockedID: blockedID,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, follow := range []database.DeleteFollowParams{
+				{FollowerID: user.UserID, FollowingID: blockedID},
+				{FollowerID: blockedID, FollowingID: user.UserID},
+			} {
+				if _, err := q.DeleteFollow(r.Context(), follow); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			http.Error(w, "Couldn't block user", http.StatusInternalServerError)
+			return
+		}
+
+		if rows == 0 {
+			w.WriteHeader(http.StatusOK) // already blocked
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func UnblockUserHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blockedID, ok := targetUserID(w, r, db, user)
+		if !ok {
+			return
+		}
+
+		rows, err := db.DeleteBlock(r.Context(), database.DeleteBlockParams{
+			BlockerID: user.UserID,
+			BlockedID: blockedID,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't unblock user", http.StatusInternalServerError)
+			return
+		}
+		if rows == 0 {
+			http.Error(w, "User is not blocked", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// MuteUserHandler hides a user's posts and comments from the caller without
+// them being told; unlike a block it doesn't restrict what they can do.
+func MuteUserHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutedID, ok := targetUserID(w, r, db, user)
+		if !ok {
+			return
+		}
+
+		rows, err := db.CreateMute(r.Context(), database.CreateMuteParams{
+			MuterID: user.UserID,
+			MutedID: mutedID,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't mute user", http.StatusInternalServerError)
+			return
+		}
+
+		if rows == 0 {
+			w.WriteHeader(http.StatusOK) // already muted
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func UnmuteUserHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutedID, ok := targetUserID(w, r, db, user)
+		if !ok {
+			return
+		}
+
+		rows, err := db.DeleteMute(r.Context(), database.DeleteMuteParams{
+			MuterID: user.UserID,
+			MutedID: mutedID,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't unmute user", http.StatusInternalServerError)
+			return
+		}
+		if rows == 0 {
+			http.Error(w, "User is not muted", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func GetBlockedUsersHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blocked, err := db.ListBlockedUsers(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get blocked users", http.StatusInternalServerError)
+			return
+		}
+
+		returned := make([]ReturnedBlockedUser, len(blocked))
+		for i, b := range blocked {
+			returned[i] = ReturnedBlockedUser{
+				UserID:    b.BlockedID,
+				Name:      b.Name,
+				Username:  b.Username,
+				CreatedAt: b.CreatedAt,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
+
+func GetMutedUsersHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		muted, err := db.ListMutedUsers(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get muted users", http.StatusInternalServerError)
+			return
+		}
+
+		returned := make([]ReturnedBlockedUser, len(muted))
+		for i, m := range muted {
+			returned[i] = ReturnedBlockedUser{
+				UserID:    m.MutedID,
+				Name:      m.Name,
+				Username:  m.Username,
+				CreatedAt: m.CreatedAt,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/MyoMyatMin/expertly-backend/badges"
 	"github.com/MyoMyatMin/expertly-backend/pkg/database"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -19,6 +20,8 @@ type Comment struct {
 	Username        string        `json:"username"`
 	Name            string        `json:"name"`
 	Replies         []*Comment    `json:"replies"` // Change to slice of pointers
+	// Moderation is set when moderators hid, removed or labeled the comment.
+	Moderation *ReturnedContentModeration `json:"moderation,omitempty"`
 }
 
 func CreateCommentHandler(db *database.Queries, user database.User) http.Handler {
@@ -47,6 +50,40 @@ func CreateCommentHandler(db *database.Queries, user database.User) http.Handler
 			return
 		}
 
+		post, err := db.GetPost(r.Context(), postID)
+		if err != nil {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+		blocked, err := isBlockedBy(r.Context(), db, post.UserID, user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't check blocks", http.StatusInternalServerError)
+			return
+		}
+		if blocked {
+			http.Error(w, "You can't comment on this user's posts", http.StatusForbidden)
+			return
+		}
+
+		var parentAuthorID uuid.UUID
+		if params.ParentCommentID.Valid {
+			parent, err := db.GetCommentByID(r.Context(), params.ParentCommentID.UUID)
+			if err != nil || parent.PostID != postID {
+				http.Error(w, "Parent comment not found", http.StatusNotFound)
+				return
+			}
+			parentAuthorID = parent.UserID
+			blocked, err := isBlockedBy(r.Context(), db, parent.UserID, user.UserID)
+			if err != nil {
+				http.Error(w, "Couldn't check blocks", http.StatusInternalServerError)
+				return
+			}
+			if blocked {
+				http.Error(w, "You can't reply to this user", http.StatusForbidden)
+				return
+			}
+		}
+
 		comment, err := db.CreateComment(r.Context(), database.CreateCommentParams{
 			CommentID:       uuid.New(),
 			Content:         params.Content,
@@ -60,6 +97,10 @@ func CreateCommentHandler(db *database.Queries, user database.User) http.Handler
 			return
 		}
 
+		if parentAuthorID != uuid.Nil && parentAuthorID != user.UserID {
+			awardBadges(r.Context(), db, parentAuthorID, badges.HelpfulCommenter)
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(comment); err != nil {
 			http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
@@ -124,7 +165,7 @@ func DeleteCommentHandler(db *database.Queries, user database.User) http.Handler
 	})
 }
 
-func GetAllCommentsByPostHandler(db *database.Queries) http.Handler {
+func GetAllCommentsByPostHandler(db *database.Queries, user database.User, moderator database.Moderator) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		postSlug := chi.URLParam(r, "postSlug")
 		PostID, err := db.GetPostBySlug(r.Context(), postSlug)
@@ -145,6 +186,12 @@ func GetAllCommentsByPostHandler(db *database.Queries) http.Handler {
 
 		fmt.Println(len(dbcomments))
 
+		hidden, err := hiddenUserSet(r.Context(), db, user.UserID)
+		if err != nil {
+			http.Error(w, "Failed to get muted users: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
 		var comments []Comment
 
 		for _, dbcomment := range dbcomments {
@@ -160,7 +207,33 @@ func GetAllCommentsByPostHandler(db *database.Queries) http.Handler {
 
 		}
 
-		nestedComments := BuildNestedComments(comments)
+		nestedComments := BuildNestedComments(comments, hidden)
+
+		takedowns, err := db.ListActiveCommentTakedownsByPost(r.Context(), postID)
+		if err != nil {
+			http.Error(w, "Failed to get comments: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		byComment := make(map[uuid.UUID]database.ContentTakedown, len(takedowns))
+		for _, takedown := range takedowns {
+			byComment[takedown.CommentID.UUID] = takedown
+		}
+		// Taken-down comments stay in the tree as tombstones so their replies
+		// keep their place. Moderators see them all and authors their own
+		// hidden ones.
+		for i := range comments {
+			takedown, ok := byComment[comments[i].ID]
+			if !ok {
+				continue
+			}
+			comments[i].Moderation = toReturnedContentModeration(takedown, "comment")
+			if hidesContent(takedown, user, moderator.ModeratorID != uuid.Nil, comments[i].UserID) {
+				comments[i].Content = ""
+				comments[i].UserID = uuid.Nil
+				comments[i].Username = ""
+				comments[i].Name = ""
+			}
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(nestedComments); err != nil {
@@ -169,11 +242,17 @@ func GetAllCommentsByPostHandler(db *database.Queries) http.Handler {
 	})
 }
 
-func BuildNestedComments(comments []Comment) []*Comment {
+// BuildNestedComments turns a flat list into reply trees. Comments written by
+// users in hidden (muted or blocked by the viewer) are left out together with
+// the replies under them.
+func BuildNestedComments(comments []Comment, hidden map[uuid.UUID]bool) []*Comment {
 	commentMap := make(map[uuid.UUID]*Comment)
 
 	// Initialize map with pointers to each comment in the original slice
 	for i := range comments {
+		if hidden[comments[i].UserID] {
+			continue
+		}
 		comment := &comments[i]        // Pointer to the original comment in the slice
 		comment.Replies = []*Comment{} // Initialize Replies as slice of pointers
 		commentMap[comment.ID] = comment
@@ -182,7 +261,10 @@ func BuildNestedComments(comments []Comment) []*Comment {
 	var nestedComments []*Comment
 
 	for i := range comments {
-		comment := commentMap[comments[i].ID]
+		comment, ok := commentMap[comments[i].ID]
+		if !ok {
+			continue
+		}
 		if !comment.ParentCommentID.Valid {
 			// Add top-level comments to the result
 			nestedComments = append(nestedComments, comment)
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/google/uuid"
+)
+
+// Takedown actions a moderator can take on reported content when resolving
+// its case. Hidden and removed content drops out of listings and readers see
+// a tombstone. Moderators still see both, and authors still see their hidden
+// content. Labeled content stays up with the label shown next to it.
+const (
+	TakedownHide   = "hide"
+	TakedownRemove = "remove"
+	TakedownLabel  = "label"
+)
+
+const maxTakedownLabelLength = 200
+
+var takedownMessages = map[string]string{
+	TakedownHide:   "This %s has been hidden by moderators",
+	TakedownRemove: "This %s was removed for breaking the community guidelines",
+}
+
+type ReturnedContentModeration struct {
+	Action    string    `json:"action"`
+	Label     string    `json:"label,omitempty"`
+	Message   string    `json:"message"`
+	CaseID    uuid.UUID `json:"case_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toReturnedContentModeration(takedown database.ContentTakedown, kind string) *ReturnedContentModeration {
+	message := takedown.Label
+	if format, ok := takedownMessages[takedown.Action]; ok {
+		message = fmt.Sprintf(format, kind)
+	}
+	return &ReturnedContentModeration{
+		Action:    takedown.Action,
+		Label:     takedown.Label,
+		Message:   message,
+		CaseID:    takedown.CaseID,
+		CreatedAt: takedown.CreatedAt,
+	}
+}
+
+// hidesContent reports whether the viewer gets a tombstone instead of
+// content written by authorID. Moderators see hidden and removed content so
+// it can be reviewed and appealed, and authors still see their hidden content.
+func hidesContent(takedown database.ContentTakedown, viewer database.User, isModerator bool, authorID uuid.UUID) bool {
+	if isModerator {
+		return false
+	}
+	switch takedown.Action {
+	case TakedownRemove:
+		return true
+	case TakedownHide:
+		return viewer.UserID != authorID
+	}
+	return false
+}
+
+// validateTakedown checks the takedown requested alongside a report
+// decision, writing the error response itself. An empty action leaves the
+// content alone.
+func validateTakedown(w http.ResponseWriter, action string, label *string, status, targetType string) bool {
+	*label = strings.TrimSpace(*label)
+	if action == "" {
+		return true
+	}
+	if action != TakedownHide && action != TakedownRemove && action != TakedownLabel {
+		http.Error(w, "content_action must be hide, remove or label", http.StatusBadRequest)
+		return false
+	}
+	if status != CaseResolved {
+		http.Error(w, "content_action only applies to resolved reports", http.StatusBadRequest)
+		return false
+	}
+	if targetType != "post" && targetType != "comment" {
+		http.Error(w, "content_action only applies to reported posts and comments", http.StatusBadRequest)
+		return false
+	}
+	if action == TakedownLabel && *label == "" {
+		http.Error(w, "content_label is required to label content", http.StatusBadRequest)
+		return false
+	}
+	if len(*label) > maxTakedownLabelLength {
+		http.Error(w, "content_label must be at most 200 characters", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// takeDownReportedContent applies a takedown to the post or comment a case
+// is about, replacing any earlier takedown of it, and returns the
+// notification telling the author.
+func takeDownReportedContent(ctx context.Context, db *database.Queries, moderator database.Moderator, reportCase database.ReportCase, action, label, reason string) (notification, error) {
+	var notice notification
+	err := db.RunInTx(ctx, func(q *database.Queries) error {
+		now := time.Now().UTC()
+
+		// A reported comment's case also carries its post, so the comment wins.
+		postID := reportCase.TargetPostID
+		if reportCase.TargetCommentID.Valid {
+			postID = uuid.NullUUID{}
+		}
+
+		if err := q.LiftActiveTakedown(ctx, database.LiftActiveTakedownParams{
+			RestoredBy: uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+			RestoredAt: sql.NullTime{Time: now, Valid: true},
+			PostID:     postID,
+			CommentID:  reportCase.TargetCommentID,
+		}); err != nil {
+			return err
+		}
+
+		takedown, err := q.CreateContentTakedown(ctx, database.CreateContentTakedownParams{
+			TakedownID:  uuid.New(),
+			CaseID:      reportCase.CaseID,
+			PostID:      postID,
+			CommentID:   reportCase.TargetCommentID,
+			Action:      action,
+			Label:       label,
+			TakenDownBy: moderator.ModeratorID,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+
+		kind, targetID := "post", takedown.PostID.UUID
+		if takedown.CommentID.Valid {
+			kind, targetID = "comment", takedown.CommentID.UUID
+		}
+		auditAction := ActionContentRemoved
+		if action == TakedownLabel {
+			auditAction = ActionContentLabeled
+		}
+		if err := recordModerationAction(ctx, q, moderator.ModeratorID, moderationAction{
+			Action:        auditAction,
+			TargetType:    kind,
+			TargetID:      targetID,
+			SubjectUserID: uuid.NullUUID{UUID: reportCase.TargetUserID, Valid: true},
+			Before:        map[string]interface{}{"state": "visible"},
+			After:         map[string]interface{}{"state": action, "label": label, "case_id": reportCase.CaseID},
+			Reason:        reason,
+		}); err != nil {
+			return err
+		}
+
+		notice = notification{
+			UserID:  reportCase.TargetUserID,
+			Type:    "content_" + action,
+			Message: toReturnedContentModeration(takedown, kind).Message,
+			Data: map[string]interface{}{
+				kind + "_id": targetID,
+				"case_id":    reportCase.CaseID,
+				"label":      label,
+			},
+		}
+		return nil
+	})
+	return notice, err
+}
+
+// restoreCaseContent lifts every active takedown made for a case, once an
+// appeal against it is upheld, and returns the notifications telling the
+// author.
+func restoreCaseContent(ctx context.Context, db *database.Queries, moderator database.Moderator, caseID, userID uuid.UUID, reason string) ([]notification, error) {
+	var notices []notification
+	err := db.RunInTx(ctx, func(q *database.Queries) error {
+		restored, err := q.RestoreCaseTakedowns(ctx, database.RestoreCaseTakedownsParams{
+			CaseID:     caseID,
+			RestoredBy: uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+			RestoredAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, takedown := range restored {
+			kind, targetID := "post", takedown.PostID.UUID
+			if takedown.CommentID.Valid {
+				kind, targetID = "comment", takedown.CommentID.UUID
+			}
+			if err := recordModerationAction(ctx, q, moderator.ModeratorID, moderationAction{
+				Action:        ActionContentRestored,
+				TargetType:    kind,
+				TargetID:      targetID,
+				SubjectUserID: uuid.NullUUID{UUID: userID, Valid: true},
+				Before:        map[string]interface{}{"state": takedown.Action, "label": takedown.Label},
+				After:         map[string]interface{}{"state": "visible"},
+				Reason:        reason,
+			}); err != nil {
+				return err
+			}
+			notices = append(notices, notification{
+				UserID:  userID,
+				Type:    "content_restored",
+				Message: fmt.Sprintf("Your %s has been restored", kind),
+				Data: map[string]interface{}{
+					kind + "_id": targetID,
+					"case_id":    caseID,
+				},
+			})
+		}
+		return nil
+	})
+	return notices, err
+}
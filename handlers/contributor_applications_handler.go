@@ -3,36 +3,185 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/MyoMyatMin/expertly-backend/pkg/database"
 	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
 
 	"github.com/google/uuid"
 )
 
+// Contributor application statuses.
+const (
+	ApplicationPending       = "pending"
+	ApplicationUnderReview   = "under_review"
+	ApplicationApproved      = "approved"
+	ApplicationRejected      = "rejected"
+	ApplicationNeedsMoreInfo = "needs_more_info"
+)
+
+// resubmitCooldown is how long a rejected applicant waits before applying
+// again.
+const resubmitCooldown = 30 * 24 * time.Hour
+
+// applicationTransitions lists where a moderator can move an application
+// from each status. needs_more_info goes back to pending only when the
+// applicant updates the application.
+var applicationTransitions = map[string][]string{
+	ApplicationPending:     {ApplicationUnderReview},
+	ApplicationUnderReview: {ApplicationApproved, ApplicationRejected, ApplicationNeedsMoreInfo},
+}
+
+type ReturnedContributorApplication struct {
+	ContriAppID       uuid.UUID  `json:"contri_app_id"`
+	Status            string     `json:"status"`
+	RequestedFields   []string   `json:"requested_fields"`
+	ExpertiseProofs   []string   `json:"expertise_proofs"`
+	IdentityProof     string     `json:"identity_proof"`
+	InitialSubmission string     `json:"initial_submission"`
+	ReviewerNotes     string     `json:"reviewer_notes"`
+	CreatedAt         *time.Time `json:"created_at"`
+	ReviewedAt        *time.Time `json:"reviewed_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+func canTransitionApplication(from, to string) bool {
+	for _, status := range applicationTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func applicationStatus(status sql.NullString) string {
+	if !status.Valid {
+		return ApplicationPending
+	}
+	return status.String
+}
+
+func isOpenApplication(status string) bool {
+	return status == ApplicationPending || status == ApplicationUnderReview || status == ApplicationNeedsMoreInfo
+}
+
+// applicationEligibility finds the user's open application, if any, and when
+// they may next apply after their latest rejection.
+func applicationEligibility(applications []database.ListContributorApplicationsByUserRow) (open *database.ListContributorApplicationsByUserRow, canApplyAt time.Time) {
+	for i, app := range applications {
+		status := applicationStatus(app.Status)
+		if isOpenApplication(status) && open == nil {
+			open = &applications[i]
+		}
+		if status == ApplicationRejected && app.ReviewedAt.Valid {
+			if until := app.ReviewedAt.Time.Add(resubmitCooldown); until.After(canApplyAt) {
+				canApplyAt = until
+			}
+		}
+	}
+	return open, canApplyAt
+}
+
+// isUniqueViolation reports whether err broke the named unique constraint or
+// index.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+type applicationParameters struct {
+	ExpertiseFields   []string `json:"expertiseFields"`
+	ExpertiseProofs   []string `json:"expertiseLinks"`
+	IdentityProof     string   `json:"identityProofUrl"`
+	InitialSubmission string   `json:"submission"`
+	// Uploaded documents; see UploadApplicationDocumentHandler.
+	IdentityDocumentID   *uuid.UUID  `json:"identityDocumentId"`
+	ExpertiseDocumentIDs []uuid.UUID `json:"expertiseDocumentIds"`
+}
+
+// decodeApplication reads and validates an application body, writing the
+// error response itself when it's invalid.
+func decodeApplication(w http.ResponseWriter, r *http.Request, db *database.Queries) (applicationParameters, bool) {
+	var params applicationParameters
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return params, false
+	}
+
+	fields, err := normalizeExpertiseFields(r.Context(), db, params.ExpertiseFields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return params, false
+	}
+	if len(fields) == 0 || len(fields) > maxRequestedFields {
+		http.Error(w, fmt.Sprintf("Choose between 1 and %d expertise fields", maxRequestedFields), http.StatusBadRequest)
+		return params, false
+	}
+	params.ExpertiseFields = fields
+
+	return params, true
+}
+
+// CreateContributorApplication submits a new application. A user can have
+// only one open application, and must wait out the cooldown after a
+// rejection.
 func CreateContributorApplication(db *database.Queries, user database.User) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		type parameters struct {
-			ExpertiseProofs   []string `json:"expertiseLinks"`
-			IdentityProof     string   `json:"identityProofUrl"`
-			InitialSubmission string   `json:"submission"`
+		params, ok := decodeApplication(w, r, db)
+		if !ok {
+			return
 		}
 
-		var params parameters
+		existing, err := db.ListContributorApplicationsByUser(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get your applications", http.StatusInternalServerError)
+			return
+		}
+		open, canApplyAt := applicationEligibility(existing)
+		if open != nil {
+			http.Error(w, "You already have an open application", http.StatusConflict)
+			return
+		}
+		if time.Now().Before(canApplyAt) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(canApplyAt).Seconds())+1))
+			http.Error(w, "You can apply again after "+canApplyAt.UTC().Format(time.RFC3339), http.StatusTooManyRequests)
+			return
+		}
 
-		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		documentIDs, ok := checkApplicationDocuments(w, r, db, user.UserID, uuid.NullUUID{}, params)
+		if !ok {
 			return
 		}
-		fmt.Println(params)
-		applicaition, err := db.ApplyContributorApplication(r.Context(), database.ApplyContributorApplicationParams{
-			UserID:            user.UserID,
-			ExpertiseProofs:   params.ExpertiseProofs,
-			IdentityProof:     params.IdentityProof,
-			InitialSubmission: params.InitialSubmission,
+
+		// The application and its documents are saved together.
+		var applicaition database.ApplyContributorApplicationRow
+		err = db.RunInTx(r.Context(), func(q *database.Queries) error {
+			var err error
+			applicaition, err = q.ApplyContributorApplication(r.Context(), database.ApplyContributorApplicationParams{
+				ContriAppID:       uuid.New(),
+				UserID:            user.UserID,
+				ExpertiseProofs:   params.ExpertiseProofs,
+				IdentityProof:     params.IdentityProof,
+				InitialSubmission: params.InitialSubmission,
+				RequestedFields:   params.ExpertiseFields,
+			})
+			if err != nil {
+				return err
+			}
+			return attachApplicationDocuments(r.Context(), q, user.UserID, applicaition.ContriAppID, documentIDs)
 		})
+		// Two submissions racing past the check above; the index keeps one.
+		if isUniqueViolation(err, "idx_contributor_applications_one_open") {
+			http.Error(w, "You already have an open application", http.StatusConflict)
+			return
+		}
 		if err != nil {
 			fmt.Println(err)
 			http.Error(w, "Couldn't create application", http.StatusInternalServerError)
@@ -53,8 +202,19 @@ func UpdateContributorApplication(db *database.Queries, moderator database.Moder
 			return
 		}
 
+		type approvedField struct {
+			Field             string     `json:"field"`
+			VerificationLevel string     `json:"verification_level"`
+			ExpiresAt         *time.Time `json:"expires_at"`
+		}
 		type parameters struct {
 			Status string `json:"app_status"`
+			// ReviewerNotes are shown to the applicant; required when
+			// rejecting or asking for more information.
+			ReviewerNotes string `json:"reviewer_notes"`
+			// ApprovedFields is the subset of the requested fields the
+			// moderator verified; required when approving.
+			ApprovedFields []approvedField `json:"approved_fields"`
 		}
 
 		var params parameters
@@ -65,43 +225,330 @@ func UpdateContributorApplication(db *database.Queries, moderator database.Moder
 			return
 		}
 
-		if contri_data.ReviewedAt.Valid {
-			http.Error(w, "Application already reviewed", http.StatusConflict)
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if !checkModerationClaim(w, r, db, moderator, QueueApplication, parsedID) {
 			return
 		}
-		application, err := db.UpdateContributorApplication(r.Context(), database.UpdateContributorApplicationParams{
-			ContriAppID: parsedID,
-			Status:      sql.NullString{String: params.Status, Valid: true},
-			ReviewedBy:  uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
-		})
+
+		currentStatus := applicationStatus(contri_data.Status)
+		if !canTransitionApplication(currentStatus, params.Status) {
+			http.Error(w, fmt.Sprintf("Can't move an application from %s to %q", currentStatus, params.Status), http.StatusConflict)
+			return
+		}
+
+		params.ReviewerNotes = strings.TrimSpace(params.ReviewerNotes)
+		if (params.Status == ApplicationRejected || params.Status == ApplicationNeedsMoreInfo) && params.ReviewerNotes == "" {
+			http.Error(w, "reviewer_notes are required to reject or ask for more information", http.StatusBadRequest)
+			return
+		}
+
+		// Applications from before the taxonomy didn't request fields, so any
+		// field in it may be approved for them.
+		requested := map[string]bool{}
+		for _, field := range contri_data.RequestedFields {
+			requested[field] = true
+		}
+
+		approved := make([]approvedField, 0, len(params.ApprovedFields))
+		if params.Status == ApplicationApproved {
+			names := make([]string, len(params.ApprovedFields))
+			for i, f := range params.ApprovedFields {
+				names[i] = f.Field
+			}
+			if _, err := normalizeExpertiseFields(r.Context(), db, names); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			seen := map[string]bool{}
+			for _, f := range params.ApprovedFields {
+				f.Field = strings.ToLower(strings.TrimSpace(f.Field))
+				if f.Field == "" {
+					continue
+				}
+				if len(requested) > 0 && !requested[f.Field] {
+					http.Error(w, fmt.Sprintf("%q wasn't requested in this application", f.Field), http.StatusBadRequest)
+					return
+				}
+				if seen[f.Field] {
+					continue
+				}
+				if f.VerificationLevel == "" {
+					f.VerificationLevel = VerificationVerified
+				}
+				if !isValidVerificationLevel(f.VerificationLevel) {
+					http.Error(w, "verification_level must be basic, verified or expert", http.StatusBadRequest)
+					return
+				}
+				if f.ExpiresAt != nil && !f.ExpiresAt.After(time.Now()) {
+					http.Error(w, "expires_at must be in the future", http.StatusBadRequest)
+					return
+				}
+				seen[f.Field] = true
+				approved = append(approved, f)
+			}
+			if len(approved) == 0 {
+				http.Error(w, "Approve at least one expertise field", http.StatusBadRequest)
+				return
+			}
+		}
+
+		// The decision, what it grants and its audit entry are saved together.
+		tx, q, err := db.Begin(r.Context())
 		if err != nil {
-			fmt.Println(err)
 			http.Error(w, "Couldn't update application", http.StatusInternalServerError)
 			return
 		}
+		defer tx.Rollback()
 
-		_, err = db.CreateContributor(r.Context(), database.CreateContributorParams{
-			UserID:          contri_data.UserID,
-			ExpertiseFields: contri_data.ExpertiseProofs,
+		application, err := q.UpdateContributorApplication(r.Context(), database.UpdateContributorApplicationParams{
+			ContriAppID:   parsedID,
+			Status:        sql.NullString{String: params.Status, Valid: true},
+			ReviewedBy:    uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+			ReviewerNotes: params.ReviewerNotes,
+			FromStatus:    sql.NullString{String: currentStatus, Valid: true},
 		})
-
+		if err == sql.ErrNoRows {
+			http.Error(w, "Application was changed by someone else; reload and try again", http.StatusConflict)
+			return
+		}
 		if err != nil {
 			fmt.Println(err)
-			http.Error(w, "Couldn't create contributor", http.StatusInternalServerError)
+			http.Error(w, "Couldn't update application", http.StatusInternalServerError)
 			return
 		}
 
+		var reinstatedFrom string
+		var reinstatedContributor *database.Contributor
+		if params.Status == ApplicationApproved {
+			fields := make([]string, len(approved))
+			for i, f := range approved {
+				fields[i] = f.Field
+			}
+
+			contributor, err := q.GetContributorByUserId(r.Context(), contri_data.UserID)
+			switch {
+			case err == sql.ErrNoRows:
+				_, err = q.CreateContributor(r.Context(), database.CreateContributorParams{
+					UserID:          contri_data.UserID,
+					ExpertiseFields: fields,
+				})
+				if err != nil {
+					fmt.Println(err)
+					http.Error(w, "Couldn't create contributor", http.StatusInternalServerError)
+					return
+				}
+			case err != nil:
+				http.Error(w, "Couldn't check contributor", http.StatusInternalServerError)
+				return
+			case contributor.Status != ContributorActive:
+				// A new approval reinstates a lapsed or revoked contributor.
+				reinstated, err := q.SetContributorStatus(r.Context(), database.SetContributorStatusParams{
+					UserID:       contri_data.UserID,
+					Status:       ContributorActive,
+					StatusReason: "Contributor application approved",
+					FromStatus:   contributor.Status,
+				})
+				if err == nil {
+					reinstated, err = q.SetContributorExpiry(r.Context(), database.SetContributorExpiryParams{
+						UserID: contri_data.UserID,
+					})
+				}
+				if err == nil {
+					err = recordContributorStatusChange(r.Context(), q, contributor.Status, reinstated, "Contributor application approved",
+						uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true})
+				}
+				if err != nil {
+					http.Error(w, "Couldn't reinstate contributor", http.StatusInternalServerError)
+					return
+				}
+				reinstatedFrom, reinstatedContributor = contributor.Status, &reinstated
+			}
+
+			now := time.Now().UTC()
+			for _, f := range approved {
+				expiresAt := sql.NullTime{}
+				if f.ExpiresAt != nil {
+					expiresAt = sql.NullTime{Time: f.ExpiresAt.UTC(), Valid: true}
+				}
+				if err := q.UpsertContributorExpertise(r.Context(), database.UpsertContributorExpertiseParams{
+					UserID:            contri_data.UserID,
+					Field:             f.Field,
+					VerificationLevel: f.VerificationLevel,
+					ApplicationID:     uuid.NullUUID{UUID: parsedID, Valid: true},
+					VerifiedBy:        uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+					VerifiedAt:        now,
+					ExpiresAt:         expiresAt,
+				}); err != nil {
+					http.Error(w, "Couldn't save verified expertise", http.StatusInternalServerError)
+					return
+				}
+			}
+			if err := q.SyncContributorExpertiseFields(r.Context(), sql.NullTime{Time: now, Valid: true}); err != nil {
+				http.Error(w, "Couldn't save verified expertise", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// Identity documents are only needed for the decision itself.
+		if params.Status == ApplicationApproved || params.Status == ApplicationRejected {
+			if err := q.ScheduleIdentityDocumentDeletion(r.Context(), database.ScheduleIdentityDocumentDeletionParams{
+				ApplicationID: uuid.NullUUID{UUID: parsedID, Valid: true},
+				DeleteAfter:   sql.NullTime{Time: time.Now().UTC().Add(identityDocumentRetention), Valid: true},
+			}); err != nil {
+				http.Error(w, "Couldn't schedule identity document deletion", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if err := recordModerationAction(r.Context(), q, moderator.ModeratorID, moderationAction{
+			Action:        ActionApplicationStatusChanged,
+			TargetType:    "application",
+			TargetID:      parsedID,
+			SubjectUserID: uuid.NullUUID{UUID: contri_data.UserID, Valid: true},
+			Before:        map[string]interface{}{"status": currentStatus},
+			After:         map[string]interface{}{"status": params.Status, "approved_fields": approved},
+			Reason:        params.ReviewerNotes,
+		}); err != nil {
+			http.Error(w, "Couldn't record the decision", http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "Couldn't update application", http.StatusInternalServerError)
+			return
+		}
+		if reinstatedContributor != nil {
+			notifyContributorStatusChange(r.Context(), db, reinstatedFrom, *reinstatedContributor, "Contributor application approved")
+		}
+
+		if params.Status != ApplicationUnderReview {
+			releaseDecidedItem(r.Context(), db, QueueApplication, parsedID)
+		}
+
+		message := map[string]string{
+			ApplicationUnderReview:   "Your contributor application is being reviewed",
+			ApplicationApproved:      "Your contributor application was approved",
+			ApplicationRejected:      "Your contributor application was rejected",
+			ApplicationNeedsMoreInfo: "Your contributor application needs more information",
+		}[params.Status]
+		notifyUser(r.Context(), db, contri_data.UserID, "contributor_application_"+params.Status, message, map[string]interface{}{
+			"contri_app_id":  parsedID,
+			"reviewer_notes": params.ReviewerNotes,
+		})
+
 		w.WriteHeader(http.StatusOK) // 200
 		json.NewEncoder(w).Encode(application)
 
 	})
 }
 
+func toReturnedContributorApplication(app database.ListContributorApplicationsByUserRow) ReturnedContributorApplication {
+	return ReturnedContributorApplication{
+		ContriAppID:       app.ContriAppID,
+		Status:            applicationStatus(app.Status),
+		RequestedFields:   app.RequestedFields,
+		ExpertiseProofs:   app.ExpertiseProofs,
+		IdentityProof:     app.IdentityProof,
+		InitialSubmission: app.InitialSubmission,
+		ReviewerNotes:     app.ReviewerNotes,
+		CreatedAt:         nullTimePtr(app.CreatedAt),
+		ReviewedAt:        nullTimePtr(app.ReviewedAt),
+		UpdatedAt:         app.UpdatedAt,
+	}
+}
+
+// GetMyContributorApplications shows applicants their applications, newest
+// first, and whether (or from when) they can apply again.
+func GetMyContributorApplications(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		applications, err := db.ListContributorApplicationsByUser(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get your applications", http.StatusInternalServerError)
+			return
+		}
+
+		returned := make([]ReturnedContributorApplication, len(applications))
+		for i, app := range applications {
+			returned[i] = toReturnedContributorApplication(app)
+		}
+
+		open, canApplyAt := applicationEligibility(applications)
+		var canApplyAtPtr *time.Time
+		if open == nil && time.Now().Before(canApplyAt) {
+			canApplyAtPtr = &canApplyAt
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"applications": returned,
+			"can_apply":    open == nil && canApplyAtPtr == nil,
+			"can_apply_at": canApplyAtPtr,
+		})
+	})
+}
+
+// ResubmitMyContributorApplication lets an applicant answer a request for
+// more information; the application goes back to pending.
+func ResubmitMyContributorApplication(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, ok := decodeApplication(w, r, db)
+		if !ok {
+			return
+		}
+
+		applications, err := db.ListContributorApplicationsByUser(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get your applications", http.StatusInternalServerError)
+			return
+		}
+		open, _ := applicationEligibility(applications)
+		if open == nil {
+			http.Error(w, "You have no open application", http.StatusNotFound)
+			return
+		}
+		if applicationStatus(open.Status) != ApplicationNeedsMoreInfo {
+			http.Error(w, "Your application can only be changed when more information was asked for", http.StatusConflict)
+			return
+		}
+
+		documentIDs, ok := checkApplicationDocuments(w, r, db, user.UserID, uuid.NullUUID{UUID: open.ContriAppID, Valid: true}, params)
+		if !ok {
+			return
+		}
+
+		var application database.ResubmitContributorApplicationRow
+		err = db.RunInTx(r.Context(), func(q *database.Queries) error {
+			var err error
+			application, err = q.ResubmitContributorApplication(r.Context(), database.ResubmitContributorApplicationParams{
+				ContriAppID:       open.ContriAppID,
+				ExpertiseProofs:   params.ExpertiseProofs,
+				IdentityProof:     params.IdentityProof,
+				InitialSubmission: params.InitialSubmission,
+				RequestedFields:   params.ExpertiseFields,
+			})
+			if err != nil {
+				return err
+			}
+			return attachApplicationDocuments(r.Context(), q, user.UserID, application.ContriAppID, documentIDs)
+		})
+		if err == sql.ErrNoRows {
+			http.Error(w, "Your application was changed by a moderator; reload and try again", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't update your application", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(toReturnedContributorApplication(database.ListContributorApplicationsByUserRow(application)))
+	})
+}
+
 func GetContributorApplications(db *database.Queries, moderator database.Moderator) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		applications, err := db.ListContributorApplications(r.Context())
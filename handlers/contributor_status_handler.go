@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Contributor statuses. Only active contributors can publish; expired is set
+// by the expiry job, never by a moderator directly.
+const (
+	ContributorActive    = "active"
+	ContributorSuspended = "suspended"
+	ContributorRevoked   = "revoked"
+	ContributorExpired   = "expired"
+)
+
+type ReturnedContributorStatus struct {
+	UserID         uuid.UUID                        `json:"user_id"`
+	Status         string                           `json:"status"`
+	StatusReason   string                           `json:"status_reason"`
+	SuspendedUntil *time.Time                       `json:"suspended_until"`
+	ExpiresAt      *time.Time                       `json:"expires_at"`
+	History        []ReturnedContributorStatusEntry `json:"history,omitempty"`
+}
+
+type ReturnedContributorStatusEntry struct {
+	HistoryID      uuid.UUID  `json:"history_id"`
+	FromStatus     string     `json:"from_status"`
+	ToStatus       string     `json:"to_status"`
+	Reason         string     `json:"reason"`
+	ChangedBy      *uuid.UUID `json:"changed_by"`
+	ChangedByName  string     `json:"changed_by_name"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// recordContributorStatusChange logs a status or expiry change and adds it
+// to the moderation audit log when a moderator made it. Run it in the
+// change's transaction.
+func recordContributorStatusChange(ctx context.Context, db *database.Queries, from string, contributor database.Contributor, reason string, changedBy uuid.NullUUID) error {
+	if err := db.CreateContributorStatusHistory(ctx, database.CreateContributorStatusHistoryParams{
+		HistoryID:      uuid.New(),
+		UserID:         contributor.UserID,
+		FromStatus:     from,
+		ToStatus:       contributor.Status,
+		Reason:         reason,
+		ChangedBy:      changedBy,
+		SuspendedUntil: contributor.SuspendedUntil,
+		ExpiresAt:      contributor.ExpiresAt,
+		CreatedAt:      time.Now().UTC(),
+	}); err != nil {
+		return err
+	}
+
+	if !changedBy.Valid {
+		return nil
+	}
+	return recordModerationAction(ctx, db, changedBy.UUID, moderationAction{
+		Action:        ActionContributorStatusChanged,
+		TargetType:    "contributor",
+		TargetID:      contributor.UserID,
+		SubjectUserID: uuid.NullUUID{UUID: contributor.UserID, Valid: true},
+		Before:        map[string]interface{}{"status": from},
+		After: map[string]interface{}{
+			"status":          contributor.Status,
+			"suspended_until": nullTimePtr(contributor.SuspendedUntil),
+			"expires_at":      nullTimePtr(contributor.ExpiresAt),
+		},
+		Reason: reason,
+	})
+}
+
+// notifyContributorStatusChange tells the contributor about a status or
+// expiry change once it's committed.
+func notifyContributorStatusChange(ctx context.Context, db *database.Queries, from string, contributor database.Contributor, reason string) {
+	message := map[string]string{
+		ContributorActive:    "Your contributor status is active",
+		ContributorSuspended: "Your contributor status was suspended",
+		ContributorRevoked:   "Your contributor status was revoked",
+	}[contributor.Status]
+	if from == contributor.Status {
+		message = "Your contributor status expiry was updated"
+	}
+	if err := notifyUser(ctx, db, contributor.UserID, "contributor_status_"+contributor.Status, message, map[string]interface{}{
+		"reason":          reason,
+		"suspended_until": nullTimePtr(contributor.SuspendedUntil),
+		"expires_at":      nullTimePtr(contributor.ExpiresAt),
+	}); err != nil {
+		fmt.Printf("Failed to notify %s of contributor status change: %v\n", contributor.UserID, err)
+	}
+}
+
+// contributorFromURL loads the contributor named by the {id} user ID,
+// writing the error response itself.
+func contributorFromURL(w http.ResponseWriter, r *http.Request, db *database.Queries) (database.Contributor, bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest) // 400
+		return database.Contributor{}, false
+	}
+
+	contributor, err := db.GetContributorByUserId(r.Context(), userID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Contributor not found", http.StatusNotFound) // 404
+		return database.Contributor{}, false
+	}
+	if err != nil {
+		http.Error(w, "Couldn't get contributor", http.StatusInternalServerError) // 500
+		return database.Contributor{}, false
+	}
+	return contributor, true
+}
+
+// GetContributorStatusHandler shows a contributor's current status and the
+// history of changes to it.
+func GetContributorStatusHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contributor, ok := contributorFromURL(w, r, db)
+		if !ok {
+			return
+		}
+
+		history, err := db.ListContributorStatusHistory(r.Context(), contributor.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get status history", http.StatusInternalServerError) // 500
+			return
+		}
+
+		entries := make([]ReturnedContributorStatusEntry, len(history))
+		for i, h := range history {
+			entries[i] = ReturnedContributorStatusEntry{
+				HistoryID:      h.HistoryID,
+				FromStatus:     h.FromStatus,
+				ToStatus:       h.ToStatus,
+				Reason:         h.Reason,
+				ChangedBy:      nullUUIDPtr(h.ChangedBy),
+				ChangedByName:  h.ChangedByName.String,
+				SuspendedUntil: nullTimePtr(h.SuspendedUntil),
+				ExpiresAt:      nullTimePtr(h.ExpiresAt),
+				CreatedAt:      h.CreatedAt,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ReturnedContributorStatus{
+			UserID:         contributor.UserID,
+			Status:         contributor.Status,
+			StatusReason:   contributor.StatusReason,
+			SuspendedUntil: nullTimePtr(contributor.SuspendedUntil),
+			ExpiresAt:      nullTimePtr(contributor.ExpiresAt),
+			History:        entries,
+		})
+	})
+}
+
+// UpdateContributorStatusHandler suspends, revokes or reinstates a
+// contributor. A suspension without suspended_until lasts until a moderator
+// reinstates the contributor.
+func UpdateContributorStatusHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type parameters struct {
+			Status         string     `json:"status"`
+			Reason         string     `json:"reason"`
+			SuspendedUntil *time.Time `json:"suspended_until"`
+		}
+
+		var params parameters
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest) // 400
+			return
+		}
+
+		if params.Status != ContributorActive && params.Status != ContributorSuspended && params.Status != ContributorRevoked {
+			http.Error(w, "status must be active, suspended or revoked", http.StatusBadRequest) // 400
+			return
+		}
+		params.Reason = strings.TrimSpace(params.Reason)
+		if params.Reason == "" {
+			http.Error(w, "reason is required", http.StatusBadRequest) // 400
+			return
+		}
+
+		suspendedUntil := sql.NullTime{}
+		if params.SuspendedUntil != nil {
+			if params.Status != ContributorSuspended {
+				http.Error(w, "suspended_until only applies to suspensions", http.StatusBadRequest) // 400
+				return
+			}
+			if !params.SuspendedUntil.After(time.Now()) {
+				http.Error(w, "suspended_until must be in the future", http.StatusBadRequest) // 400
+				return
+			}
+			suspendedUntil = sql.NullTime{Time: params.SuspendedUntil.UTC(), Valid: true}
+		}
+
+		contributor, ok := contributorFromURL(w, r, db)
+		if !ok {
+			return
+		}
+		if contributor.Status == params.Status && params.Status != ContributorSuspended {
+			http.Error(w, "Contributor is already "+params.Status, http.StatusConflict) // 409
+			return
+		}
+		if params.Status == ContributorActive && contributor.ExpiresAt.Valid && !contributor.ExpiresAt.Time.After(time.Now()) {
+			http.Error(w, "Contributor status has expired; extend expires_at to reinstate it", http.StatusConflict) // 409
+			return
+		}
+
+		tx, q, err := db.Begin(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't update contributor status", http.StatusInternalServerError) // 500
+			return
+		}
+		defer tx.Rollback()
+
+		updated, err := q.SetContributorStatus(r.Context(), database.SetContributorStatusParams{
+			UserID:         contributor.UserID,
+			Status:         params.Status,
+			StatusReason:   params.Reason,
+			SuspendedUntil: suspendedUntil,
+			FromStatus:     contributor.Status,
+		})
+		if err == sql.ErrNoRows {
+			http.Error(w, "Contributor was changed by someone else; reload and try again", http.StatusConflict) // 409
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't update contributor status", http.StatusInternalServerError) // 500
+			return
+		}
+
+		if err := recordContributorStatusChange(r.Context(), q, contributor.Status, updated, params.Reason,
+			uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true}); err != nil {
+			http.Error(w, "Couldn't record status change", http.StatusInternalServerError) // 500
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "Couldn't update contributor status", http.StatusInternalServerError) // 500
+			return
+		}
+		notifyContributorStatusChange(r.Context(), db, contributor.Status, updated, params.Reason)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ReturnedContributorStatus{
+			UserID:         updated.UserID,
+			Status:         updated.Status,
+			StatusReason:   updated.StatusReason,
+			SuspendedUntil: nullTimePtr(updated.SuspendedUntil),
+			ExpiresAt:      nullTimePtr(updated.ExpiresAt),
+		})
+	})
+}
+
+// UpdateContributorExpiryHandler sets or clears when a contributor's status
+// lapses. Extending the expiry of an expired contributor renews them.
+func UpdateContributorExpiryHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type parameters struct {
+			ExpiresAt *time.Time `json:"expires_at"`
+			Reason    string     `json:"reason"`
+		}
+
+		var params parameters
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest) // 400
+			return
+		}
+
+		expiresAt := sql.NullTime{}
+		if params.ExpiresAt != nil {
+			if !params.ExpiresAt.After(time.Now()) {
+				http.Error(w, "expires_at must be in the future", http.StatusBadRequest) // 400
+				return
+			}
+			expiresAt = sql.NullTime{Time: params.ExpiresAt.UTC(), Valid: true}
+		}
+		params.Reason = strings.TrimSpace(params.Reason)
+		if params.Reason == "" {
+			params.Reason = "Expiry updated"
+		}
+
+		contributor, ok := contributorFromURL(w, r, db)
+		if !ok {
+			return
+		}
+
+		tx, q, err := db.Begin(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't update contributor expiry", http.StatusInternalServerError) // 500
+			return
+		}
+		defer tx.Rollback()
+
+		updated, err := q.SetContributorExpiry(r.Context(), database.SetContributorExpiryParams{
+			UserID:    contributor.UserID,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't update contributor expiry", http.StatusInternalServerError) // 500
+			return
+		}
+
+		if updated.Status == ContributorExpired {
+			updated, err = q.SetContributorStatus(r.Context(), database.SetContributorStatusParams{
+				UserID:       contributor.UserID,
+				Status:       ContributorActive,
+				StatusReason: params.Reason,
+				FromStatus:   ContributorExpired,
+			})
+			if err != nil {
+				http.Error(w, "Couldn't renew contributor status", http.StatusInternalServerError) // 500
+				return
+			}
+		}
+
+		if err := recordContributorStatusChange(r.Context(), q, contributor.Status, updated, params.Reason,
+			uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true}); err != nil {
+			http.Error(w, "Couldn't record status change", http.StatusInternalServerError) // 500
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "Couldn't update contributor expiry", http.StatusInternalServerError) // 500
+			return
+		}
+		notifyContributorStatusChange(r.Context(), db, contributor.Status, updated, params.Reason)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ReturnedContributorStatus{
+			UserID:         updated.UserID,
+			Status:         updated.Status,
+			StatusReason:   updated.StatusReason,
+			SuspendedUntil: nullTimePtr(updated.SuspendedUntil),
+			ExpiresAt:      nullTimePtr(updated.ExpiresAt),
+		})
+	})
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/MyoMyatMin/expertly-backend/jobs"
+	"github.com/go-chi/chi/v5"
+)
+
+// CronHandler runs a background job on demand. Vercel has no long-running
+// process, so its cron scheduler calls this with CRON_SECRET as a bearer token.
+func CronHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		secret := os.Getenv("CRON_SECRET")
+		if secret == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+secret)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		name := chi.URLParam(r, "job")
+		if err := jobs.RunByName(r.Context(), db, name); err != nil {
+			if errors.Is(err, jobs.ErrUnknownJob) {
+				http.Error(w, "Unknown job", http.StatusNotFound)
+				return
+			}
+			log.Printf("job %s failed: %v", name, err)
+			http.Error(w, "Job failed", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,515 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Penalties the enforcement policy can hand out. A resolved case gives the
+// reported user a strike, and the number of strikes they hold picks the
+// penalty.
+const (
+	PenaltyNone       = "none"
+	PenaltyWarning    = "warning"
+	PenaltySuspension = "suspension"
+	PenaltyBan        = "ban"
+)
+
+const (
+	maxPolicySuspendDays  = 365
+	maxStrikeExpiryDays   = 3650
+	maxEnforcementLevels  = 20
+	strikeNotificationFmt = "You received a strike for breaking the community guidelines. You now have %d active strike(s)."
+)
+
+// permanentBanUntil is the suspended_until a ban gives. It never passes, and
+// it's the latest time that still encodes as JSON.
+var permanentBanUntil = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// enforcementLevel is one step of the policy: holding Strikes active strikes
+// or more earns Penalty.
+type enforcementLevel struct {
+	Strikes     int32  `json:"strikes"`
+	Penalty     string `json:"penalty"`
+	SuspendDays int32  `json:"suspend_days,omitempty"`
+}
+
+type ReturnedEnforcementPolicy struct {
+	StrikeExpiryDays int32              `json:"strike_expiry_days"`
+	Levels           []enforcementLevel `json:"levels"`
+	UpdatedBy        *uuid.UUID         `json:"updated_by"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+func toReturnedEnforcementPolicy(policy database.EnforcementPolicy) (ReturnedEnforcementPolicy, error) {
+	var levels []enforcementLevel
+	if err := json.Unmarshal(policy.Levels, &levels); err != nil {
+		return ReturnedEnforcementPolicy{}, err
+	}
+	return ReturnedEnforcementPolicy{
+		StrikeExpiryDays: policy.StrikeExpiryDays,
+		Levels:           levels,
+		UpdatedBy:        nullUUIDPtr(policy.UpdatedBy),
+		UpdatedAt:        policy.UpdatedAt,
+	}, nil
+}
+
+// penaltyFor picks the highest level the strike count reaches.
+func penaltyFor(levels []enforcementLevel, strikes int64) enforcementLevel {
+	penalty := enforcementLevel{Penalty: PenaltyNone}
+	for _, level := range levels {
+		if int64(level.Strikes) <= strikes && level.Strikes >= penalty.Strikes {
+			penalty = level
+		}
+	}
+	return penalty
+}
+
+// validateEnforcementLevels sorts the levels by strike count and checks them,
+// writing the error response itself.
+func validateEnforcementLevels(w http.ResponseWriter, levels []enforcementLevel) bool {
+	if len(levels) > maxEnforcementLevels {
+		http.Error(w, "A policy can have at most 20 levels", http.StatusBadRequest) // 400
+		return false
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Strikes < levels[j].Strikes })
+
+	for i, level := range levels {
+		if level.Strikes < 1 {
+			http.Error(w, "strikes must be at least 1", http.StatusBadRequest) // 400
+			return false
+		}
+		if i > 0 && levels[i-1].Strikes == level.Strikes {
+			http.Error(w, fmt.Sprintf("Strike count %d appears more than once", level.Strikes), http.StatusBadRequest) // 400
+			return false
+		}
+		switch level.Penalty {
+		case PenaltyWarning, PenaltyBan:
+			if level.SuspendDays != 0 {
+				http.Error(w, "suspend_days only applies to suspensions", http.StatusBadRequest) // 400
+				return false
+			}
+		case PenaltySuspension:
+			if level.SuspendDays < 1 || level.SuspendDays > maxPolicySuspendDays {
+				http.Error(w, "suspend_days must be between 1 and 365", http.StatusBadRequest) // 400
+				return false
+			}
+		default:
+			http.Error(w, "penalty must be warning, suspension or ban", http.StatusBadRequest) // 400
+			return false
+		}
+	}
+	return true
+}
+
+// ReturnedEnforcementPreview is what resolving a case would do to the
+// reported user. Moderators confirm it by sending StrikesAfter back as
+// expected_strikes.
+type ReturnedEnforcementPreview struct {
+	CaseID        uuid.UUID `json:"case_id"`
+	UserID        uuid.UUID `json:"user_id"`
+	ActiveStrikes int64     `json:"active_strikes"`
+	StrikesAfter  int64     `json:"strikes_after"`
+	// AlreadyStruck is set when the case has given its strike already, e.g.
+	// through one of its reports being resolved on its own.
+	AlreadyStruck   bool      `json:"already_struck"`
+	Penalty         string    `json:"penalty"`
+	SuspendDays     int32     `json:"suspend_days"`
+	StrikeExpiresAt time.Time `json:"strike_expires_at"`
+	// Manual is set when the moderator chose the suspension instead of the
+	// policy.
+	Manual bool `json:"manual"`
+}
+
+func previewCaseEnforcement(ctx context.Context, db *database.Queries, reportCase database.ReportCase) (ReturnedEnforcementPreview, error) {
+	policy, err := db.GetEnforcementPolicy(ctx)
+	if err != nil {
+		return ReturnedEnforcementPreview{}, err
+	}
+	returnedPolicy, err := toReturnedEnforcementPolicy(policy)
+	if err != nil {
+		return ReturnedEnforcementPreview{}, err
+	}
+
+	now := time.Now().UTC()
+	active, err := db.CountActiveStrikes(ctx, database.CountActiveStrikesParams{
+		UserID:    reportCase.TargetUserID,
+		ExpiresAt: now,
+	})
+	if err != nil {
+		return ReturnedEnforcementPreview{}, err
+	}
+
+	preview := ReturnedEnforcementPreview{
+		CaseID:          reportCase.CaseID,
+		UserID:          reportCase.TargetUserID,
+		ActiveStrikes:   active,
+		StrikesAfter:    active,
+		Penalty:         PenaltyNone,
+		StrikeExpiresAt: now.AddDate(0, 0, int(policy.StrikeExpiryDays)),
+	}
+
+	_, err = db.GetStrikeByCase(ctx, reportCase.CaseID)
+	if err == nil {
+		preview.AlreadyStruck = true
+		return preview, nil
+	}
+	if err != sql.ErrNoRows {
+		return ReturnedEnforcementPreview{}, err
+	}
+
+	preview.StrikesAfter = active + 1
+	level := penaltyFor(returnedPolicy.Levels, preview.StrikesAfter)
+	preview.Penalty = level.Penalty
+	preview.SuspendDays = level.SuspendDays
+	return preview, nil
+}
+
+// planCaseEnforcement works out what resolving a case does to the reported
+// user, writing the error response itself. A manual suspendedDays replaces
+// the policy's penalty; expectedStrikes is required and has to match the
+// preview the moderator confirmed.
+func planCaseEnforcement(w http.ResponseWriter, r *http.Request, db *database.Queries, reportCase database.ReportCase, suspendedDays int, expectedStrikes *int64) (ReturnedEnforcementPreview, bool) {
+	if expectedStrikes == nil {
+		http.Error(w, "expected_strikes is required to resolve; preview the penalty first", http.StatusBadRequest) // 400
+		return ReturnedEnforcementPreview{}, false
+	}
+	plan, err := previewCaseEnforcement(r.Context(), db, reportCase)
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, "Couldn't work out the penalty", http.StatusInternalServerError) // 500
+		return ReturnedEnforcementPreview{}, false
+	}
+	if *expectedStrikes != plan.StrikesAfter {
+		http.Error(w, "The user's strikes changed since the preview; preview the penalty again", http.StatusConflict) // 409
+		return ReturnedEnforcementPreview{}, false
+	}
+	if suspendedDays > 0 {
+		plan.Penalty = PenaltySuspension
+		plan.SuspendDays = int32(suspendedDays)
+		plan.Manual = true
+	}
+	return plan, true
+}
+
+// enforceCase gives the reported user the case's strike and applies the
+// planned penalty, returning the notifications to send once it's committed.
+// reportID is set when a single report of the case was resolved.
+func enforceCase(ctx context.Context, db *database.Queries, moderator database.Moderator, reportCase database.ReportCase, reportID uuid.NullUUID, plan ReturnedEnforcementPreview, reason string) (ReturnedEnforcementPreview, []notification, error) {
+	var notices []notification
+	err := db.RunInTx(ctx, func(q *database.Queries) error {
+		if !plan.AlreadyStruck {
+			strike, err := q.CreateStrike(ctx, database.CreateStrikeParams{
+				StrikeID:    uuid.New(),
+				UserID:      reportCase.TargetUserID,
+				CaseID:      reportCase.CaseID,
+				IssuedBy:    moderator.ModeratorID,
+				Penalty:     plan.Penalty,
+				SuspendDays: plan.SuspendDays,
+				CreatedAt:   time.Now().UTC(),
+				ExpiresAt:   plan.StrikeExpiresAt,
+			})
+			if err == sql.ErrNoRows {
+				// Another moderator struck for this case in the meantime.
+				plan.AlreadyStruck = true
+				plan.StrikesAfter = plan.ActiveStrikes
+			} else if err != nil {
+				return err
+			} else {
+				if err := recordModerationAction(ctx, q, moderator.ModeratorID, moderationAction{
+					Action:        ActionStrikeIssued,
+					TargetType:    "user",
+					TargetID:      reportCase.TargetUserID,
+					SubjectUserID: uuid.NullUUID{UUID: reportCase.TargetUserID, Valid: true},
+					Before:        map[string]interface{}{"active_strikes": plan.ActiveStrikes},
+					After: map[string]interface{}{
+						"active_strikes": plan.StrikesAfter,
+						"strike_id":      strike.StrikeID,
+						"case_id":        reportCase.CaseID,
+						"penalty":        plan.Penalty,
+						"suspend_days":   plan.SuspendDays,
+						"expires_at":     strike.ExpiresAt,
+					},
+					Reason: reason,
+				}); err != nil {
+					return err
+				}
+				notices = append(notices, notification{
+					UserID:  reportCase.TargetUserID,
+					Type:    "strike_issued",
+					Message: fmt.Sprintf(strikeNotificationFmt, plan.StrikesAfter),
+					Data: map[string]interface{}{
+						"case_id":        reportCase.CaseID,
+						"active_strikes": plan.StrikesAfter,
+						"penalty":        plan.Penalty,
+						"suspend_days":   plan.SuspendDays,
+						"expires_at":     strike.ExpiresAt,
+					},
+				})
+			}
+		}
+		if plan.AlreadyStruck && !plan.Manual {
+			plan.Penalty = PenaltyNone
+			plan.SuspendDays = 0
+		}
+
+		caseID := uuid.NullUUID{UUID: reportCase.CaseID, Valid: true}
+		switch plan.Penalty {
+		case PenaltySuspension:
+			return suspendReportedUser(ctx, q, moderator.ModeratorID, reportCase.TargetUserID, caseID, reportID, int(plan.SuspendDays), reason)
+		case PenaltyBan:
+			return banUser(ctx, q, moderator.ModeratorID, reportCase.TargetUserID, caseID, reportID, reason)
+		}
+		return nil
+	})
+	return plan, notices, err
+}
+
+// banUser suspends the user for good. An upheld appeal lifts it like any
+// other suspension.
+func banUser(ctx context.Context, db *database.Queries, moderatorID, userID uuid.UUID, caseID, reportID uuid.NullUUID, reason string) error {
+	return db.RunInTx(ctx, func(q *database.Queries) error {
+		suspension, before, after, err := suspendUser(ctx, q, moderatorID, userID, caseID, reportID, 0, reason)
+		if err != nil {
+			return err
+		}
+
+		return recordModerationAction(ctx, q, moderatorID, moderationAction{
+			Action:        ActionUserBanned,
+			TargetType:    "user",
+			TargetID:      userID,
+			SubjectUserID: uuid.NullUUID{UUID: userID, Valid: true},
+			Before:        map[string]interface{}{"suspended_until": nullTimePtr(before)},
+			After:         map[string]interface{}{"suspended_until": nullTimePtr(after), "suspension_id": suspension.SuspensionID},
+			Reason:        reason,
+		})
+	})
+}
+
+// revokeCaseStrike takes back the strike a case gave, once an appeal against
+// it is upheld.
+func revokeCaseStrike(ctx context.Context, db *database.Queries, moderator database.Moderator, caseID uuid.UUID, reason string) error {
+	return db.RunInTx(ctx, func(q *database.Queries) error {
+		strike, err := q.RevokeCaseStrike(ctx, database.RevokeCaseStrikeParams{
+			CaseID:    caseID,
+			RevokedBy: uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+			RevokedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		})
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordModerationAction(ctx, q, moderator.ModeratorID, moderationAction{
+			Action:        ActionStrikeRevoked,
+			TargetType:    "user",
+			TargetID:      strike.UserID,
+			SubjectUserID: uuid.NullUUID{UUID: strike.UserID, Valid: true},
+			Before:        map[string]interface{}{"strike_id": strike.StrikeID, "case_id": caseID},
+			After:         map[string]interface{}{"revoked_at": strike.RevokedAt.Time},
+			Reason:        reason,
+		})
+	})
+}
+
+// GetEnforcementPolicyHandler shows the strike expiry and the penalty for
+// each strike count.
+func GetEnforcementPolicyHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy, err := db.GetEnforcementPolicy(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't get enforcement policy", http.StatusInternalServerError) // 500
+			return
+		}
+		returned, err := toReturnedEnforcementPolicy(policy)
+		if err != nil {
+			http.Error(w, "Couldn't read enforcement policy", http.StatusInternalServerError) // 500
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
+
+// UpdateEnforcementPolicyHandler replaces the policy. Admins only. Strikes
+// already given keep the expiry they were given with.
+func UpdateEnforcementPolicyHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if moderator.Role != "admin" {
+			http.Error(w, "Only admins can change the enforcement policy", http.StatusForbidden) // 403
+			return
+		}
+
+		type parameters struct {
+			StrikeExpiryDays int32              `json:"strike_expiry_days"`
+			Levels           []enforcementLevel `json:"levels"`
+			// Reason is kept in the moderation audit log.
+			Reason string `json:"reason"`
+		}
+		var params parameters
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest) // 400
+			return
+		}
+		if params.StrikeExpiryDays < 1 || params.StrikeExpiryDays > maxStrikeExpiryDays {
+			http.Error(w, "strike_expiry_days must be between 1 and 3650", http.StatusBadRequest) // 400
+			return
+		}
+		if params.Levels == nil {
+			params.Levels = []enforcementLevel{}
+		}
+		if !validateEnforcementLevels(w, params.Levels) {
+			return
+		}
+
+		existing, err := db.GetEnforcementPolicy(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't get enforcement policy", http.StatusInternalServerError) // 500
+			return
+		}
+		before, err := toReturnedEnforcementPolicy(existing)
+		if err != nil {
+			http.Error(w, "Couldn't read enforcement policy", http.StatusInternalServerError) // 500
+			return
+		}
+
+		levels, err := json.Marshal(params.Levels)
+		if err != nil {
+			http.Error(w, "Couldn't encode enforcement policy", http.StatusInternalServerError) // 500
+			return
+		}
+		tx, q, err := db.Begin(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't update enforcement policy", http.StatusInternalServerError) // 500
+			return
+		}
+		defer tx.Rollback()
+
+		policy, err := q.UpdateEnforcementPolicy(r.Context(), database.UpdateEnforcementPolicyParams{
+			StrikeExpiryDays: params.StrikeExpiryDays,
+			Levels:           levels,
+			UpdatedBy:        uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+			UpdatedAt:        time.Now().UTC(),
+		})
+		if err != nil {
+			http.Error(w, "Couldn't update enforcement policy", http.StatusInternalServerError) // 500
+			return
+		}
+		returned, err := toReturnedEnforcementPolicy(policy)
+		if err != nil {
+			http.Error(w, "Couldn't read enforcement policy", http.StatusInternalServerError) // 500
+			return
+		}
+
+		if err := recordModerationAction(r.Context(), q, moderator.ModeratorID, moderationAction{
+			Action:     ActionEnforcementPolicyUpdated,
+			TargetType: "enforcement_policy",
+			TargetID:   uuid.Nil,
+			Before:     map[string]interface{}{"strike_expiry_days": before.StrikeExpiryDays, "levels": before.Levels},
+			After:      map[string]interface{}{"strike_expiry_days": returned.StrikeExpiryDays, "levels": returned.Levels},
+			Reason:     params.Reason,
+		}); err != nil {
+			http.Error(w, "Couldn't record policy change", http.StatusInternalServerError) // 500
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "Couldn't update enforcement policy", http.StatusInternalServerError) // 500
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
+
+// GetCaseEnforcementPreviewHandler shows the strike and penalty resolving a
+// case would give, before the moderator confirms it.
+func GetCaseEnforcementPreviewHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reportCase, ok := reportCaseFromURL(w, r, db)
+		if !ok {
+			return
+		}
+
+		preview, err := previewCaseEnforcement(r.Context(), db, reportCase)
+		if err != nil {
+			fmt.Println(err)
+			http.Error(w, "Couldn't work out the penalty", http.StatusInternalServerError) // 500
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(preview)
+	})
+}
+
+type ReturnedStrike struct {
+	StrikeID    uuid.UUID  `json:"strike_id"`
+	CaseID      uuid.UUID  `json:"case_id"`
+	IssuedBy    uuid.UUID  `json:"issued_by"`
+	Penalty     string     `json:"penalty"`
+	SuspendDays int32      `json:"suspend_days"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedBy   *uuid.UUID `json:"revoked_by"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	Active      bool       `json:"active"`
+}
+
+// GetUserStrikesHandler lists every strike a user has had, newest first,
+// with how many still count.
+func GetUserStrikesHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest) // 400
+			return
+		}
+
+		strikes, err := db.ListStrikesByUser(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Couldn't get strikes", http.StatusInternalServerError) // 500
+			return
+		}
+
+		now := time.Now().UTC()
+		var active int64
+		returned := make([]ReturnedStrike, len(strikes))
+		for i, strike := range strikes {
+			returned[i] = ReturnedStrike{
+				StrikeID:    strike.StrikeID,
+				CaseID:      strike.CaseID,
+				IssuedBy:    strike.IssuedBy,
+				Penalty:     strike.Penalty,
+				SuspendDays: strike.SuspendDays,
+				CreatedAt:   strike.CreatedAt,
+				ExpiresAt:   strike.ExpiresAt,
+				RevokedBy:   nullUUIDPtr(strike.RevokedBy),
+				RevokedAt:   nullTimePtr(strike.RevokedAt),
+				Active:      !strike.RevokedAt.Valid && strike.ExpiresAt.After(now),
+			}
+			if returned[i].Active {
+				active++
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"user_id":        userID,
+			"active_strikes": active,
+			"strikes":        returned,
+		})
+	})
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/google/uuid"
+)
+
+// How thoroughly a moderator checked a contributor's expertise in a field.
+// Legacy fields were carried over from before verification and can't be
+// given out by moderators.
+const (
+	VerificationLegacy   = "legacy"
+	VerificationBasic    = "basic"
+	VerificationVerified = "verified"
+	VerificationExpert   = "expert"
+)
+
+const maxRequestedFields = 5
+
+type ReturnedExpertise struct {
+	Field             string     `json:"field"`
+	Name              string     `json:"name"`
+	Category          string     `json:"category"`
+	VerificationLevel string     `json:"verification_level"`
+	VerifiedAt        time.Time  `json:"verified_at"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+}
+
+func isValidVerificationLevel(level string) bool {
+	return level == VerificationBasic || level == VerificationVerified || level == VerificationExpert
+}
+
+// normalizeExpertiseFields checks requested field slugs against the curated
+// taxonomy, dropping duplicates.
+func normalizeExpertiseFields(ctx context.Context, db *database.Queries, fields []string) ([]string, error) {
+	taxonomy, err := db.ListExpertiseFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	for _, f := range taxonomy {
+		known[f.Slug] = true
+	}
+
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" || seen[field] {
+			continue
+		}
+		if !known[field] {
+			return nil, fmt.Errorf("unknown expertise field %q", field)
+		}
+		seen[field] = true
+		normalized = append(normalized, field)
+	}
+	return normalized, nil
+}
+
+// activeExpertise returns the fields a contributor is currently verified in.
+func activeExpertise(ctx context.Context, db *database.Queries, userID uuid.UUID) ([]ReturnedExpertise, error) {
+	expertise, err := db.ListActiveContributorExpertise(ctx, database.ListActiveContributorExpertiseParams{
+		UserID: userID,
+		Now:    sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	returned := make([]ReturnedExpertise, len(expertise))
+	for i, e := range expertise {
+		returned[i] = ReturnedExpertise{
+			Field:             e.Field,
+			Name:              e.Name,
+			Category:          e.Category,
+			VerificationLevel: e.VerificationLevel,
+			VerifiedAt:        e.VerifiedAt,
+			ExpiresAt:         nullTimePtr(e.ExpiresAt),
+		}
+	}
+	return returned, nil
+}
+
+// GetExpertiseFieldsHandler lists the curated expertise taxonomy applicants
+// choose their fields from.
+func GetExpertiseFieldsHandler(db *database.Queries) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields, err := db.ListExpertiseFields(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't get expertise fields", http.StatusInternalServerError)
+			return
+		}
+
+		type returnedField struct {
+			Slug     string `json:"slug"`
+			Name     string `json:"name"`
+			Category string `json:"category"`
+		}
+		returned := make([]returnedField, len(fields))
+		for i, f := range fields {
+			returned[i] = returnedField{Slug: f.Slug, Name: f.Name, Category: f.Category}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
@@ -1,40 +1,376 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/utils"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
-func GetFollowingListByIDHandler(db *database.Queries) http.Handler {
+// GetFollowingListByIDHandler lists who a user follows, a page at a time.
+// Each entry says whether the viewer follows them and whether they follow the
+// viewer.
+func GetFollowingListByIDHandler(db *database.Queries, user database.User, moderator database.Moderator) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := parsePagination(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest) // 400
+			return
+		}
 
-		usernameParam := chi.URLParam(r, "username")
-		username := usernameParam
+		userID, err := db.GetIDbyUsername(r.Context(), chi.URLParam(r, "username"))
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound) // 404
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get user ID", http.StatusInternalServerError) // 500
+			return
+		}
 
-		userID, err := db.GetIDbyUsername(r.Context(), username)
+		settings, err := getPrivacySettings(r.Context(), db, userID)
 		if err != nil {
-			http.Error(w, "Couldn't get user ID", http.StatusInternalServerError)
+			http.Error(w, "Couldn't get privacy settings", http.StatusInternalServerError)
+			return
+		}
+		allowed, err := canView(r.Context(), db, settings.FollowsVisibility, userID, user, moderator)
+		if err != nil {
+			http.Error(w, "Couldn't check privacy settings", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, "This user's following list is private", http.StatusForbidden)
+			return
+		}
+
+		total, err := db.GetFollowingCount(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Couldn't get following count", http.StatusInternalServerError)
 			return
 		}
 
-		following, err := db.GetFollowingList(r.Context(), userID)
+		following, err := db.ListFollowing(r.Context(), database.ListFollowingParams{
+			ViewerID:   user.UserID,
+			UserID:     userID,
+			PageLimit:  limit,
+			PageOffset: offset,
+		})
 		if err != nil {
 			http.Error(w, "Couldn't get following list", http.StatusInternalServerError)
 			return
 		}
 
-		w.WriteHeader(http.StatusOK) // 200
-		json.NewEncoder(w).Encode(following)
+		returnedFollowing := make([]ReturnedFollowUser, len(following))
+		for i, followed := range following {
+			returnedFollowing[i] = ReturnedFollowUser{
+				UserID:      followed.FollowingID,
+				Name:        followed.Name,
+				Username:    followed.Username,
+				IsFollowing: followed.IsFollowing,
+				FollowsYou:  followed.FollowsYou,
+				IsMutual:    followed.IsFollowing && followed.FollowsYou,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"following": returnedFollowing,
+			"total":     total,
+			"limit":     limit,
+			"offset":    offset,
+		})
+	})
+}
+
+type ReturnedFollowUser struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	Username    string    `json:"username"`
+	IsFollowing bool      `json:"is_following"`
+	FollowsYou  bool      `json:"follows_you"`
+	IsMutual    bool      `json:"is_mutual"`
+}
+
+type ReturnedFollowStatus struct {
+	IsFollowing bool `json:"is_following"`
+	FollowsYou  bool `json:"follows_you"`
+	IsMutual    bool `json:"is_mutual"`
+}
+
+// GetFollowersHandler lists who follows a user, a page at a time. Each entry
+// says whether the viewer follows them back and whether they follow the viewer.
+func GetFollowersHandler(db *database.Queries, user database.User, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := parsePagination(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		userID, err := db.GetIDbyUsername(r.Context(), chi.URLParam(r, "username"))
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get user ID", http.StatusInternalServerError)
+			return
+		}
+
+		settings, err := getPrivacySettings(r.Context(), db, userID)
+		if err != nil {
+			http.Error(w, "Couldn't get privacy settings", http.StatusInternalServerError)
+			return
+		}
+		allowed, err := canView(r.Context(), db, settings.FollowsVisibility, userID, user, moderator)
+		if err != nil {
+			http.Error(w, "Couldn't check privacy settings", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, "This user's followers list is private", http.StatusForbidden)
+			return
+		}
+
+		total, err := db.GetFollwersCount(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Couldn't get follower count", http.StatusInternalServerError)
+			return
+		}
+
+		followers, err := db.ListFollowers(r.Context(), database.ListFollowersParams{
+			ViewerID:   user.UserID,
+			UserID:     userID,
+			PageLimit:  limit,
+			PageOffset: offset,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't get followers", http.StatusInternalServerError)
+			return
+		}
+
+		returnedFollowers := make([]ReturnedFollowUser, len(followers))
+		for i, follower := range followers {
+			returnedFollowers[i] = ReturnedFollowUser{
+				UserID:      follower.FollowerID,
+				Name:        follower.Name,
+				Username:    follower.Username,
+				IsFollowing: follower.IsFollowing,
+				FollowsYou:  follower.FollowsYou,
+				IsMutual:    follower.IsFollowing && follower.FollowsYou,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"followers": returnedFollowers,
+			"total":     total,
+			"limit":     limit,
+			"offset":    offset,
+		})
 	})
 }
 
+// GetFollowStatusesHandler answers "do I follow them / do they follow me" for
+// up to maxPageLimit users at once (?ids=uuid,uuid,...), so lists of people
+// can render follow buttons without one request per row.
+func GetFollowStatusesHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := strings.Split(r.URL.Query().Get("ids"), ",")
+		if len(raw) > maxPageLimit {
+			http.Error(w, "Too many user IDs", http.StatusBadRequest)
+			return
+		}
+
+		userIDs := make([]uuid.UUID, 0, len(raw))
+		for _, id := range raw {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+			userID, err := uuid.Parse(id)
+			if err != nil {
+				http.Error(w, "Invalid user ID", http.StatusBadRequest)
+				return
+			}
+			userIDs = append(userIDs, userID)
+		}
+
+		statuses, err := db.GetFollowStatuses(r.Context(), database.GetFollowStatusesParams{
+			ViewerID: user.UserID,
+			UserIds:  userIDs,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't get follow statuses", http.StatusInternalServerError)
+			return
+		}
+
+		returned := make(map[uuid.UUID]ReturnedFollowStatus, len(statuses))
+		for _, status := range statuses {
+			returned[status.UserID] = ReturnedFollowStatus{
+				IsFollowing: status.IsFollowing,
+				FollowsYou:  status.FollowsYou,
+				IsMutual:    status.IsFollowing && status.FollowsYou,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
+
+type ReturnedFollow struct {
+	FollowerID     uuid.UUID  `json:"follower_id"`
+	FollowingID    uuid.UUID  `json:"following_id"`
+	Username       string     `json:"username"`
+	Name           string     `json:"name"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	FollowersCount int64      `json:"followers_count"`
+	FollowingCount int64      `json:"following_count"`
+}
+
+// followTarget is the account being followed or unfollowed.
+type followTarget struct {
+	UserID   uuid.UUID
+	Username string
+	Name     string
+}
+
+// resolveFollowTarget looks the target up by username, or by ID when no
+// username is given, and writes the error response itself when it fails.
+func resolveFollowTarget(w http.ResponseWriter, r *http.Request, db *database.Queries, user database.User, username string, userID uuid.UUID) (followTarget, bool) {
+	var target followTarget
+	switch {
+	case username != "":
+		u, err := db.GetUserByUsername(r.Context(), username)
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound) // 404
+			return target, false
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get user", http.StatusInternalServerError) // 500
+			return target, false
+		}
+		target = followTarget{UserID: u.UserID, Username: u.Username, Name: u.Name}
+	case userID != uuid.Nil:
+		u, err := db.GetUserById(r.Context(), userID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound) // 404
+			return target, false
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get user", http.StatusInternalServerError) // 500
+			return target, false
+		}
+		target = followTarget{UserID: u.UserID, Username: u.Username, Name: u.Name}
+	default:
+		http.Error(w, "username or following_id is required", http.StatusUnprocessableEntity) // 422
+		return target, false
+	}
+
+	if target.UserID == utils.DeletedUserID {
+		http.Error(w, "User not found", http.StatusNotFound) // 404
+		return target, false
+	}
+	if target.UserID == user.UserID {
+		http.Error(w, "You can't follow yourself", http.StatusUnprocessableEntity) // 422
+		return target, false
+	}
+	return target, true
+}
+
+// writeFollowResponse reports the relationship together with the target's
+// follower count and the caller's following count, so the client can update
+// both without refetching the profile.
+func writeFollowResponse(w http.ResponseWriter, r *http.Request, db *database.Queries, user database.User, target followTarget, createdAt *time.Time, status int) {
+	followersCount, err := db.GetFollwersCount(r.Context(), target.UserID)
+	if err != nil {
+		http.Error(w, "Couldn't get follower count", http.StatusInternalServerError) // 500
+		return
+	}
+	followingCount, err := db.GetFollowingCount(r.Context(), user.UserID)
+	if err != nil {
+		http.Error(w, "Couldn't get following count", http.StatusInternalServerError) // 500
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ReturnedFollow{
+		FollowerID:     user.UserID,
+		FollowingID:    target.UserID,
+		Username:       target.Username,
+		Name:           target.Name,
+		CreatedAt:      createdAt,
+		FollowersCount: followersCount,
+		FollowingCount: followingCount,
+	})
+}
+
+func followUser(w http.ResponseWriter, r *http.Request, db *database.Queries, user database.User, target followTarget) {
+	blocked, err := isBlockedBy(r.Context(), db, target.UserID, user.UserID)
+	if err != nil {
+		http.Error(w, "Couldn't check blocks", http.StatusInternalServerError) // 500
+		return
+	}
+	if blocked {
+		http.Error(w, "You can't follow this user", http.StatusForbidden) // 403
+		return
+	}
+	blocking, err := isBlockedBy(r.Context(), db, user.UserID, target.UserID)
+	if err != nil {
+		http.Error(w, "Couldn't check blocks", http.StatusInternalServerError) // 500
+		return
+	}
+	if blocking {
+		http.Error(w, "Unblock this user before following them", http.StatusForbidden) // 403
+		return
+	}
+
+	follow, err := db.CreateFollow(r.Context(), database.CreateFollowParams{
+		FollowerID:  user.UserID,
+		FollowingID: target.UserID,
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "You already follow this user", http.StatusConflict) // 409
+		return
+	}
+	if err != nil {
+		http.Error(w, "Couldn't follow user", http.StatusInternalServerError) // 500
+		return
+	}
+
+	writeFollowResponse(w, r, db, user, target, &follow.CreatedAt, http.StatusCreated) // 201
+}
+
+func unfollowUser(w http.ResponseWriter, r *http.Request, db *database.Queries, user database.User, target followTarget) {
+	rows, err := db.DeleteFollow(r.Context(), database.DeleteFollowParams{
+		FollowerID:  user.UserID,
+		FollowingID: target.UserID,
+	})
+	if err != nil {
+		http.Error(w, "Couldn't unfollow user", http.StatusInternalServerError) // 500
+		return
+	}
+	if rows == 0 {
+		http.Error(w, "You don't follow this user", http.StatusNotFound) // 404
+		return
+	}
+
+	writeFollowResponse(w, r, db, user, target, nil, http.StatusOK) // 200
+}
+
+// CreateFollowHandler follows the user named by "username" or, for older
+// clients, "following_id" in the request body.
 func CreateFollowHandler(db *database.Queries, user database.User) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		type parameters struct {
+			Username    string    `json:"username"`
 			FollowingID uuid.UUID `json:"following_id"`
 		}
 
@@ -44,16 +380,21 @@ func CreateFollowHandler(db *database.Queries, user database.User) http.Handler
 			return
 		}
 
-		err := db.CreateFollow(r.Context(), database.CreateFollowParams{
-			FollowerID:  user.UserID,
-			FollowingID: params.FollowingID,
-		})
-		if err != nil {
-			http.Error(w, "Couldn't follow user", http.StatusInternalServerError) // 500
+		target, ok := resolveFollowTarget(w, r, db, user, params.Username, params.FollowingID)
+		if !ok {
 			return
 		}
+		followUser(w, r, db, user, target)
+	})
+}
 
-		w.WriteHeader(http.StatusCreated) // 201
+func FollowByUsernameHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target, ok := resolveFollowTarget(w, r, db, user, chi.URLParam(r, "username"), uuid.Nil)
+		if !ok {
+			return
+		}
+		followUser(w, r, db, user, target)
 	})
 }
 
@@ -67,16 +408,21 @@ func DeleteFollowHandler(db *database.Queries, user database.User) http.Handler
 			return
 		}
 
-		err = db.DeleteFollow(r.Context(), database.DeleteFollowParams{
-			FollowerID:  user.UserID,
-			FollowingID: followeeUUID,
-		})
-		if err != nil {
-			http.Error(w, "Couldn't unfollow user", http.StatusInternalServerError) // 500
+		target, ok := resolveFollowTarget(w, r, db, user, "", followeeUUID)
+		if !ok {
 			return
 		}
+		unfollowUser(w, r, db, user, target)
+	})
+}
 
-		w.WriteHeader(http.StatusOK) // 200
+func UnfollowByUsernameHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target, ok := resolveFollowTarget(w, r, db, user, chi.URLParam(r, "username"), uuid.Nil)
+		if !ok {
+			return
+		}
+		unfollowUser(w, r, db, user, target)
 	})
 }
 
@@ -89,6 +435,19 @@ func GetFeedHandler(db *database.Queries, user database.User) http.Handler {
 			return
 		}
 
+		hidden, err := hiddenUserSet(r.Context(), db, user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get muted users", http.StatusInternalServerError)
+			return
+		}
+		visible := feed[:0]
+		for _, post := range feed {
+			if !hidden[post.UserID] {
+				visible = append(visible, post)
+			}
+		}
+		feed = visible
+
 		w.WriteHeader(http.StatusOK) // 200
 		json.NewEncoder(w).Encode(feed)
 	})
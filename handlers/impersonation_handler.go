@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+const (
+	defaultImpersonationLifetime = 15 * time.Minute
+	maxImpersonationLifetime     = time.Hour
+)
+
+type ReturnedImpersonation struct {
+	ImpersonationID uuid.UUID  `json:"impersonation_id"`
+	ModeratorID     *uuid.UUID `json:"moderator_id,omitempty"`
+	ModeratorName   string     `json:"moderator_name,omitempty"`
+	UserID          *uuid.UUID `json:"user_id,omitempty"`
+	Username        string     `json:"username,omitempty"`
+	Reason          string     `json:"reason"`
+	ReadOnly        bool       `json:"read_only"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	EndedAt         *time.Time `json:"ended_at"`
+}
+
+type ReturnedImpersonationRequest struct {
+	ImpersonationID uuid.UUID `json:"impersonation_id"`
+	Method          string    `json:"method"`
+	Path            string    `json:"path"`
+	StatusCode      int32     `json:"status_code"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func generateImpersonationToken(impersonation database.Impersonation) (string, error) {
+	godotenv.Load(".env")
+	var jwtSecretKey = os.Getenv("SECRET_KEY")
+	claims := jwt.MapClaims{
+		"user_id":          impersonation.UserID,
+		"impersonation_id": impersonation.ImpersonationID,
+		"impersonator_id":  impersonation.ModeratorID,
+		"read_only":        impersonation.ReadOnly,
+
+		"exp": impersonation.ExpiresAt.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecretKey))
+}
+
+func toReturnedImpersonationRequests(requests []database.ImpersonationRequest) []ReturnedImpersonationRequest {
+	returned := make([]ReturnedImpersonationRequest, len(requests))
+	for i, request := range requests {
+		returned[i] = ReturnedImpersonationRequest{
+			ImpersonationID: request.ImpersonationID,
+			Method:          request.Method,
+			Path:            request.Path,
+			StatusCode:      request.StatusCode,
+			CreatedAt:       request.CreatedAt,
+		}
+	}
+	return returned
+}
+
+// CreateImpersonationHandler lets an admin view the site as a user. The token
+// it returns is sent as "Authorization: Bearer ..." and is read-only unless
+// read_only is explicitly false. The user is told straight away.
+func CreateImpersonationHandler(db *database.Queries, moderator database.Moderator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if moderator.Role != "admin" {
+			http.Error(w, "Only admins can impersonate users", http.StatusForbidden)
+			return
+		}
+
+		type parameters struct {
+			Username        string `json:"username"`
+			Reason          string `json:"reason"`
+			ReadOnly        *bool  `json:"read_only"`
+			DurationMinutes int    `json:"duration_minutes"`
+		}
+		params := parameters{}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		params.Reason = strings.TrimSpace(params.Reason)
+		if params.Reason == "" {
+			http.Error(w, "A reason is required", http.StatusBadRequest)
+			return
+		}
+
+		lifetime := defaultImpersonationLifetime
+		if params.DurationMinutes > 0 {
+			lifetime = time.Duration(params.DurationMinutes) * time.Minute
+		}
+		if lifetime > maxImpersonationLifetime {
+			http.Error(w, "Impersonation can last at most 60 minutes", http.StatusBadRequest)
+			return
+		}
+
+		readOnly := true
+		if params.ReadOnly != nil {
+			readOnly = *params.ReadOnly
+		}
+
+		user, err := db.GetUserByUsername(r.Context(), params.Username)
+		if err != nil {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+
+		now := time.Now().UTC()
+		impersonation, err := db.CreateImpersonation(r.Context(), database.CreateImpersonationParams{
+			ImpersonationID: uuid.New(),
+			ModeratorID:     moderator.ModeratorID,
+			UserID:          user.UserID,
+			Reason:          params.Reason,
+			ReadOnly:        readOnly,
+			CreatedAt:       now,
+			ExpiresAt:       now.Add(lifetime),
+		})
+		if err != nil {
+			http.Error(w, "Couldn't start impersonation", http.StatusInternalServerError)
+			return
+		}
+
+		token, err := generateImpersonationToken(impersonation)
+		if err != nil {
+			http.Error(w, "Couldn't generate impersonation token", http.StatusInternalServerError)
+			return
+		}
+
+		notifyUser(r.Context(), db, user.UserID, "account_impersonated",
+			"A moderator is viewing your account to investigate an issue: "+params.Reason,
+			map[string]interface{}{
+				"impersonation_id": impersonation.ImpersonationID,
+				"read_only":        impersonation.ReadOnly,
+				"expires_at":       impersonation.ExpiresAt,
+			})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token": token,
+			"impersonation": ReturnedImpersonation{
+				ImpersonationID: impersonation.ImpersonationID,
+				ModeratorID:     &impersonation.ModeratorID,
+				ModeratorName:   moderator.Name,
+				UserID:          &impersonation.UserID,
+				Username:        user.Username,
+				Reason:          impersonation.Reason,
+				ReadOnly:        impersonation.ReadOnly,
+				CreatedAt:       impersonation.CreatedAt,
+				ExpiresAt:       impersonation.ExpiresAt,
+			},
+		})
+	}
+}
+
+func EndImpersonationHandler(db *database.Queries, moderator database.Moderator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		impersonationID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid impersonation ID", http.StatusBadRequest)
+			return
+		}
+
+		rows, err := db.EndImpersonation(r.Context(), database.EndImpersonationParams{
+			ImpersonationID: impersonationID,
+			ModeratorID:     moderator.ModeratorID,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't end impersonation", http.StatusInternalServerError)
+			return
+		}
+		if rows == 0 {
+			http.Error(w, "Impersonation not found", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func GetImpersonationsHandler(db *database.Queries, moderator database.Moderator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if moderator.Role != "admin" {
+			http.Error(w, "Only admins can view impersonations", http.StatusForbidden)
+			return
+		}
+
+		impersonations, err := db.ListImpersonations(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't get impersonations", http.StatusInternalServerError)
+			return
+		}
+
+		returned := make([]ReturnedImpersonation, len(impersonations))
+		for i, impersonation := range impersonations {
+			returned[i] = ReturnedImpersonation{
+				ImpersonationID: impersonation.ImpersonationID,
+				ModeratorID:     &impersonation.ModeratorID,
+				ModeratorName:   impersonation.ModeratorName,
+				UserID:          &impersonation.UserID,
+				Username:        impersonation.Username,
+				Reason:          impersonation.Reason,
+				ReadOnly:        impersonation.ReadOnly,
+				CreatedAt:       impersonation.CreatedAt,
+				ExpiresAt:       impersonation.ExpiresAt,
+				EndedAt:         nullTimePtr(impersonation.EndedAt),
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	}
+}
+
+func GetImpersonationRequestsHandler(db *database.Queries, moderator database.Moderator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if moderator.Role != "admin" {
+			http.Error(w, "Only admins can view impersonations", http.StatusForbidden)
+			return
+		}
+
+		impersonationID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid impersonation ID", http.StatusBadRequest)
+			return
+		}
+
+		requests, err := db.ListImpersonationRequests(r.Context(), impersonationID)
+		if err != nil {
+			http.Error(w, "Couldn't get impersonated requests", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(toReturnedImpersonationRequests(requests))
+	}
+}
+
+// GetMyImpersonationsHandler shows a user every time a moderator viewed their
+// account and what was requested while they did.
+func GetMyImpersonationsHandler(db *database.Queries, user database.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		impersonations, err := db.ListImpersonationsOfUser(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get impersonations", http.StatusInternalServerError)
+			return
+		}
+
+		requests, err := db.ListImpersonationRequestsOfUser(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get impersonated requests", http.StatusInternalServerError)
+			return
+		}
+
+		returned := make([]ReturnedImpersonation, len(impersonations))
+		for i, impersonation := range impersonations {
+			returned[i] = ReturnedImpersonation{
+				ImpersonationID: impersonation.ImpersonationID,
+				ModeratorName:   impersonation.ModeratorName,
+				Reason:          impersonation.Reason,
+				ReadOnly:        impersonation.ReadOnly,
+				CreatedAt:       impersonation.CreatedAt,
+				ExpiresAt:       impersonation.ExpiresAt,
+				EndedAt:         nullTimePtr(impersonation.EndedAt),
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"impersonations": returned,
+			"requests":       toReturnedImpersonationRequests(requests),
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Moderation actions recorded in the audit log.
+const (
+	ActionReportStatusChanged      = "report_status_changed"
+	ActionReportCaseClosed         = "report_case_closed"
+	ActionSuspensionApplied        = "suspension_applied"
+	ActionSuspensionLifted         = "suspension_lifted"
+	ActionAppealDecided            = "appeal_decided"
+	ActionApplicationStatusChanged = "application_status_changed"
+	ActionContributorStatusChanged = "contributor_status_changed"
+	ActionModeratorCreated         = "moderator_created"
+	ActionContentRemoved           = "content_removed"
+	ActionContentLabeled           = "content_labeled"
+	ActionContentRestored          = "content_restored"
+	ActionStrikeIssued             = "strike_issued"
+	ActionStrikeRevoked            = "strike_revoked"
+	ActionUserBanned               = "user_banned"
+	ActionEnforcementPolicyUpdated = "enforcement_policy_updated"
+)
+
+// moderationAction is one entry for the audit log. Before and After hold
+// the parts of the target's state the action changed.
+type moderationAction struct {
+	Action        string
+	TargetType    string
+	TargetID      uuid.UUID
+	SubjectUserID uuid.NullUUID
+	Before        map[string]interface{}
+	After         map[string]interface{}
+	Reason        string
+}
+
+// recordModerationAction appends to the audit log. Write it with the same
+// queries as the action it records, inside that action's transaction, so
+// nothing a moderator does goes unaudited.
+func recordModerationAction(ctx context.Context, db *database.Queries, moderatorID uuid.UUID, action moderationAction) error {
+	if action.Before == nil {
+		action.Before = map[string]interface{}{}
+	}
+	if action.After == nil {
+		action.After = map[string]interface{}{}
+	}
+	before, err := json.Marshal(action.Before)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(action.After)
+	if err != nil {
+		return err
+	}
+
+	return db.CreateModerationAction(ctx, database.CreateModerationActionParams{
+		ActionID:      uuid.New(),
+		ModeratorID:   uuid.NullUUID{UUID: moderatorID, Valid: true},
+		Action:        action.Action,
+		TargetType:    action.TargetType,
+		TargetID:      action.TargetID,
+		SubjectUserID: action.SubjectUserID,
+		BeforeState:   before,
+		AfterState:    after,
+		Reason:        action.Reason,
+		CreatedAt:     time.Now().UTC(),
+	})
+}
+
+type ReturnedModerationAction struct {
+	ActionID        uuid.UUID       `json:"action_id"`
+	ModeratorID     *uuid.UUID      `json:"moderator_id"`
+	ModeratorName   string          `json:"moderator_name"`
+	Action          string          `json:"action"`
+	TargetType      string          `json:"target_type"`
+	TargetID        uuid.UUID       `json:"target_id"`
+	SubjectUserID   *uuid.UUID      `json:"subject_user_id"`
+	SubjectUsername string          `json:"subject_username"`
+	Before          json.RawMessage `json:"before"`
+	After           json.RawMessage `json:"after"`
+	Reason          string          `json:"reason"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// listModerationActions loads a page of the audit log matching params, taking
+// the pagination from the request. It writes the error response itself.
+func listModerationActions(w http.ResponseWriter, r *http.Request, db *database.Queries, params *database.ListModerationActionsParams) ([]ReturnedModerationAction, bool) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest) // 400
+		return nil, false
+	}
+	params.PageLimit = limit
+	params.PageOffset = offset
+
+	rows, err := db.ListModerationActions(r.Context(), *params)
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, "Couldn't get moderation actions", http.StatusInternalServerError) // 500
+		return nil, false
+	}
+
+	actions := make([]ReturnedModerationAction, len(rows))
+	for i, row := range rows {
+		actions[i] = ReturnedModerationAction{
+			ActionID:        row.ActionID,
+			ModeratorID:     nullUUIDPtr(row.ModeratorID),
+			ModeratorName:   row.ModeratorName.String,
+			Action:          row.Action,
+			TargetType:      row.TargetType,
+			TargetID:        row.TargetID,
+			SubjectUserID:   nullUUIDPtr(row.SubjectUserID),
+			SubjectUsername: row.SubjectUsername.String,
+			Before:          row.BeforeState,
+			After:           row.AfterState,
+			Reason:          row.Reason,
+			CreatedAt:       row.CreatedAt,
+		}
+	}
+	return actions, true
+}
+
+func parseOptionalUUID(raw string) (uuid.NullUUID, error) {
+	if raw == "" {
+		return uuid.NullUUID{}, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: id, Valid: true}, nil
+}
+
+func parseOptionalTime(raw string) (sql.NullTime, error) {
+	if raw == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+}
+
+// GetModerationActionsHandler lists the audit log, newest first. Admins
+// only. Filters: ?moderator=, ?action=, ?target_type=, ?target_id=, ?user=
+// (the user the action was about) and ?since= / ?until= as RFC 3339 times.
+func GetModerationActionsHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if moderator.Role != "admin" {
+			http.Error(w, "Only admins can view the moderation log", http.StatusForbidden) // 403
+			return
+		}
+
+		query := r.URL.Query()
+		params := database.ListModerationActionsParams{
+			Action:     query.Get("action"),
+			TargetType: query.Get("target_type"),
+		}
+
+		var err error
+		for name, dest := range map[string]*uuid.NullUUID{
+			"moderator": &params.ModeratorID,
+			"target_id": &params.TargetID,
+			"user":      &params.SubjectUserID,
+		} {
+			if *dest, err = parseOptionalUUID(query.Get(name)); err != nil {
+				http.Error(w, "Invalid "+name+" ID", http.StatusBadRequest) // 400
+				return
+			}
+		}
+		for name, dest := range map[string]*sql.NullTime{
+			"since": &params.Since,
+			"until": &params.Until,
+		} {
+			if *dest, err = parseOptionalTime(query.Get(name)); err != nil {
+				http.Error(w, name+" must be an RFC 3339 time", http.StatusBadRequest) // 400
+				return
+			}
+		}
+
+		actions, ok := listModerationActions(w, r, db, &params)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"actions": actions,
+			"limit":   params.PageLimit,
+			"offset":  params.PageOffset,
+		})
+	})
+}
+
+// GetUserModerationHistoryHandler shows every moderation action taken about
+// a user, newest first, with their current suspension.
+func GetUserModerationHistoryHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest) // 400
+			return
+		}
+
+		params := database.ListModerationActionsParams{
+			SubjectUserID: uuid.NullUUID{UUID: userID, Valid: true},
+		}
+		actions, ok := listModerationActions(w, r, db, &params)
+		if !ok {
+			return
+		}
+
+		// Purged accounts keep their history.
+		var suspendedUntil *time.Time
+		user, err := db.GetUserById(r.Context(), userID)
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, "Couldn't get user", http.StatusInternalServerError) // 500
+			return
+		}
+		if err == nil {
+			suspendedUntil = nullTimePtr(user.SuspendedUntil)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"user_id":         userID,
+			"suspended_until": suspendedUntil,
+			"actions":         actions,
+			"limit":           params.PageLimit,
+			"offset":          params.PageOffset,
+		})
+	})
+}
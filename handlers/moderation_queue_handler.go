@@ -0,0 +1,449 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Moderation queue item types. A report item is a report case.
+const (
+	QueueReport      = "report"
+	QueueAppeal      = "appeal"
+	QueueApplication = "application"
+)
+
+const (
+	// claimTimeout is how long a claim holds before the item goes back to
+	// the queue. Claiming again renews it.
+	claimTimeout = 30 * time.Minute
+	// assignmentTimeout is how long an assigned item stays with its assignee.
+	assignmentTimeout = 24 * time.Hour
+	// slaDueSoon is the share of an item's SLA after which it's due soon.
+	slaDueSoon = 0.75
+)
+
+// SLA states of a queue item.
+const (
+	SLAOnTrack = "on_track"
+	SLADueSoon = "due_soon"
+	SLAOverdue = "overdue"
+)
+
+var queueItemTypes = map[string]bool{
+	QueueReport:      true,
+	QueueAppeal:      true,
+	QueueApplication: true,
+}
+
+// reportSLAs is how long a report case may wait, by severity.
+var reportSLAs = map[int32]time.Duration{
+	1: 72 * time.Hour,
+	2: 48 * time.Hour,
+	3: 24 * time.Hour,
+	4: 4 * time.Hour,
+}
+
+// moderationSLA is how long an item may wait in the queue.
+func moderationSLA(itemType string, severity int32) time.Duration {
+	switch itemType {
+	case QueueReport:
+		if sla, ok := reportSLAs[severity]; ok {
+			return sla
+		}
+		return reportSLAs[1]
+	case QueueAppeal:
+		return 72 * time.Hour
+	default:
+		return 7 * 24 * time.Hour
+	}
+}
+
+func slaStatus(age, sla time.Duration) string {
+	switch {
+	case age >= sla:
+		return SLAOverdue
+	case float64(age) >= float64(sla)*slaDueSoon:
+		return SLADueSoon
+	default:
+		return SLAOnTrack
+	}
+}
+
+type ReturnedQueueClaim struct {
+	ClaimedBy     uuid.UUID  `json:"claimed_by"`
+	ClaimedByName string     `json:"claimed_by_name,omitempty"`
+	AssignedBy    *uuid.UUID `json:"assigned_by"`
+	ClaimedAt     time.Time  `json:"claimed_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+}
+
+type ReturnedQueueItem struct {
+	ItemType        string              `json:"item_type"`
+	ItemID          uuid.UUID           `json:"item_id"`
+	Status          string              `json:"status"`
+	Summary         string              `json:"summary"`
+	Severity        int32               `json:"severity"`
+	SeverityName    string              `json:"severity_name,omitempty"`
+	SubjectUserID   uuid.UUID           `json:"subject_user_id"`
+	SubjectName     string              `json:"subject_name"`
+	SubjectUsername string              `json:"subject_username"`
+	CreatedAt       time.Time           `json:"created_at"`
+	AgeHours        float64             `json:"age_hours"`
+	SLADueAt        time.Time           `json:"sla_due_at"`
+	SLAStatus       string              `json:"sla_status"`
+	Claim           *ReturnedQueueClaim `json:"claim"`
+}
+
+func toReturnedQueueClaim(claim database.ModerationClaim) *ReturnedQueueClaim {
+	return &ReturnedQueueClaim{
+		ClaimedBy:  claim.ClaimedBy,
+		AssignedBy: nullUUIDPtr(claim.AssignedBy),
+		ClaimedAt:  claim.ClaimedAt,
+		ExpiresAt:  claim.ExpiresAt,
+	}
+}
+
+// GetModerationQueueHandler lists open report cases, pending appeals and
+// contributor applications awaiting a decision in one queue, most severe and
+// then oldest first. Filters: ?type= (report, appeal or application),
+// ?status=, ?min_age_hours=, ?claimed= (unclaimed, claimed or mine) and
+// ?assignee= (a moderator ID).
+func GetModerationQueueHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		now := time.Now().UTC()
+
+		itemType := query.Get("type")
+		if itemType != "" && !queueItemTypes[itemType] {
+			http.Error(w, "type must be report, appeal or application", http.StatusBadRequest) // 400
+			return
+		}
+
+		createdBefore := sql.NullTime{}
+		if raw := query.Get("min_age_hours"); raw != "" {
+			hours, err := strconv.Atoi(raw)
+			if err != nil || hours < 0 {
+				http.Error(w, "min_age_hours must be zero or a positive number", http.StatusBadRequest) // 400
+				return
+			}
+			createdBefore = sql.NullTime{Time: now.Add(-time.Duration(hours) * time.Hour), Valid: true}
+		}
+
+		claimState := query.Get("claimed")
+		claimedBy := uuid.NullUUID{}
+		switch claimState {
+		case "", "unclaimed", "claimed":
+		case "mine":
+			claimState = ""
+			claimedBy = uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true}
+		default:
+			http.Error(w, "claimed must be unclaimed, claimed or mine", http.StatusBadRequest) // 400
+			return
+		}
+		if raw := query.Get("assignee"); raw != "" {
+			assignee, err := uuid.Parse(raw)
+			if err != nil {
+				http.Error(w, "Invalid assignee ID", http.StatusBadRequest) // 400
+				return
+			}
+			claimedBy = uuid.NullUUID{UUID: assignee, Valid: true}
+		}
+
+		limit, offset, err := parsePagination(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest) // 400
+			return
+		}
+
+		rows, err := db.ListModerationQueue(r.Context(), database.ListModerationQueueParams{
+			Now:           now,
+			ItemType:      itemType,
+			Status:        query.Get("status"),
+			CreatedBefore: createdBefore,
+			ClaimState:    claimState,
+			ClaimedBy:     claimedBy,
+			PageLimit:     limit,
+			PageOffset:    offset,
+		})
+		if err != nil {
+			fmt.Println(err)
+			http.Error(w, "Couldn't get moderation queue", http.StatusInternalServerError) // 500
+			return
+		}
+
+		items := make([]ReturnedQueueItem, len(rows))
+		for i, row := range rows {
+			age := now.Sub(row.CreatedAt)
+			sla := moderationSLA(row.ItemType, row.Severity)
+			items[i] = ReturnedQueueItem{
+				ItemType:        row.ItemType,
+				ItemID:          row.ItemID,
+				Status:          row.Status,
+				Summary:         row.Summary,
+				Severity:        row.Severity,
+				SeverityName:    severityNames[row.Severity],
+				SubjectUserID:   row.SubjectUserID,
+				SubjectName:     row.SubjectName,
+				SubjectUsername: row.SubjectUsername,
+				CreatedAt:       row.CreatedAt,
+				AgeHours:        age.Hours(),
+				SLADueAt:        row.CreatedAt.Add(sla),
+				SLAStatus:       slaStatus(age, sla),
+			}
+			if row.ClaimedBy.Valid {
+				items[i].Claim = &ReturnedQueueClaim{
+					ClaimedBy:     row.ClaimedBy.UUID,
+					ClaimedByName: row.ClaimedByName.String,
+					AssignedBy:    nullUUIDPtr(row.AssignedBy),
+					ClaimedAt:     row.ClaimedAt.Time,
+					ExpiresAt:     row.ClaimExpiresAt.Time,
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"items":  items,
+			"limit":  limit,
+			"offset": offset,
+		})
+	})
+}
+
+// isOpenQueueItem reports whether the item still needs a moderator's decision.
+func isOpenQueueItem(ctx context.Context, db *database.Queries, itemType string, itemID uuid.UUID) (bool, error) {
+	switch itemType {
+	case QueueReport:
+		reportCase, err := db.GetReportCase(ctx, itemID)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return reportCase.Status == CaseOpen, nil
+	case QueueAppeal:
+		appeal, err := db.GetAppealById(ctx, itemID)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return !appeal.AppealStatus.Valid || appeal.AppealStatus.String == "pending", nil
+	case QueueApplication:
+		application, err := db.GetContributorApplication(ctx, itemID)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		status := applicationStatus(application.Status)
+		return status == ApplicationPending || status == ApplicationUnderReview, nil
+	}
+	return false, nil
+}
+
+// queueItemFromURL reads {type} and {id} and checks the item is still open,
+// writing the error response itself.
+func queueItemFromURL(w http.ResponseWriter, r *http.Request, db *database.Queries) (string, uuid.UUID, bool) {
+	itemType := chi.URLParam(r, "type")
+	if !queueItemTypes[itemType] {
+		http.Error(w, "type must be report, appeal or application", http.StatusBadRequest) // 400
+		return "", uuid.Nil, false
+	}
+	itemID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid item ID", http.StatusBadRequest) // 400
+		return "", uuid.Nil, false
+	}
+
+	open, err := isOpenQueueItem(r.Context(), db, itemType, itemID)
+	if err != nil {
+		http.Error(w, "Couldn't get queue item", http.StatusInternalServerError) // 500
+		return "", uuid.Nil, false
+	}
+	if !open {
+		http.Error(w, "Item isn't waiting in the queue", http.StatusNotFound) // 404
+		return "", uuid.Nil, false
+	}
+	return itemType, itemID, true
+}
+
+// checkModerationClaim stops a moderator from deciding an item someone else
+// has claimed, writing the error response itself.
+func checkModerationClaim(w http.ResponseWriter, r *http.Request, db *database.Queries, moderator database.Moderator, itemType string, itemID uuid.UUID) bool {
+	claim, err := db.GetModerationClaim(r.Context(), database.GetModerationClaimParams{
+		ItemType:  itemType,
+		ItemID:    itemID,
+		ExpiresAt: time.Now().UTC(),
+	})
+	if err == sql.ErrNoRows {
+		return true
+	}
+	if err != nil {
+		http.Error(w, "Couldn't check claim", http.StatusInternalServerError) // 500
+		return false
+	}
+	if claim.ClaimedBy != moderator.ModeratorID {
+		http.Error(w, "Another moderator has claimed this item", http.StatusConflict) // 409
+		return false
+	}
+	return true
+}
+
+// releaseDecidedItem drops the claim on an item once it's been decided.
+func releaseDecidedItem(ctx context.Context, db *database.Queries, itemType string, itemID uuid.UUID) {
+	if err := db.DeleteModerationClaim(ctx, database.DeleteModerationClaimParams{
+		ItemType: itemType,
+		ItemID:   itemID,
+	}); err != nil {
+		fmt.Printf("Failed to release %s %s: %v\n", itemType, itemID, err)
+	}
+}
+
+// ClaimQueueItemHandler claims an item for the moderator for 30 minutes, or
+// renews their claim. Items claimed by someone else can't be taken until
+// the claim lapses or is released.
+func ClaimQueueItemHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		itemType, itemID, ok := queueItemFromURL(w, r, db)
+		if !ok {
+			return
+		}
+
+		now := time.Now().UTC()
+		claim, err := db.ClaimModerationItem(r.Context(), database.ClaimModerationItemParams{
+			ItemType:  itemType,
+			ItemID:    itemID,
+			ClaimedBy: moderator.ModeratorID,
+			ClaimedAt: now,
+			ExpiresAt: now.Add(claimTimeout),
+		})
+		if err == sql.ErrNoRows {
+			http.Error(w, "Another moderator has claimed this item", http.StatusConflict) // 409
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't claim item", http.StatusInternalServerError) // 500
+			return
+		}
+
+		returned := toReturnedQueueClaim(claim)
+		returned.ClaimedByName = moderator.Name
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
+
+// ReleaseQueueItemHandler gives a claimed item back to the queue. Admins can
+// release anyone's claim; moderators only their own.
+func ReleaseQueueItemHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		itemType := chi.URLParam(r, "type")
+		itemID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid item ID", http.StatusBadRequest) // 400
+			return
+		}
+
+		claim, err := db.GetModerationClaim(r.Context(), database.GetModerationClaimParams{
+			ItemType:  itemType,
+			ItemID:    itemID,
+			ExpiresAt: time.Now().UTC(),
+		})
+		if err == sql.ErrNoRows {
+			http.Error(w, "Item isn't claimed", http.StatusNotFound) // 404
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get claim", http.StatusInternalServerError) // 500
+			return
+		}
+		if claim.ClaimedBy != moderator.ModeratorID && moderator.Role != "admin" {
+			http.Error(w, "Only admins can release another moderator's claim", http.StatusForbidden) // 403
+			return
+		}
+
+		released, err := db.ReleaseModerationItem(r.Context(), database.ReleaseModerationItemParams{
+			ItemType:  itemType,
+			ItemID:    itemID,
+			ClaimedBy: claim.ClaimedBy,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't release item", http.StatusInternalServerError) // 500
+			return
+		}
+		if released == 0 {
+			http.Error(w, "Claim was changed by someone else; reload and try again", http.StatusConflict) // 409
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent) // 204
+	})
+}
+
+// AssignQueueItemHandler hands an item to a specific moderator for 24 hours,
+// replacing any claim on it. Admins can assign any item; moderators can only
+// pass on items they hold.
+func AssignQueueItemHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type parameters struct {
+			ModeratorID uuid.UUID `json:"moderator_id"`
+		}
+
+		var params parameters
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest) // 400
+			return
+		}
+
+		itemType, itemID, ok := queueItemFromURL(w, r, db)
+		if !ok {
+			return
+		}
+
+		if moderator.Role != "admin" && !checkModerationClaim(w, r, db, moderator, itemType, itemID) {
+			return
+		}
+
+		assignee, err := db.GetModeratorById(r.Context(), params.ModeratorID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Moderator not found", http.StatusBadRequest) // 400
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get moderator", http.StatusInternalServerError) // 500
+			return
+		}
+
+		now := time.Now().UTC()
+		claim, err := db.AssignModerationItem(r.Context(), database.AssignModerationItemParams{
+			ItemType:   itemType,
+			ItemID:     itemID,
+			ClaimedBy:  assignee.ModeratorID,
+			AssignedBy: uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+			ClaimedAt:  now,
+			ExpiresAt:  now.Add(assignmentTimeout),
+		})
+		if err != nil {
+			http.Error(w, "Couldn't assign item", http.StatusInternalServerError) // 500
+			return
+		}
+
+		returned := toReturnedQueueClaim(claim)
+		returned.ClaimedByName = assignee.Name
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
@@ -41,13 +41,26 @@ func CreateModeratorHandler(db *database.Queries, moderator database.Moderator)
 			http.Error(w, "Counldn't hash password", http.StatusInternalServerError)
 		}
 
-		moderator, err := db.CreateModerator(r.Context(), database.CreateModeratorParams{
-			ModeratorID: uuid.New(),
-			Name:        params.Name,
-			Email:       params.Email,
-			Password:    string(hashedPassword),
-			CreatedBy:   uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
-			Role:        params.Roles,
+		var created database.CreateModeratorRow
+		err = db.RunInTx(r.Context(), func(q *database.Queries) error {
+			var err error
+			created, err = q.CreateModerator(r.Context(), database.CreateModeratorParams{
+				ModeratorID: uuid.New(),
+				Name:        params.Name,
+				Email:       params.Email,
+				Password:    string(hashedPassword),
+				CreatedBy:   uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+				Role:        params.Roles,
+			})
+			if err != nil {
+				return err
+			}
+			return recordModerationAction(r.Context(), q, moderator.ModeratorID, moderationAction{
+				Action:     ActionModeratorCreated,
+				TargetType: "moderator",
+				TargetID:   created.ModeratorID,
+				After:      map[string]interface{}{"name": created.Name, "email": created.Email, "role": created.Role},
+			})
 		})
 
 		if err != nil {
@@ -57,7 +70,7 @@ func CreateModeratorHandler(db *database.Queries, moderator database.Moderator)
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(moderator)
+		json.NewEncoder(w).Encode(created)
 	})
 }
 
@@ -91,16 +104,10 @@ func LoginModeratorController(db *database.Queries) http.Handler {
 			return
 		}
 
-		accessToken, err := generateAccessToken(moderator.ModeratorID)
+		accessToken, refreshToken, err := issueSession(db, r, moderator.ModeratorID, true)
 		if err != nil {
 			fmt.Println(err)
-			http.Error(w, "Couldn't generate access token", http.StatusInternalServerError)
-			return
-		}
-
-		refreshToken, err := generateRefreshToken(moderator.ModeratorID)
-		if err != nil {
-			http.Error(w, "Couldn't generate refresh token", http.StatusInternalServerError)
+			http.Error(w, "Couldn't start session", http.StatusInternalServerError)
 			return
 		}
 
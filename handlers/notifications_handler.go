@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ReturnedNotification struct {
+	NotificationID uuid.UUID       `json:"notification_id"`
+	Type           string          `json:"type"`
+	Message        string          `json:"message"`
+	Data           json.RawMessage `json:"data"`
+	ReadAt         *time.Time      `json:"read_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// notification is held back until the transaction that caused it commits.
+// A failed statement aborts a Postgres transaction, so sending it inside
+// would let a failed notification undo the decision it's about.
+type notification struct {
+	UserID  uuid.UUID
+	Type    string
+	Message string
+	Data    map[string]interface{}
+}
+
+// sendNotifications sends notifications held back until a commit. Failures
+// are only logged.
+func sendNotifications(ctx context.Context, db *database.Queries, notifications []notification) {
+	for _, n := range notifications {
+		if err := notifyUser(ctx, db, n.UserID, n.Type, n.Message, n.Data); err != nil {
+			fmt.Printf("Failed to send %s notification to %s: %v\n", n.Type, n.UserID, err)
+		}
+	}
+}
+
+// notifyUser stores an in-app notification for a user. data is any extra
+// context the frontend needs to render or link the notification.
+func notifyUser(ctx context.Context, db *database.Queries, userID uuid.UUID, notificationType string, message string, data map[string]interface{}) error {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return db.CreateNotification(ctx, database.CreateNotificationParams{
+		NotificationID: uuid.New(),
+		UserID:         userID,
+		Type:           notificationType,
+		Message:        message,
+		Data:           encoded,
+	})
+}
+
+func GetNotificationsHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notifications, err := db.ListNotificationsByUser(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get notifications", http.StatusInternalServerError)
+			return
+		}
+
+		unread, err := db.CountUnreadNotifications(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't count notifications", http.StatusInternalServerError)
+			return
+		}
+
+		returnedNotifications := make([]ReturnedNotification, len(notifications))
+		for i, notification := range notifications {
+			returnedNotifications[i] = ReturnedNotification{
+				NotificationID: notification.NotificationID,
+				Type:           notification.Type,
+				Message:        notification.Message,
+				Data:           notification.Data,
+				ReadAt:         nullTimePtr(notification.ReadAt),
+				CreatedAt:      notification.CreatedAt,
+			}
+		}
+
+		response := map[string]interface{}{
+			"notifications": returnedNotifications,
+			"unread_count":  unread,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+}
+
+func MarkNotificationReadHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notificationID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+			return
+		}
+
+		_, err = db.MarkNotificationRead(r.Context(), database.MarkNotificationReadParams{
+			NotificationID: notificationID,
+			UserID:         user.UserID,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't update notification", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func MarkAllNotificationsReadHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := db.MarkAllNotificationsRead(r.Context(), user.UserID); err != nil {
+			http.Error(w, "Couldn't update notifications", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
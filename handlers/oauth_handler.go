@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/pkg/oauth"
+	"github.com/MyoMyatMin/expertly-backend/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+const oauthStateCookie = "oauth_state"
+
+func randomURLSafe(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// OAuthStartHandler redirects the browser to the provider's login page. The
+// state, nonce and PKCE verifier travel in a short-lived cookie so the
+// callback can check them.
+func OAuthStartHandler(db *database.Queries) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		godotenv.Load(".env")
+
+		provider, err := oauth.Get(r.Context(), chi.URLParam(r, "provider"))
+		if err != nil {
+			if errors.Is(err, oauth.ErrUnknownProvider) {
+				http.Error(w, "Unknown login provider", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Login provider unavailable", http.StatusBadGateway)
+			return
+		}
+
+		var secrets [3]string
+		for i := range secrets {
+			secrets[i], err = randomURLSafe(32)
+			if err != nil {
+				http.Error(w, "Couldn't start login", http.StatusInternalServerError)
+				return
+			}
+		}
+		state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     oauthStateCookie,
+			Value:    strings.Join(secrets[:], "."),
+			Expires:  time.Now().Add(10 * time.Minute),
+			HttpOnly: true,
+			Secure:   true,
+			// Lax so the cookie survives the top-level redirect back from the provider.
+			SameSite: http.SameSiteLaxMode,
+			Path:     "/api/auth/oauth",
+		})
+
+		http.Redirect(w, r, provider.AuthCodeURL(state, nonce, verifier), http.StatusFound)
+	})
+}
+
+func OAuthCallbackHandler(db *database.Queries) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		godotenv.Load(".env")
+
+		provider, err := oauth.Get(r.Context(), chi.URLParam(r, "provider"))
+		if err != nil {
+			finishOAuth(w, r, http.StatusNotFound, "Unknown login provider", nil)
+			return
+		}
+
+		stateCookie, err := r.Cookie(oauthStateCookie)
+		if err != nil {
+			finishOAuth(w, r, http.StatusBadRequest, "Login session expired, please try again", nil)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     oauthStateCookie,
+			Value:    "",
+			Expires:  time.Now().Add(-1 * time.Hour),
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteLaxMode,
+			Path:     "/api/auth/oauth",
+		})
+
+		secrets := strings.Split(stateCookie.Value, ".")
+		if len(secrets) != 3 || r.URL.Query().Get("state") != secrets[0] {
+			finishOAuth(w, r, http.StatusBadRequest, "Invalid login state", nil)
+			return
+		}
+		if providerErr := r.URL.Query().Get("error"); providerErr != "" {
+			finishOAuth(w, r, http.StatusUnauthorized, "Login was cancelled: "+providerErr, nil)
+			return
+		}
+
+		identity, err := provider.Exchange(r.Context(), r.URL.Query().Get("code"), secrets[1], secrets[2])
+		if err != nil {
+			finishOAuth(w, r, http.StatusUnauthorized, "Couldn't verify login with provider", nil)
+			return
+		}
+
+		userID, status, err := findOrCreateOAuthUser(db, r, provider.Name, identity)
+		if err != nil {
+			finishOAuth(w, r, status, err.Error(), nil)
+			return
+		}
+
+		user, err := db.GetUserById(r.Context(), userID)
+		if err != nil {
+			finishOAuth(w, r, http.StatusInternalServerError, "Couldn't get user", nil)
+			return
+		}
+
+		accessToken, refreshToken, err := issueSession(db, r, user.UserID, false)
+		if err != nil {
+			finishOAuth(w, r, http.StatusInternalServerError, "Couldn't start session", nil)
+			return
+		}
+
+		isContributor, err := db.CheckIfUserIsContributor(r.Context(), user.UserID)
+		if err != nil {
+			finishOAuth(w, r, http.StatusInternalServerError, "Couldn't check if user is contributor", nil)
+			return
+		}
+
+		returnedUser := ReturnedUser{
+			UserID:         user.UserID,
+			Name:           user.Name,
+			Email:          user.Email,
+			Username:       user.Username,
+			SuspendedUntil: user.SuspendedUntil.Time,
+			Role:           "user",
+		}
+		if isContributor {
+			returnedUser.Role = "contributor"
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     "access_token",
+			Value:    accessToken,
+			Expires:  time.Now().Add(2 * time.Hour),
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+			Path:     "/",
+		})
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     "refresh_token",
+			Value:    refreshToken,
+			Expires:  time.Now().Add(24 * time.Hour),
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+			Path:     "/",
+		})
+
+		finishOAuth(w, r, http.StatusOK, "", map[string]interface{}{
+			"user":          returnedUser,
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+		})
+	})
+}
+
+// findOrCreateOAuthUser resolves the local account for a provider identity:
+// an existing link first, then an existing user with the same verified email,
+// and finally a brand new user.
+func findOrCreateOAuthUser(db *database.Queries, r *http.Request, provider string, identity oauth.Identity) (uuid.UUID, int, error) {
+	linked, err := db.GetUserIdentity(r.Context(), database.GetUserIdentityParams{
+		Provider: provider,
+		Subject:  identity.Subject,
+	})
+	if err == nil {
+		db.TouchUserIdentity(r.Context(), linked.IdentityID)
+		return linked.UserID, http.StatusOK, nil
+	}
+	if err != sql.ErrNoRows {
+		return uuid.Nil, http.StatusInternalServerError, errors.New("couldn't look up linked account")
+	}
+
+	if identity.Email == "" {
+		return uuid.Nil, http.StatusUnprocessableEntity, errors.New("your account with this provider has no email address")
+	}
+
+	// The new user and its link to the provider are created together, so a
+	// failed link doesn't leave behind an account nobody can log in to.
+	var userID uuid.UUID
+	var status int
+	err = db.RunInTx(r.Context(), func(q *database.Queries) error {
+		existing, err := q.GetUserByEmail(r.Context(), identity.Email)
+		switch {
+		case err == nil:
+			// Only link to an existing account when the provider vouches for
+			// the email, otherwise anyone could claim someone else's account.
+			if !identity.EmailVerified {
+				status = http.StatusConflict
+				return errors.New("an account with this email already exists; verify your email with the provider or log in with your password")
+			}
+			userID = existing.UserID
+		case err == sql.ErrNoRows:
+			if !identity.EmailVerified {
+				status = http.StatusUnprocessableEntity
+				return errors.New("your email address with this provider is not verified")
+			}
+
+			name := identity.Name
+			if name == "" {
+				name, _, _ = strings.Cut(identity.Email, "@")
+			}
+			username, err := utils.GenerateUniqueUsername(name, q, r)
+			if err != nil {
+				status = http.StatusInternalServerError
+				return errors.New("couldn't generate unique username")
+			}
+
+			// Social accounts have no password; LoginHandler refuses an empty
+			// hash.
+			created, err := q.CreateUser(r.Context(), database.CreateUserParams{
+				UserID:   uuid.New(),
+				Name:     name,
+				Email:    identity.Email,
+				Password: "",
+				Username: username,
+			})
+			if err != nil {
+				status = http.StatusInternalServerError
+				return errors.New("couldn't create user")
+			}
+			userID = created.UserID
+		default:
+			status = http.StatusInternalServerError
+			return errors.New("couldn't look up user")
+		}
+
+		_, err = q.CreateUserIdentity(r.Context(), database.CreateUserIdentityParams{
+			IdentityID:    uuid.New(),
+			UserID:        userID,
+			Provider:      provider,
+			Subject:       identity.Subject,
+			Email:         sql.NullString{String: identity.Email, Valid: identity.Email != ""},
+			EmailVerified: identity.EmailVerified,
+		})
+		if err != nil {
+			status = http.StatusInternalServerError
+			return errors.New("couldn't link account")
+		}
+		return nil
+	})
+	if err != nil && status == 0 {
+		// The transaction itself failed to commit.
+		return uuid.Nil, http.StatusInternalServerError, errors.New("couldn't link account")
+	}
+	if err != nil {
+		return uuid.Nil, status, err
+	}
+
+	return userID, http.StatusOK, nil
+}
+
+// finishOAuth ends the callback. When FRONTEND_URL is set the browser is sent
+// back to the app (with oauth_error on failure); otherwise the result is
+// written as JSON, which is what API clients and tests against a mock
+// provider expect.
+func finishOAuth(w http.ResponseWriter, r *http.Request, status int, message string, response map[string]interface{}) {
+	if frontendURL := os.Getenv("FRONTEND_URL"); frontendURL != "" {
+		target := strings.TrimRight(frontendURL, "/") + "/"
+		if message != "" {
+			target += "?oauth_error=" + url.QueryEscape(message)
+		}
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
+
+	if message != "" {
+		http.Error(w, message, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// parsePagination reads ?limit= and ?offset= from the query string, applying
+// the default page size and capping it at maxPageLimit.
+func parsePagination(r *http.Request) (limit int32, offset int32, err error) {
+	limit, offset = defaultPageLimit, 0
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("limit must be a positive number")
+		}
+		limit = int32(min(n, maxPageLimit))
+	}
+
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("offset must be zero or a positive number")
+		}
+		offset = int32(n)
+	}
+
+	return limit, offset, nil
+}
@@ -6,16 +6,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
+	"github.com/MyoMyatMin/expertly-backend/badges"
 	"github.com/MyoMyatMin/expertly-backend/pkg/database"
 	"github.com/MyoMyatMin/expertly-backend/utils"
-	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
-	"github.com/joho/godotenv"
 )
 
 func GetAllPostsHandler(db *database.Queries) http.Handler {
@@ -37,6 +36,7 @@ func CreatePostHandler(db *database.Queries, contributor database.Contributor) h
 			Title   string   `json:"title"`
 			Content string   `json:"content"`
 			Images  []string `json:"images"`
+			Tags    []string `json:"tags"`
 		}
 
 		var params parameters
@@ -46,6 +46,12 @@ func CreatePostHandler(db *database.Queries, contributor database.Contributor) h
 			return
 		}
 
+		tags, err := utils.NormalizeTags(params.Tags)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest) // 400
+			return
+		}
+
 		deleteUnusedImages(params.Content, params.Images)
 
 		slug, err := utils.GenerateUniqueSlug(params.Title, db, r)
@@ -66,8 +72,26 @@ func CreatePostHandler(db *database.Queries, contributor database.Contributor) h
 			return
 		}
 
+		if err := db.AddPostTags(r.Context(), database.AddPostTagsParams{
+			PostID: post.PostID,
+			Tags:   tags,
+		}); err != nil {
+			http.Error(w, "Couldn't save tags", http.StatusInternalServerError) // 500
+			return
+		}
+
+		// Posts sharing a tag or the author now have a new candidate.
+		if err := db.InvalidateRelatedPosts(r.Context(), post.PostID); err != nil {
+			fmt.Printf("Failed to invalidate related posts for %s: %v\n", post.PostID, err)
+		}
+
+		awardBadges(r.Context(), db, contributor.UserID, badges.FirstPost)
+
 		w.WriteHeader(http.StatusCreated) // 201
-		json.NewEncoder(w).Encode(post)
+		json.NewEncoder(w).Encode(struct {
+			database.CreatePostRow
+			Tags []string
+		}{post, tags})
 	})
 }
 
@@ -107,15 +131,64 @@ func GetPostBySlugHandler(db *database.Queries, user database.User, moderator da
 			return
 		}
 
+		var moderation *ReturnedContentModeration
+		takedown, err := db.GetActivePostTakedown(r.Context(), uuid.NullUUID{UUID: postUUID, Valid: true})
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, "Couldn't get post", http.StatusInternalServerError) // 500
+			return
+		}
+		if err == nil {
+			moderation = toReturnedContentModeration(takedown, "post")
+			// Readers get a tombstone; moderators still see the post so it
+			// can be reviewed, and the author a hidden one to appeal it.
+			if hidesContent(takedown, user, moderator.ModeratorID != uuid.Nil, post.UserID) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusGone) // 410
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"post_id":    post.PostID,
+					"slug":       post.Slug,
+					"moderation": moderation,
+				})
+				return
+			}
+		}
+
+		tags, err := db.ListPostTags(r.Context(), postUUID)
+		if err != nil {
+			http.Error(w, "Couldn't get tags", http.StatusInternalServerError) // 500
+			return
+		}
+		if tags == nil {
+			tags = []string{}
+		}
+
+		// Verified fields are listed until their expiry, stored in UTC.
+		now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
 		var postDetails interface{}
 		if user != (database.User{}) {
-			postDetails, err = db.GetPostDetailsForUsersByID(r.Context(), database.GetPostDetailsForUsersByIDParams{
-				PostID: postUUID,
-				UserID: user.UserID,
+			var details database.GetPostDetailsForUsersByIDRow
+			details, err = db.GetPostDetailsForUsersByID(r.Context(), database.GetPostDetailsForUsersByIDParams{
+				PostID:    postUUID,
+				UserID:    user.UserID,
+				ExpiresAt: now,
 			})
+			postDetails = struct {
+				database.GetPostDetailsForUsersByIDRow
+				Tags       []string
+				Moderation *ReturnedContentModeration
+			}{details, tags, moderation}
 		} else {
 			fmt.Println("moderator")
-			postDetails, err = db.GetPostDetailsByID(r.Context(), postUUID)
+			var details database.GetPostDetailsByIDRow
+			details, err = db.GetPostDetailsByID(r.Context(), database.GetPostDetailsByIDParams{
+				PostID:    postUUID,
+				ExpiresAt: now,
+			})
+			postDetails = struct {
+				database.GetPostDetailsByIDRow
+				Tags       []string
+				Moderation *ReturnedContentModeration
+			}{details, tags, moderation}
 		}
 
 		if err != nil {
@@ -138,9 +211,10 @@ func UpdatePostHandler(db *database.Queries, contributor database.Contributor) h
 		}
 
 		type parameters struct {
-			Title   string   `json:"title"`
-			Content string   `json:"content"`
-			Images  []string `json:"images"`
+			Title   string    `json:"title"`
+			Content string    `json:"content"`
+			Images  []string  `json:"images"`
+			Tags    *[]string `json:"tags"`
 		}
 
 		var params parameters
@@ -150,6 +224,15 @@ func UpdatePostHandler(db *database.Queries, contributor database.Contributor) h
 			return
 		}
 
+		var tags []string
+		if params.Tags != nil {
+			tags, err = utils.NormalizeTags(*params.Tags)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest) // 400
+				return
+			}
+		}
+
 		deleteUnusedImages(params.Content, params.Images)
 
 		slug, err := utils.GenerateUniqueSlug(params.Title, db, r)
@@ -158,6 +241,12 @@ func UpdatePostHandler(db *database.Queries, contributor database.Contributor) h
 			return
 		}
 
+		// Invalidate while the old tags are still in place so posts that
+		// shared them drop this one on their next refresh.
+		if err := db.InvalidateRelatedPosts(r.Context(), postUUID); err != nil {
+			fmt.Printf("Failed to invalidate related posts for %s: %v\n", postUUID, err)
+		}
+
 		post, err := db.UpdatePost(r.Context(), database.UpdatePostParams{
 			PostID:  postUUID,
 			Title:   params.Title,
@@ -169,8 +258,37 @@ func UpdatePostHandler(db *database.Queries, contributor database.Contributor) h
 			return
 		}
 
+		if params.Tags != nil {
+			if err := db.DeletePostTags(r.Context(), post.PostID); err != nil {
+				http.Error(w, "Couldn't save tags", http.StatusInternalServerError) // 500
+				return
+			}
+			if err := db.AddPostTags(r.Context(), database.AddPostTagsParams{
+				PostID: post.PostID,
+				Tags:   tags,
+			}); err != nil {
+				http.Error(w, "Couldn't save tags", http.StatusInternalServerError) // 500
+				return
+			}
+			if err := db.InvalidateRelatedPosts(r.Context(), post.PostID); err != nil {
+				fmt.Printf("Failed to invalidate related posts for %s: %v\n", post.PostID, err)
+			}
+		} else {
+			tags, err = db.ListPostTags(r.Context(), post.PostID)
+			if err != nil {
+				http.Error(w, "Couldn't get tags", http.StatusInternalServerError) // 500
+				return
+			}
+			if tags == nil {
+				tags = []string{}
+			}
+		}
+
 		w.WriteHeader(http.StatusOK) // 200
-		json.NewEncoder(w).Encode(post)
+		json.NewEncoder(w).Encode(struct {
+			database.UpdatePostRow
+			Tags []string
+		}{post, tags})
 	})
 }
 
@@ -185,6 +303,12 @@ func DeletePostHandler(db *database.Queries, contributor database.Contributor) h
 			return
 		}
 
+		// The cascade removes this post from other posts' related lists, so
+		// mark those lists stale before it goes.
+		if err := db.InvalidateRelatedPosts(r.Context(), postUUID); err != nil {
+			fmt.Printf("Failed to invalidate related posts for %s: %v\n", postUUID, err)
+		}
+
 		err = db.DeletePost(r.Context(), postUUID)
 		if err != nil {
 			fmt.Println(err)
@@ -231,20 +355,16 @@ func extractPublicID(imgURL string) string {
 }
 
 func deleteImagesFromCloudinary(publicID string) error {
-	godotenv.Load(".env")
-	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
-	apiKey := os.Getenv("CLOUDINARY_API_KEY")
-	apiSecret := os.Getenv("CLOUDINARY_API_SECRET")
-	cld, err := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
+	cld, err := newCloudinary()
 	if err != nil {
-		return fmt.Errorf("failed to initialize Cloudinary: %v", err)
+		return err
 	}
 	_, err = cld.Upload.Destroy(context.Background(), uploader.DestroyParams{PublicID: publicID})
 
 	return err
 }
 
-func SearchPostsHandler(db *database.Queries) http.Handler {
+func SearchPostsHandler(db *database.Queries, user database.User) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		query := r.URL.Query().Get("q")
 		posts, err := db.PostSearchByKeyword(r.Context(), sql.NullString{String: query, Valid: query != ""})
@@ -253,6 +373,19 @@ func SearchPostsHandler(db *database.Queries) http.Handler {
 			return
 		}
 
+		hidden, err := hiddenUserSet(r.Context(), db, user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get muted users", http.StatusInternalServerError)
+			return
+		}
+		visible := posts[:0]
+		for _, post := range posts {
+			if !hidden[post.UserID] {
+				visible = append(visible, post)
+			}
+		}
+		posts = visible
+
 		w.WriteHeader(http.StatusOK) // 200
 		json.NewEncoder(w).Encode(posts)
 	})
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/google/uuid"
+)
+
+// Who can see a piece of profile data besides its owner and moderators.
+const (
+	VisibilityPublic    = "public"
+	VisibilityFollowers = "followers"
+	VisibilityPrivate   = "private"
+)
+
+type ReturnedPrivacySettings struct {
+	SavedPostsVisibility string    `json:"saved_posts_visibility"`
+	FollowsVisibility    string    `json:"follows_visibility"`
+	EmailVisibility      string    `json:"email_visibility"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+func isValidVisibility(visibility string) bool {
+	return visibility == VisibilityPublic || visibility == VisibilityFollowers || visibility == VisibilityPrivate
+}
+
+// getPrivacySettings returns the user's settings, falling back to the column
+// defaults for users who never changed them.
+func getPrivacySettings(ctx context.Context, db *database.Queries, userID uuid.UUID) (database.PrivacySetting, error) {
+	settings, err := db.GetPrivacySettings(ctx, userID)
+	if err == sql.ErrNoRows {
+		return database.PrivacySetting{
+			UserID:               userID,
+			SavedPostsVisibility: VisibilityPrivate,
+			FollowsVisibility:    VisibilityPublic,
+			EmailVisibility:      VisibilityPrivate,
+		}, nil
+	}
+	return settings, err
+}
+
+// canView reports whether the viewer (a user or a moderator, whichever is set)
+// may see data the owner shared with the given visibility.
+func canView(ctx context.Context, db *database.Queries, visibility string, ownerID uuid.UUID, viewer database.User, moderator database.Moderator) (bool, error) {
+	if moderator.ModeratorID != uuid.Nil || viewer.UserID == ownerID {
+		return true, nil
+	}
+
+	switch visibility {
+	case VisibilityPublic:
+		return true, nil
+	case VisibilityFollowers:
+		if viewer.UserID == uuid.Nil {
+			return false, nil
+		}
+		return db.GetFollowStatus(ctx, database.GetFollowStatusParams{
+			FollowerID:  viewer.UserID,
+			FollowingID: ownerID,
+		})
+	default:
+		return false, nil
+	}
+}
+
+func GetPrivacySettingsHandler(db *database.Queries, user database.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		settings, err := getPrivacySettings(r.Context(), db, user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get privacy settings", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ReturnedPrivacySettings{
+			SavedPostsVisibility: settings.SavedPostsVisibility,
+			FollowsVisibility:    settings.FollowsVisibility,
+			EmailVisibility:      settings.EmailVisibility,
+			UpdatedAt:            settings.UpdatedAt,
+		})
+	}
+}
+
+// UpdatePrivacySettingsHandler changes any of the visibility settings; fields
+// left out of the request keep their current value.
+func UpdatePrivacySettingsHandler(db *database.Queries, user database.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		type parameters struct {
+			SavedPostsVisibility string `json:"saved_posts_visibility"`
+			FollowsVisibility    string `json:"follows_visibility"`
+			EmailVisibility      string `json:"email_visibility"`
+		}
+		params := parameters{}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		settings, err := getPrivacySettings(r.Context(), db, user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get privacy settings", http.StatusInternalServerError)
+			return
+		}
+
+		for _, field := range []struct {
+			value string
+			dest  *string
+		}{
+			{params.SavedPostsVisibility, &settings.SavedPostsVisibility},
+			{params.FollowsVisibility, &settings.FollowsVisibility},
+			{params.EmailVisibility, &settings.EmailVisibility},
+		} {
+			if field.value == "" {
+				continue
+			}
+			if !isValidVisibility(field.value) {
+				http.Error(w, "Visibility must be public, followers or private", http.StatusBadRequest)
+				return
+			}
+			*field.dest = field.value
+		}
+
+		settings, err = db.UpsertPrivacySettings(r.Context(), database.UpsertPrivacySettingsParams{
+			UserID:               user.UserID,
+			SavedPostsVisibility: settings.SavedPostsVisibility,
+			FollowsVisibility:    settings.FollowsVisibility,
+			EmailVisibility:      settings.EmailVisibility,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't update privacy settings", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ReturnedPrivacySettings{
+			SavedPostsVisibility: settings.SavedPostsVisibility,
+			FollowsVisibility:    settings.FollowsVisibility,
+			EmailVisibility:      settings.EmailVisibility,
+			UpdatedAt:            settings.UpdatedAt,
+		})
+	}
+}
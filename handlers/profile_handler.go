@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+const maxAvatarSize = 2 << 20 // 2 MB
+
+var allowedAvatarTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// getUserProfile returns the user's profile fields, or an empty profile if
+// they've never filled it in.
+func getUserProfile(ctx context.Context, db *database.Queries, userID uuid.UUID) (database.UserProfile, error) {
+	profile, err := db.GetUserProfile(ctx, userID)
+	if err == sql.ErrNoRows {
+		return database.UserProfile{UserID: userID, SocialLinks: json.RawMessage("{}")}, nil
+	}
+	return profile, err
+}
+
+func decodeSocialLinks(raw json.RawMessage) map[string]string {
+	links := map[string]string{}
+	json.Unmarshal(raw, &links)
+	return links
+}
+
+func newCloudinary() (*cloudinary.Cloudinary, error) {
+	godotenv.Load(".env")
+	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
+	apiKey := os.Getenv("CLOUDINARY_API_KEY")
+	apiSecret := os.Getenv("CLOUDINARY_API_SECRET")
+	cld, err := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Cloudinary: %v", err)
+	}
+	return cld, nil
+}
+
+// avatarPublicID keeps one avatar per user; uploading again overwrites it.
+func avatarPublicID(userID uuid.UUID) string {
+	return "avatars/" + userID.String()
+}
+
+// UploadAvatarHandler accepts a multipart "avatar" image of up to 2 MB,
+// stores it on Cloudinary and saves its URL on the profile.
+func UploadAvatarHandler(db *database.Queries, user database.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxAvatarSize+1<<10)
+		if err := r.ParseMultipartForm(maxAvatarSize); err != nil {
+			http.Error(w, "Avatar must be an image of at most 2 MB", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		file, header, err := r.FormFile("avatar")
+		if err != nil {
+			http.Error(w, "Missing avatar file", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		if header.Size > maxAvatarSize {
+			http.Error(w, "Avatar must be an image of at most 2 MB", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		// Trust the bytes, not the client-supplied Content-Type.
+		sniff := make([]byte, 512)
+		n, _ := io.ReadFull(file, sniff)
+		if !allowedAvatarTypes[http.DetectContentType(sniff[:n])] {
+			http.Error(w, "Avatar must be a JPEG, PNG, GIF or WebP image", http.StatusUnsupportedMediaType)
+			return
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			http.Error(w, "Couldn't read avatar", http.StatusInternalServerError)
+			return
+		}
+
+		cld, err := newCloudinary()
+		if err != nil {
+			http.Error(w, "Couldn't upload avatar", http.StatusInternalServerError)
+			return
+		}
+
+		overwrite, invalidate := true, true
+		result, err := cld.Upload.Upload(r.Context(), file, uploader.UploadParams{
+			PublicID:   avatarPublicID(user.UserID),
+			Overwrite:  &overwrite,
+			Invalidate: &invalidate,
+		})
+		if err != nil || result.Error.Message != "" {
+			http.Error(w, "Couldn't upload avatar", http.StatusBadGateway)
+			return
+		}
+
+		if err := db.SetUserAvatar(r.Context(), database.SetUserAvatarParams{
+			UserID:    user.UserID,
+			AvatarUrl: result.SecureURL,
+		}); err != nil {
+			http.Error(w, "Couldn't save avatar", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"avatar_url": result.SecureURL})
+	}
+}
+
+func DeleteAvatarHandler(db *database.Queries, user database.User) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		profile, err := getUserProfile(r.Context(), db, user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get profile", http.StatusInternalServerError)
+			return
+		}
+		if profile.AvatarUrl == "" {
+			http.Error(w, "No avatar to remove", http.StatusNotFound)
+			return
+		}
+
+		if err := db.SetUserAvatar(r.Context(), database.SetUserAvatarParams{
+			UserID:    user.UserID,
+			AvatarUrl: "",
+		}); err != nil {
+			http.Error(w, "Couldn't remove avatar", http.StatusInternalServerError)
+			return
+		}
+
+		if cld, err := newCloudinary(); err == nil {
+			if _, err := cld.Upload.Destroy(r.Context(), uploader.DestroyParams{PublicID: avatarPublicID(user.UserID)}); err != nil {
+				fmt.Printf("Failed to delete avatar for %s: %v\n", user.UserID, err)
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -1,16 +1,33 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/utils"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 )
 
+// refreshTokenReuseGrace is how long a session's previous refresh token is
+// still accepted after rotation, so tabs that refresh at the same time don't
+// look like a replayed token and sign the user out.
+const refreshTokenReuseGrace = 30 * time.Second
+
+// justRotatedFrom reports whether usedHash is the refresh token session
+// rotated away from within the grace window.
+func justRotatedFrom(session database.Session, usedHash string) bool {
+	return session.PreviousRefreshTokenHash.Valid &&
+		session.PreviousRefreshTokenHash.String == usedHash &&
+		session.RotatedAt.Valid &&
+		time.Now().UTC().Sub(session.RotatedAt.Time) < refreshTokenReuseGrace
+}
+
 func RefreshTokenHandler(db *database.Queries) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		godotenv.Load(".env")
@@ -42,16 +59,80 @@ func RefreshTokenHandler(db *database.Queries) http.Handler {
 			return
 		}
 
-		accessToken, err := generateAccessToken(claims.UserID)
-		if err != nil {
-			http.Error(w, "Couldn't generate new access token", http.StatusInternalServerError)
+		if claims.ImpersonationID != uuid.Nil {
+			http.Error(w, "Impersonation tokens can't be refreshed", http.StatusUnauthorized)
 			return
 		}
 
-		refreshToken, err := generateRefreshToken(claims.UserID)
-		if err != nil {
-			http.Error(w, "Couldn't generate new refresh token", http.StatusInternalServerError)
-			return
+		// refreshToken stays empty when another request already rotated the
+		// session's refresh token; the refresh_token cookie it set is kept.
+		var accessToken, refreshToken string
+		usedHash := utils.HashToken(refreshCookie.Value)
+		if claims.SessionID == uuid.Nil {
+			// Refresh tokens issued before sessions were persisted get a
+			// session of their own on first use, and are refused after that.
+			rows, err := db.MarkRefreshTokenUpgraded(r.Context(), usedHash)
+			if err != nil {
+				http.Error(w, "Couldn't start session", http.StatusInternalServerError)
+				return
+			}
+			if rows == 0 {
+				http.Error(w, "Refresh token has already been used", http.StatusUnauthorized)
+				return
+			}
+
+			_, modErr := db.GetModeratorById(r.Context(), claims.UserID)
+			accessToken, refreshToken, err = issueSession(db, r, claims.UserID, modErr == nil)
+			if err != nil {
+				http.Error(w, "Couldn't start session", http.StatusInternalServerError)
+				return
+			}
+		} else {
+			session, err := db.GetSessionByID(r.Context(), claims.SessionID)
+			if err != nil || session.RevokedAt.Valid || time.Now().After(session.ExpiresAt) {
+				http.Error(w, "Session has been signed out", http.StatusUnauthorized)
+				return
+			}
+
+			rotate := session.RefreshTokenHash == usedHash
+			if !rotate && !justRotatedFrom(session, usedHash) {
+				// An old refresh token was replayed, so the session may have
+				// been stolen. End it and make the owner sign in again.
+				db.RevokeSession(r.Context(), session.SessionID)
+				http.Error(w, "Refresh token has already been used", http.StatusUnauthorized)
+				return
+			}
+
+			accessToken, err = generateAccessToken(claims.UserID, session.SessionID)
+			if err != nil {
+				http.Error(w, "Couldn't generate new access token", http.StatusInternalServerError)
+				return
+			}
+
+			if rotate {
+				refreshToken, err = generateRefreshToken(claims.UserID, session.SessionID)
+				if err != nil {
+					http.Error(w, "Couldn't generate new refresh token", http.StatusInternalServerError)
+					return
+				}
+
+				rows, err := db.RotateSessionRefreshToken(r.Context(), database.RotateSessionRefreshTokenParams{
+					RefreshTokenHash:     utils.HashToken(refreshToken),
+					RotatedAt:            sql.NullTime{Time: time.Now().UTC(), Valid: true},
+					ExpiresAt:            time.Now().UTC().Add(refreshTokenLifetime),
+					IpAddress:            utils.ClientIP(r),
+					SessionID:            session.SessionID,
+					UsedRefreshTokenHash: usedHash,
+				})
+				if err != nil {
+					http.Error(w, "Couldn't update session", http.StatusInternalServerError)
+					return
+				}
+				if rows == 0 {
+					// A concurrent refresh rotated it first.
+					refreshToken = ""
+				}
+			}
 		}
 
 		http.SetCookie(w, &http.Cookie{
@@ -64,15 +145,17 @@ func RefreshTokenHandler(db *database.Queries) http.Handler {
 			Path:     "/",
 		})
 
-		http.SetCookie(w, &http.Cookie{
-			Name:     "refresh_token",
-			Value:    refreshToken,
-			Expires:  time.Now().Add(24 * time.Hour),
-			HttpOnly: true,
-			Secure:   true,
-			SameSite: http.SameSiteNoneMode,
-			Path:     "/",
-		})
+		if refreshToken != "" {
+			http.SetCookie(w, &http.Cookie{
+				Name:     "refresh_token",
+				Value:    refreshToken,
+				Expires:  time.Now().Add(24 * time.Hour),
+				HttpOnly: true,
+				Secure:   true,
+				SameSite: http.SameSiteNoneMode,
+				Path:     "/",
+			})
+		}
 
 		response := map[string]interface{}{
 			"access_token": accessToken,
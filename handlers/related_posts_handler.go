@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// relatedPostsTTL bounds how stale a cached list can get from upvotes,
+	// which don't invalidate it.
+	relatedPostsTTL     = 24 * time.Hour
+	maxRelatedPosts     = 20
+	defaultRelatedPosts = 5
+)
+
+// Why a post was considered related.
+const (
+	RelatedReasonSharedTags  = "shared_tags"
+	RelatedReasonSameAuthor  = "same_author"
+	RelatedReasonCoUpvoted   = "co_upvoted"
+	RelatedReasonSimilarText = "similar_text"
+)
+
+type ReturnedRelatedPost struct {
+	PostID         uuid.UUID  `json:"post_id"`
+	Slug           string     `json:"slug"`
+	Title          string     `json:"title"`
+	AuthorName     string     `json:"author_name"`
+	AuthorUsername string     `json:"author_username"`
+	CreatedAt      *time.Time `json:"created_at"`
+	Reasons        []string   `json:"reasons"`
+}
+
+// refreshRelatedPosts recomputes the cached list for a post unless it's still
+// fresh. Posts invalidate the lists of their neighbours when they're created,
+// edited or deleted.
+func refreshRelatedPosts(ctx context.Context, db *database.Queries, postID uuid.UUID) error {
+	computedAt, err := db.GetRelatedPostsComputedAt(ctx, postID)
+	if err == nil && time.Since(computedAt) < relatedPostsTTL {
+		return nil
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err := db.ResetRelatedPostsCache(ctx, database.ResetRelatedPostsCacheParams{
+		PostID:     postID,
+		ComputedAt: time.Now().UTC(),
+	}); err != nil {
+		return err
+	}
+	if err := db.DeleteRelatedPosts(ctx, postID); err != nil {
+		return err
+	}
+	return db.InsertRelatedPosts(ctx, database.InsertRelatedPostsParams{
+		PostID:     postID,
+		MaxRelated: maxRelatedPosts,
+	})
+}
+
+func relatedPostReasons(p database.ListRelatedPostsRow) []string {
+	reasons := []string{}
+	if p.SharedTags > 0 {
+		reasons = append(reasons, RelatedReasonSharedTags)
+	}
+	if p.SameAuthor {
+		reasons = append(reasons, RelatedReasonSameAuthor)
+	}
+	if p.CoUpvotes > 0 {
+		reasons = append(reasons, RelatedReasonCoUpvoted)
+	}
+	if p.TextSimilarity > 0 {
+		reasons = append(reasons, RelatedReasonSimilarText)
+	}
+	return reasons
+}
+
+// GetRelatedPostsHandler lists posts similar to the one at {slug}: sharing
+// tags, by the same author, upvoted by the same readers or with similar text.
+func GetRelatedPostsHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultRelatedPosts
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+				return
+			}
+			limit = min(n, maxRelatedPosts)
+		}
+
+		post, err := db.GetPostBySlug(r.Context(), chi.URLParam(r, "slug"))
+		if err != nil {
+			http.Error(w, "Couldn't get post", http.StatusNotFound)
+			return
+		}
+
+		if err := refreshRelatedPosts(r.Context(), db, post.PostID); err != nil {
+			http.Error(w, "Couldn't compute related posts", http.StatusInternalServerError)
+			return
+		}
+
+		related, err := db.ListRelatedPosts(r.Context(), post.PostID)
+		if err != nil {
+			http.Error(w, "Couldn't get related posts", http.StatusInternalServerError)
+			return
+		}
+
+		hidden, err := hiddenUserSet(r.Context(), db, user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get muted users", http.StatusInternalServerError)
+			return
+		}
+
+		returned := []ReturnedRelatedPost{}
+		for _, p := range related {
+			if len(returned) == limit {
+				break
+			}
+			if hidden[p.UserID] {
+				continue
+			}
+			returned = append(returned, ReturnedRelatedPost{
+				PostID:         p.PostID,
+				Slug:           p.Slug,
+				Title:          p.Title,
+				AuthorName:     p.AuthorName,
+				AuthorUsername: p.AuthorUsername,
+				CreatedAt:      nullTimePtr(p.CreatedAt),
+				Reasons:        relatedPostReasons(p),
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returned)
+	})
+}
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Report case statuses.
+const (
+	CaseOpen      = "open"
+	CaseResolved  = "resolved"
+	CaseDismissed = "dismissed"
+)
+
+var reportTargetTypes = map[string]bool{
+	"post":    true,
+	"comment": true,
+	"user":    true,
+}
+
+type ReturnedReportCase struct {
+	CaseID              uuid.UUID  `json:"case_id"`
+	TargetType          string     `json:"target_type"`
+	TargetUserID        uuid.UUID  `json:"target_user_id"`
+	TargetName          string     `json:"target_name"`
+	TargetUsername      string     `json:"target_username"`
+	TargetIsContributor bool       `json:"target_is_contributor"`
+	TargetPostID        *uuid.UUID `json:"target_post_id"`
+	TargetPostSlug      string     `json:"target_post_slug,omitempty"`
+	TargetPostTitle     string     `json:"target_post_title,omitempty"`
+	TargetCommentID     *uuid.UUID `json:"target_comment_id"`
+	TargetComment       string     `json:"target_comment,omitempty"`
+	Status              string     `json:"status"`
+	ReportCount         int64      `json:"report_count"`
+	ReporterCount       int64      `json:"reporter_count"`
+	Reasons             []string   `json:"reasons"`
+	ReasonCodes         []string   `json:"reason_codes"`
+	// Severity is the highest severity among the case's report reasons.
+	Severity       int32      `json:"severity"`
+	SeverityName   string     `json:"severity_name"`
+	ResolvedBy     *uuid.UUID `json:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	SuspendDays    *int32     `json:"suspend_days"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastReportedAt time.Time  `json:"last_reported_at"`
+}
+
+// GetReportCasesHandler lists report cases, most severe and then most widely
+// reported first. ?status= is open (the default), resolved or dismissed;
+// ?type= narrows it to post, comment or user cases and ?reason= to cases with
+// a report filed under that reason.
+func GetReportCasesHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := r.URL.Query().Get("status")
+		if status == "" {
+			status = CaseOpen
+		}
+		if status != CaseOpen && status != CaseResolved && status != CaseDismissed {
+			http.Error(w, "status must be open, resolved or dismissed", http.StatusBadRequest)
+			return
+		}
+		targetType := r.URL.Query().Get("type")
+		if targetType != "" && !reportTargetTypes[targetType] {
+			http.Error(w, "type must be post, comment or user", http.StatusBadRequest)
+			return
+		}
+
+		limit, offset, err := parsePagination(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rows, err := db.ListReportCases(r.Context(), database.ListReportCasesParams{
+			Status:     status,
+			TargetType: targetType,
+			ReasonCode: r.URL.Query().Get("reason"),
+			PageLimit:  limit,
+			PageOffset: offset,
+		})
+		if err != nil {
+			fmt.Println(err)
+			http.Error(w, "Couldn't get report cases", http.StatusInternalServerError)
+			return
+		}
+
+		cases := make([]ReturnedReportCase, len(rows))
+		for i, row := range rows {
+			cases[i] = ReturnedReportCase{
+				CaseID:              row.CaseID,
+				TargetType:          row.TargetType,
+				TargetUserID:        row.TargetUserID,
+				TargetName:          row.TargetName,
+				TargetUsername:      row.TargetUsername,
+				TargetIsContributor: row.TargetIsContributor,
+				TargetPostID:        nullUUIDPtr(row.TargetPostID),
+				TargetPostSlug:      row.TargetPostSlug.String,
+				TargetPostTitle:     row.TargetPostTitle.String,
+				TargetCommentID:     nullUUIDPtr(row.TargetCommentID),
+				TargetComment:       row.TargetComment.String,
+				Status:              row.Status,
+				ReportCount:         row.ReportCount,
+				ReporterCount:       row.ReporterCount,
+				Reasons:             row.Reasons,
+				ReasonCodes:         row.ReasonCodes,
+				Severity:            row.Severity,
+				SeverityName:        severityNames[row.Severity],
+				ResolvedBy:          nullUUIDPtr(row.ResolvedBy),
+				ResolvedAt:          nullTimePtr(row.ResolvedAt),
+				SuspendDays:         nullInt32Ptr(row.SuspendDays),
+				CreatedAt:           row.CreatedAt,
+				LastReportedAt:      row.LastReportedAt,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"cases":  cases,
+			"limit":  limit,
+			"offset": offset,
+		})
+	})
+}
+
+func toReturnedReportCase(c database.ReportCase) ReturnedReportCase {
+	return ReturnedReportCase{
+		CaseID:          c.CaseID,
+		TargetType:      c.TargetType,
+		TargetUserID:    c.TargetUserID,
+		TargetPostID:    nullUUIDPtr(c.TargetPostID),
+		TargetCommentID: nullUUIDPtr(c.TargetCommentID),
+		Status:          c.Status,
+		Reasons:         []string{},
+		ReasonCodes:     []string{},
+		ResolvedBy:      nullUUIDPtr(c.ResolvedBy),
+		ResolvedAt:      nullTimePtr(c.ResolvedAt),
+		SuspendDays:     nullInt32Ptr(c.SuspendDays),
+		CreatedAt:       c.CreatedAt,
+		LastReportedAt:  c.LastReportedAt,
+	}
+}
+
+func nullInt32Ptr(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+// reportCaseFromURL loads the case named by {caseID}, writing the error
+// response itself.
+func reportCaseFromURL(w http.ResponseWriter, r *http.Request, db *database.Queries) (database.ReportCase, bool) {
+	caseID, err := uuid.Parse(chi.URLParam(r, "caseID"))
+	if err != nil {
+		http.Error(w, "Invalid case ID", http.StatusBadRequest)
+		return database.ReportCase{}, false
+	}
+
+	reportCase, err := db.GetReportCase(r.Context(), caseID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Case not found", http.StatusNotFound)
+		return database.ReportCase{}, false
+	}
+	if err != nil {
+		http.Error(w, "Couldn't get case", http.StatusInternalServerError)
+		return database.ReportCase{}, false
+	}
+	return reportCase, true
+}
+
+// GetReportCaseHandler returns a case with every report filed in it.
+func GetReportCaseHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reportCase, ok := reportCaseFromURL(w, r, db)
+		if !ok {
+			return
+		}
+
+		reports, err := db.ListReportsByCase(r.Context(), reportCase.CaseID)
+		if err != nil {
+			http.Error(w, "Couldn't get reports", http.StatusInternalServerError)
+			return
+		}
+
+		type returnedCaseReport struct {
+			ReportID           uuid.UUID  `json:"report_id"`
+			ReportedBy         uuid.UUID  `json:"reported_by"`
+			ReportedByName     string     `json:"reported_by_name"`
+			ReportedByUsername string     `json:"reported_by_username"`
+			Reason             string     `json:"reason"`
+			ReasonCode         string     `json:"reason_code"`
+			ReasonLabel        string     `json:"reason_label"`
+			Severity           int32      `json:"severity"`
+			Status             string     `json:"status"`
+			CreatedAt          *time.Time `json:"created_at"`
+		}
+		returnedCase := toReturnedReportCase(reportCase)
+		reporters := map[uuid.UUID]bool{}
+		reasons := map[string]bool{}
+		reasonCodes := map[string]bool{}
+		returned := make([]returnedCaseReport, len(reports))
+		for i, report := range reports {
+			reporters[report.ReportedBy] = true
+			if report.Reason != "" && !reasons[report.Reason] {
+				reasons[report.Reason] = true
+				returnedCase.Reasons = append(returnedCase.Reasons, report.Reason)
+			}
+			if !reasonCodes[report.ReasonCode] {
+				reasonCodes[report.ReasonCode] = true
+				returnedCase.ReasonCodes = append(returnedCase.ReasonCodes, report.ReasonCode)
+			}
+			returnedCase.Severity = max(returnedCase.Severity, report.Severity)
+			returned[i] = returnedCaseReport{
+				ReportID:           report.ReportID,
+				ReportedBy:         report.ReportedBy,
+				ReportedByName:     report.ReportedByName,
+				ReportedByUsername: report.ReportedByUsername,
+				Reason:             report.Reason,
+				ReasonCode:         report.ReasonCode,
+				ReasonLabel:        report.ReasonLabel,
+				Severity:           report.Severity,
+				Status:             report.Status.String,
+				CreatedAt:          nullTimePtr(report.CreatedAt),
+			}
+		}
+
+		returnedCase.ReportCount = int64(len(reports))
+		returnedCase.ReporterCount = int64(len(reporters))
+		returnedCase.SeverityName = severityNames[returnedCase.Severity]
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"case":    returnedCase,
+			"reports": returned,
+		})
+	})
+}
+
+// UpdateReportCaseStatusHandler resolves or dismisses a case, closing every
+// pending report in it. Resolving gives the reported user a strike and the
+// penalty the enforcement policy sets for it, unless suspendedDays picks a
+// suspension instead.
+func UpdateReportCaseStatusHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type parameters struct {
+			Status        string `json:"status"`
+			SuspendedDays int    `json:"suspendedDays"`
+			// Reason is kept in the moderation audit log.
+			Reason string `json:"reason"`
+			// ContentAction hides, removes or labels the reported post or
+			// comment; ContentLabel is the label shown with it.
+			ContentAction string `json:"content_action"`
+			ContentLabel  string `json:"content_label"`
+			// ExpectedStrikes confirms the enforcement preview: it's the
+			// preview's strikes_after.
+			ExpectedStrikes *int64 `json:"expected_strikes"`
+		}
+
+		var params parameters
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if !validStatuses[params.Status] {
+			http.Error(w, "status must be resolved or dismissed", http.StatusBadRequest)
+			return
+		}
+		if params.SuspendedDays < 0 || (params.SuspendedDays > 0 && params.Status != CaseResolved) {
+			http.Error(w, "suspendedDays only applies to resolved cases", http.StatusBadRequest)
+			return
+		}
+
+		reportCase, ok := reportCaseFromURL(w, r, db)
+		if !ok {
+			return
+		}
+		if !checkModerationClaim(w, r, db, moderator, QueueReport, reportCase.CaseID) {
+			return
+		}
+		if !validateTakedown(w, params.ContentAction, &params.ContentLabel, params.Status, reportCase.TargetType) {
+			return
+		}
+
+		var plan ReturnedEnforcementPreview
+		if params.Status == CaseResolved {
+			if plan, ok = planCaseEnforcement(w, r, db, reportCase, params.SuspendedDays, params.ExpectedStrikes); !ok {
+				return
+			}
+		}
+
+		now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		suspendDays := sql.NullInt32{Int32: plan.SuspendDays, Valid: plan.SuspendDays != 0}
+		reviewer := uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true}
+
+		// Closing the case, its strike and its penalty are saved together.
+		tx, q, err := db.Begin(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't update case", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		closed, err := q.CloseReportCase(r.Context(), database.CloseReportCaseParams{
+			CaseID:      reportCase.CaseID,
+			Status:      params.Status,
+			ResolvedBy:  reviewer,
+			ResolvedAt:  now,
+			SuspendDays: suspendDays,
+		})
+		if err == sql.ErrNoRows {
+			http.Error(w, "Case is already closed", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't update case", http.StatusInternalServerError)
+			return
+		}
+
+		closedReports, err := q.CloseCaseReports(r.Context(), database.CloseCaseReportsParams{
+			CaseID:      reportCase.CaseID,
+			Status:      sql.NullString{String: params.Status, Valid: true},
+			Reviewedby:  reviewer,
+			ReviewedAt:  now,
+			SuspendDays: suspendDays,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't close reports", http.StatusInternalServerError)
+			return
+		}
+
+		if err := recordModerationAction(r.Context(), q, moderator.ModeratorID, moderationAction{
+			Action:        ActionReportCaseClosed,
+			TargetType:    "report_case",
+			TargetID:      reportCase.CaseID,
+			SubjectUserID: uuid.NullUUID{UUID: reportCase.TargetUserID, Valid: true},
+			Before:        map[string]interface{}{"status": reportCase.Status},
+			After: map[string]interface{}{
+				"status":         closed.Status,
+				"penalty":        plan.Penalty,
+				"suspend_days":   plan.SuspendDays,
+				"closed_reports": closedReports,
+			},
+			Reason: params.Reason,
+		}); err != nil {
+			http.Error(w, "Couldn't record the decision", http.StatusInternalServerError)
+			return
+		}
+
+		var enforcement *ReturnedEnforcementPreview
+		var notices []notification
+		if params.Status == CaseResolved {
+			applied, enforced, err := enforceCase(r.Context(), q, moderator, reportCase, uuid.NullUUID{}, plan, params.Reason)
+			if err != nil {
+				http.Error(w, "Couldn't apply penalty", http.StatusInternalServerError)
+				return
+			}
+			enforcement = &applied
+			notices = append(notices, enforced...)
+		}
+		if params.ContentAction != "" {
+			notice, err := takeDownReportedContent(r.Context(), q, moderator, reportCase, params.ContentAction, params.ContentLabel, params.Reason)
+			if err != nil {
+				http.Error(w, "Couldn't take down content", http.StatusInternalServerError)
+				return
+			}
+			notices = append(notices, notice)
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "Couldn't update case", http.StatusInternalServerError)
+			return
+		}
+		sendNotifications(r.Context(), db, notices)
+		releaseDecidedItem(r.Context(), db, QueueReport, reportCase.CaseID)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"case":           toReturnedReportCase(closed),
+			"closed_reports": closedReports,
+			"enforcement":    enforcement,
+		})
+	})
+}
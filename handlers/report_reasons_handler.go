@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+)
+
+// ReportReasonOther is the catch-all reason; it needs a free-text detail.
+const ReportReasonOther = "other"
+
+// maxReportDetailLength caps the free-text detail on a report.
+const maxReportDetailLength = 1000
+
+// severityNames labels report_reasons.severity, lowest first.
+var severityNames = map[int32]string{
+	1: "low",
+	2: "medium",
+	3: "high",
+	4: "critical",
+}
+
+var reportReasonCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,39}$`)
+
+type ReturnedReportReason struct {
+	Code        string `json:"code"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Severity    int32  `json:"severity"`
+	// SeverityName is low, medium, high or critical.
+	SeverityName string `json:"severity_name"`
+	Active       bool   `json:"active"`
+	SortOrder    int32  `json:"sort_order"`
+}
+
+func toReturnedReportReason(reason database.ReportReason) ReturnedReportReason {
+	return ReturnedReportReason{
+		Code:         reason.Code,
+		Label:        reason.Label,
+		Description:  reason.Description,
+		Severity:     reason.Severity,
+		SeverityName: severityNames[reason.Severity],
+		Active:       reason.Active,
+		SortOrder:    reason.SortOrder,
+	}
+}
+
+// resolveReportReason checks the reason a report is filed under and its
+// detail, writing the error response itself. Older clients only send a
+// free-text reason, which is filed under "other".
+func resolveReportReason(w http.ResponseWriter, r *http.Request, db *database.Queries, code, detail string) (database.ReportReason, string, bool) {
+	code = strings.TrimSpace(code)
+	detail = strings.TrimSpace(detail)
+	if code == "" {
+		code = ReportReasonOther
+	}
+	if len(detail) > maxReportDetailLength {
+		http.Error(w, "Report details must be at most 1000 characters", http.StatusBadRequest)
+		return database.ReportReason{}, "", false
+	}
+
+	reason, err := db.GetReportReason(r.Context(), code)
+	if err == sql.ErrNoRows || (err == nil && !reason.Active) {
+		http.Error(w, "Unknown report reason "+code, http.StatusBadRequest)
+		return database.ReportReason{}, "", false
+	}
+	if err != nil {
+		http.Error(w, "Couldn't get report reason", http.StatusInternalServerError)
+		return database.ReportReason{}, "", false
+	}
+	if reason.Code == ReportReasonOther && detail == "" {
+		http.Error(w, "Describe the problem when reporting it as other", http.StatusBadRequest)
+		return database.ReportReason{}, "", false
+	}
+	return reason, detail, true
+}
+
+// GetReportReasonsHandler lists the reasons users can report content for.
+func GetReportReasonsHandler(db *database.Queries) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listReportReasons(w, r, db, false)
+	})
+}
+
+// GetAllReportReasonsHandler lists every reason, including inactive ones,
+// for moderators configuring the taxonomy.
+func GetAllReportReasonsHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listReportReasons(w, r, db, true)
+	})
+}
+
+func listReportReasons(w http.ResponseWriter, r *http.Request, db *database.Queries, includeInactive bool) {
+	reasons, err := db.ListReportReasons(r.Context(), includeInactive)
+	if err != nil {
+		http.Error(w, "Couldn't get report reasons", http.StatusInternalServerError)
+		return
+	}
+
+	returned := make([]ReturnedReportReason, len(reasons))
+	for i, reason := range reasons {
+		returned[i] = toReturnedReportReason(reason)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(returned)
+}
+
+type reportReasonParameters struct {
+	Code        string `json:"code"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Severity    int32  `json:"severity"`
+	Active      *bool  `json:"active"`
+	SortOrder   int32  `json:"sort_order"`
+}
+
+func validateReportReason(w http.ResponseWriter, params *reportReasonParameters) bool {
+	params.Label = strings.TrimSpace(params.Label)
+	params.Description = strings.TrimSpace(params.Description)
+	if params.Label == "" {
+		http.Error(w, "label is required", http.StatusBadRequest)
+		return false
+	}
+	if _, ok := severityNames[params.Severity]; !ok {
+		http.Error(w, "severity must be between 1 (low) and 4 (critical)", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// CreateReportReasonHandler adds a reason to the taxonomy.
+func CreateReportReasonHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params reportReasonParameters
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		params.Code = strings.ToLower(strings.TrimSpace(params.Code))
+		if !reportReasonCodePattern.MatchString(params.Code) {
+			http.Error(w, "code must be 2-40 lowercase letters, digits or underscores", http.StatusBadRequest)
+			return
+		}
+		if !validateReportReason(w, &params) {
+			return
+		}
+
+		reason, err := db.CreateReportReason(r.Context(), database.CreateReportReasonParams{
+			Code:        params.Code,
+			Label:       params.Label,
+			Description: params.Description,
+			Severity:    params.Severity,
+			SortOrder:   params.SortOrder,
+			CreatedAt:   time.Now().UTC(),
+		})
+		if err == sql.ErrNoRows {
+			http.Error(w, "A reason with that code already exists", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't create report reason", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(toReturnedReportReason(reason))
+	})
+}
+
+// UpdateReportReasonHandler changes a reason's label, severity or order, or
+// retires it with "active": false. Existing reports keep their reason.
+func UpdateReportReasonHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := chi.URLParam(r, "code")
+
+		var params reportReasonParameters
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if !validateReportReason(w, &params) {
+			return
+		}
+
+		existing, err := db.GetReportReason(r.Context(), code)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Report reason not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get report reason", http.StatusInternalServerError)
+			return
+		}
+
+		active := existing.Active
+		if params.Active != nil {
+			active = *params.Active
+		}
+		if code == ReportReasonOther && !active {
+			http.Error(w, "The other reason can't be retired", http.StatusBadRequest)
+			return
+		}
+
+		reason, err := db.UpdateReportReason(r.Context(), database.UpdateReportReasonParams{
+			Code:        code,
+			Label:       params.Label,
+			Description: params.Description,
+			Severity:    params.Severity,
+			Active:      active,
+			SortOrder:   params.SortOrder,
+			UpdatedAt:   time.Now().UTC(),
+		})
+		if err != nil {
+			http.Error(w, "Couldn't update report reason", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(toReturnedReportReason(reason))
+	})
+}
+
+// GetReportReasonStatsHandler counts reports per reason filed within
+// ?timeframe= (week, month, year or all) and how they were decided.
+func GetReportReasonStatsHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeframe := r.URL.Query().Get("timeframe")
+		if timeframe == "" {
+			timeframe = "all"
+		}
+		window, ok := leaderboardTimeframes[timeframe]
+		if !ok {
+			http.Error(w, "timeframe must be week, month, year or all", http.StatusBadRequest)
+			return
+		}
+		// Reports from before created_at was always set count towards "all".
+		since := sql.NullTime{}
+		if window > 0 {
+			since = sql.NullTime{Time: time.Now().UTC().Add(-window), Valid: true}
+		}
+
+		stats, err := db.GetReportReasonStats(r.Context(), since)
+		if err != nil {
+			http.Error(w, "Couldn't get report stats", http.StatusInternalServerError)
+			return
+		}
+
+		type returnedStat struct {
+			Code           string  `json:"code"`
+			Label          string  `json:"label"`
+			Severity       int32   `json:"severity"`
+			SeverityName   string  `json:"severity_name"`
+			ReportCount    int64   `json:"report_count"`
+			PendingCount   int64   `json:"pending_count"`
+			ResolvedCount  int64   `json:"resolved_count"`
+			DismissedCount int64   `json:"dismissed_count"`
+			CaseCount      int64   `json:"case_count"`
+			ReporterCount  int64   `json:"reporter_count"`
+			UpheldRate     float64 `json:"upheld_rate"`
+		}
+		returned := make([]returnedStat, len(stats))
+		for i, s := range stats {
+			returned[i] = returnedStat{
+				Code:           s.Code,
+				Label:          s.Label,
+				Severity:       s.Severity,
+				SeverityName:   severityNames[s.Severity],
+				ReportCount:    s.ReportCount,
+				PendingCount:   s.PendingCount,
+				ResolvedCount:  s.ResolvedCount,
+				DismissedCount: s.DismissedCount,
+				CaseCount:      s.CaseCount,
+				ReporterCount:  s.ReporterCount,
+			}
+			if decided := s.ResolvedCount + s.DismissedCount; decided > 0 {
+				returned[i].UpheldRate = float64(s.ResolvedCount) / float64(decided)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"timeframe": timeframe,
+			"reasons":   returned,
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -12,12 +13,26 @@ import (
 	"github.com/google/uuid"
 )
 
+// reportTarget works out what a report is about. Comment reports also keep
+// the comment's post, as they always have.
+type reportTarget struct {
+	key       string
+	kind      string
+	userID    uuid.UUID
+	postID    uuid.NullUUID
+	commentID uuid.NullUUID
+}
+
 func CreateReportHandler(db *database.Queries, user database.User) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		type parameters struct {
+			// ReasonCode is one of the report reasons; Reason is the
+			// optional free-text detail.
+			ReasonCode      string        `json:"reason_code"`
 			Reason          string        `json:"reason"`
 			TargetPostID    uuid.NullUUID `json:"target_postID"`
 			TargetCommentID uuid.NullUUID `json:"target_CommentID"`
+			TargetUserID    uuid.NullUUID `json:"target_userID"`
 		}
 
 		var params parameters
@@ -28,8 +43,12 @@ func CreateReportHandler(db *database.Queries, user database.User) http.Handler
 			return
 		}
 
-		var targetUserID uuid.UUID
-		var targetPostID uuid.NullUUID
+		reason, detail, ok := resolveReportReason(w, r, db, params.ReasonCode, params.Reason)
+		if !ok {
+			return
+		}
+
+		var target reportTarget
 		if params.TargetCommentID.Valid {
 			comment, err := db.GetCommentByID(r.Context(), params.TargetCommentID.UUID)
 			if err != nil {
@@ -37,30 +56,69 @@ func CreateReportHandler(db *database.Queries, user database.User) http.Handler
 				return
 			}
 
-			targetUserID = comment.UserID
-			targetPostID = uuid.NullUUID{UUID: comment.PostID, Valid: true}
+			target = reportTarget{
+				key:       "comment:" + comment.CommentID.String(),
+				kind:      "comment",
+				userID:    comment.UserID,
+				postID:    uuid.NullUUID{UUID: comment.PostID, Valid: true},
+				commentID: params.TargetCommentID,
+			}
 		} else if params.TargetPostID.Valid {
 			post, err := db.GetPost(r.Context(), params.TargetPostID.UUID)
 			if err != nil {
 				http.Error(w, "Post not found", http.StatusNotFound)
 				return
 			}
-			targetUserID = post.UserID
-			targetPostID = params.TargetPostID
+			target = reportTarget{
+				key:    "post:" + post.PostID.String(),
+				kind:   "post",
+				userID: post.UserID,
+				postID: params.TargetPostID,
+			}
+		} else if params.TargetUserID.Valid {
+			targetUser, err := db.GetUserById(r.Context(), params.TargetUserID.UUID)
+			if err != nil {
+				http.Error(w, "User not found", http.StatusNotFound)
+				return
+			}
+			target = reportTarget{
+				key:    "user:" + targetUser.UserID.String(),
+				kind:   "user",
+				userID: targetUser.UserID,
+			}
 		} else {
 			http.Error(w, "Invalid report target", http.StatusBadRequest)
 			return
 		}
 
+		reportCase, err := db.OpenReportCase(r.Context(), database.OpenReportCaseParams{
+			CaseID:          uuid.New(),
+			TargetKey:       target.key,
+			TargetType:      target.kind,
+			TargetUserID:    target.userID,
+			TargetPostID:    target.postID,
+			TargetCommentID: target.commentID,
+			CreatedAt:       time.Now().UTC(),
+		})
+		if err != nil {
+			http.Error(w, "Couldn't create report", http.StatusInternalServerError)
+			return
+		}
+
 		_, err = db.CreateReport(r.Context(), database.CreateReportParams{
 			ReportID:        uuid.New(),
 			ReportedBy:      user.UserID,
-			TargetPostID:    targetPostID,
-			TargetCommentID: params.TargetCommentID,
-			TargetUserID:    targetUserID,
-			Reason:          params.Reason,
+			TargetPostID:    target.postID,
+			TargetCommentID: target.commentID,
+			TargetUserID:    target.userID,
+			Reason:          detail,
+			ReasonCode:      reason.Code,
+			CaseID:          reportCase.CaseID,
 		})
-
+		if err == sql.ErrNoRows {
+			http.Error(w, "You've already reported this", http.StatusConflict)
+			return
+		}
 		if err != nil {
 			http.Error(w, "Couldn't create report", http.StatusInternalServerError)
 			return
@@ -73,9 +131,17 @@ func CreateReportHandler(db *database.Queries, user database.User) http.Handler
 func UpdateReportStatusHandler(db *database.Queries, moderator database.Moderator) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		type parameters struct {
-			Status        string    `json:"status"`
-			SuspendedDays int       `json:"suspendedDays"`
-			TargetUserID  uuid.UUID `json:"targetUserID"`
+			Status        string `json:"status"`
+			SuspendedDays int    `json:"suspendedDays"`
+			// Reason is kept in the moderation audit log.
+			Reason string `json:"reason"`
+			// ContentAction hides, removes or labels the reported post or
+			// comment; ContentLabel is the label shown with it.
+			ContentAction string `json:"content_action"`
+			ContentLabel  string `json:"content_label"`
+			// ExpectedStrikes confirms the enforcement preview of the
+			// report's case.
+			ExpectedStrikes *int64 `json:"expected_strikes"`
 		}
 
 		reportIDStr := chi.URLParam(r, "reportID")
@@ -96,11 +162,59 @@ func UpdateReportStatusHandler(db *database.Queries, moderator database.Moderato
 			return
 		}
 
+		existing, err := db.GetReportById(r.Context(), reportID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Report not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get report", http.StatusInternalServerError)
+			return
+		}
+		if !checkModerationClaim(w, r, db, moderator, QueueReport, existing.CaseID) {
+			return
+		}
+		reportCase, err := db.GetReportCase(r.Context(), existing.CaseID)
+		if err != nil {
+			http.Error(w, "Couldn't get report case", http.StatusInternalServerError)
+			return
+		}
+		if !validateTakedown(w, params.ContentAction, &params.ContentLabel, params.Status, reportCase.TargetType) {
+			return
+		}
+
+		if params.SuspendedDays < 0 || (params.SuspendedDays > 0 && params.Status != CaseResolved) {
+			http.Error(w, "suspendedDays only applies to resolved reports", http.StatusBadRequest)
+			return
+		}
+		var plan ReturnedEnforcementPreview
+		if params.Status == CaseResolved {
+			var ok bool
+			if plan, ok = planCaseEnforcement(w, r, db, reportCase, params.SuspendedDays, params.ExpectedStrikes); !ok {
+				return
+			}
+		}
+
+		// The report's decision, its case's strike and the penalty are saved
+		// together.
+		tx, q, err := db.Begin(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't update report status", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// The claim is checked again now the decision is under way, in case
+		// someone else claimed the case since.
+		if !checkModerationClaim(w, r, q, moderator, QueueReport, existing.CaseID) {
+			return
+		}
+
 		status := sql.NullString{String: params.Status, Valid: params.Status != ""}
-		_, err = db.UpdateReportStatus(r.Context(), database.UpdateReportStatusParams{
+		report, err := q.UpdateReportStatus(r.Context(), database.UpdateReportStatusParams{
 			ReportID:    reportID,
 			Status:      status,
-			SuspendDays: sql.NullInt32{Int32: int32(params.SuspendedDays), Valid: params.SuspendedDays != 0},
+			SuspendDays: sql.NullInt32{Int32: plan.SuspendDays, Valid: plan.SuspendDays != 0},
 			Reviewedby:  uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
 		})
 		if err != nil {
@@ -109,29 +223,59 @@ func UpdateReportStatusHandler(db *database.Queries, moderator database.Moderato
 			return
 		}
 
-		if params.Status == "resolved" {
-			targetUser, err := db.GetUserById(r.Context(), params.TargetUserID)
+		// Close the report's case once none of its reports are pending.
+		settled, err := q.SettleReportCase(r.Context(), database.SettleReportCaseParams{
+			CaseID:     report.CaseID,
+			ResolvedBy: uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+			ResolvedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		})
+		if err != nil {
+			http.Error(w, "Couldn't update report case", http.StatusInternalServerError)
+			return
+		}
+
+		if err := recordModerationAction(r.Context(), q, moderator.ModeratorID, moderationAction{
+			Action:        ActionReportStatusChanged,
+			TargetType:    "report",
+			TargetID:      reportID,
+			SubjectUserID: uuid.NullUUID{UUID: report.TargetUserID, Valid: true},
+			Before:        map[string]interface{}{"status": existing.ReportStatus.String},
+			After:         map[string]interface{}{"status": params.Status, "penalty": plan.Penalty, "suspend_days": plan.SuspendDays},
+			Reason:        params.Reason,
+		}); err != nil {
+			http.Error(w, "Couldn't record the decision", http.StatusInternalServerError)
+			return
+		}
+
+		// The first resolved report of a case gives its strike; later ones
+		// only suspend when suspendedDays asks for it.
+		var notices []notification
+		if params.Status == CaseResolved {
+			_, enforced, err := enforceCase(r.Context(), q, moderator, reportCase, uuid.NullUUID{UUID: reportID, Valid: true}, plan, params.Reason)
 			if err != nil {
-				fmt.Println("hello", err)
-				http.Error(w, "Couldn't get user", http.StatusInternalServerError)
+				fmt.Println("Hi there", err)
+				http.Error(w, "Couldn't apply penalty", http.StatusInternalServerError)
 				return
 			}
+			notices = append(notices, enforced...)
+		}
 
-			suspendedUntil := time.Now().Truncate(24*time.Hour).Add(-7*time.Hour).AddDate(0, 0, params.SuspendedDays)
-			if targetUser.SuspendedUntil.Valid {
-				suspendedUntil = targetUser.SuspendedUntil.Time.Truncate(24*time.Hour).AddDate(0, 0, params.SuspendedDays)
-			}
-
-			err = db.UpdateUserSuspension(r.Context(), database.UpdateUserSuspensionParams{
-				UserID:         params.TargetUserID,
-				SuspendedUntil: sql.NullTime{Time: suspendedUntil, Valid: true},
-			})
-
+		if params.ContentAction != "" {
+			notice, err := takeDownReportedContent(r.Context(), q, moderator, reportCase, params.ContentAction, params.ContentLabel, params.Reason)
 			if err != nil {
-				fmt.Println("Hi there", err)
-				http.Error(w, "Couldn't update user suspension", http.StatusInternalServerError)
+				http.Error(w, "Couldn't take down content", http.StatusInternalServerError)
 				return
 			}
+			notices = append(notices, notice)
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "Couldn't update report status", http.StatusInternalServerError)
+			return
+		}
+		sendNotifications(r.Context(), db, notices)
+		if settled > 0 {
+			// The case has left the queue along with its last pending report.
+			releaseDecidedItem(r.Context(), db, QueueReport, report.CaseID)
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -140,6 +284,35 @@ func UpdateReportStatusHandler(db *database.Queries, moderator database.Moderato
 	})
 }
 
+// suspendReportedUser suspends the user for days over a case, or over one of
+// its reports when reportID is set, and records it in the audit log.
+func suspendReportedUser(ctx context.Context, db *database.Queries, moderatorID, userID uuid.UUID, caseID, reportID uuid.NullUUID, days int, reason string) error {
+	if days <= 0 {
+		return nil
+	}
+	return db.RunInTx(ctx, func(q *database.Queries) error {
+		suspension, before, after, err := suspendUser(ctx, q, moderatorID, userID, caseID, reportID, days, reason)
+		if err != nil {
+			return err
+		}
+
+		return recordModerationAction(ctx, q, moderatorID, moderationAction{
+			Action:        ActionSuspensionApplied,
+			TargetType:    "user",
+			TargetID:      userID,
+			SubjectUserID: uuid.NullUUID{UUID: userID, Valid: true},
+			Before:        map[string]interface{}{"suspended_until": nullTimePtr(before)},
+			After: map[string]interface{}{
+				"suspended_until": nullTimePtr(after),
+				"suspend_days":    days,
+				"suspension_id":   suspension.SuspensionID,
+				"ends_at":         suspension.EndsAt.Time,
+			},
+			Reason: reason,
+		})
+	})
+}
+
 func GetReportedContributorsHandler(db *database.Queries, moderator database.Moderator) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Println("GetReportedContributorsHandler")
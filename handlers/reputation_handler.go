@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Leaderboard timeframes, counted back from now.
+var leaderboardTimeframes = map[string]time.Duration{
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+	"year":  365 * 24 * time.Hour,
+	"all":   0,
+}
+
+type ReturnedReputationEntry struct {
+	Reason     string     `json:"reason"`
+	Points     int32      `json:"points"`
+	PostID     *uuid.UUID `json:"post_id,omitempty"`
+	PostSlug   string     `json:"post_slug,omitempty"`
+	PostTitle  string     `json:"post_title,omitempty"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+type ReturnedReputationReason struct {
+	Reason string `json:"reason"`
+	Events int64  `json:"events"`
+	Points int64  `json:"points"`
+}
+
+type ReturnedLeaderboardEntry struct {
+	Rank     int32     `json:"rank"`
+	UserID   uuid.UUID `json:"user_id"`
+	Name     string    `json:"name"`
+	Username string    `json:"username"`
+	Score    int64     `json:"score"`
+}
+
+// GetUserReputationHandler returns a user's reputation score, how it breaks
+// down by reason, and the ledger entries behind it a page at a time.
+func GetUserReputationHandler(db *database.Queries) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := parsePagination(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		userID, err := db.GetIDbyUsername(r.Context(), chi.URLParam(r, "username"))
+		if err != nil {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+
+		score, err := db.GetReputationScore(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Couldn't get reputation", http.StatusInternalServerError)
+			return
+		}
+
+		breakdown, err := db.GetReputationBreakdown(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Couldn't get reputation", http.StatusInternalServerError)
+			return
+		}
+		returnedBreakdown := make([]ReturnedReputationReason, len(breakdown))
+		for i, b := range breakdown {
+			returnedBreakdown[i] = ReturnedReputationReason{
+				Reason: b.Reason,
+				Events: b.Events,
+				Points: b.Points,
+			}
+		}
+
+		entries, err := db.ListReputationEntries(r.Context(), database.ListReputationEntriesParams{
+			UserID:     userID,
+			PageLimit:  limit,
+			PageOffset: offset,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't get reputation history", http.StatusInternalServerError)
+			return
+		}
+		returnedEntries := make([]ReturnedReputationEntry, len(entries))
+		for i, e := range entries {
+			returnedEntries[i] = ReturnedReputationEntry{
+				Reason:     e.Reason,
+				Points:     e.Points,
+				PostID:     nullUUIDPtr(e.PostID),
+				PostSlug:   e.PostSlug.String,
+				PostTitle:  e.PostTitle.String,
+				OccurredAt: e.OccurredAt,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"score":     score,
+			"breakdown": returnedBreakdown,
+			"history":   returnedEntries,
+			"limit":     limit,
+			"offset":    offset,
+		})
+	})
+}
+
+// GetLeaderboardHandler ranks users by the reputation they earned within
+// ?timeframe= (week, month, year or all), optionally only contributors with
+// the given ?field= of expertise.
+func GetLeaderboardHandler(db *database.Queries) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := parsePagination(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		timeframe := r.URL.Query().Get("timeframe")
+		if timeframe == "" {
+			timeframe = "all"
+		}
+		window, ok := leaderboardTimeframes[timeframe]
+		if !ok {
+			http.Error(w, "timeframe must be week, month, year or all", http.StatusBadRequest)
+			return
+		}
+		var since time.Time
+		if window > 0 {
+			since = time.Now().UTC().Add(-window)
+		}
+
+		field := strings.TrimSpace(r.URL.Query().Get("field"))
+
+		leaders, err := db.GetLeaderboard(r.Context(), database.GetLeaderboardParams{
+			Since:          since,
+			ExpertiseField: field,
+			DeletedUserID:  utils.DeletedUserID,
+			PageLimit:      limit,
+			PageOffset:     offset,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't get leaderboard", http.StatusInternalServerError)
+			return
+		}
+
+		returned := make([]ReturnedLeaderboardEntry, len(leaders))
+		for i, l := range leaders {
+			returned[i] = ReturnedLeaderboardEntry{
+				Rank:     offset + int32(i) + 1,
+				UserID:   l.UserID,
+				Name:     l.Name,
+				Username: l.Username,
+				Score:    l.Score,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"timeframe": timeframe,
+			"field":     field,
+			"leaders":   returned,
+			"limit":     limit,
+			"offset":    offset,
+		})
+	})
+}
@@ -64,7 +64,7 @@ func DeleteSavedPost(db *database.Queries, user database.User) http.Handler {
 	})
 }
 
-func GetSavedPosts(db *database.Queries) http.Handler {
+func GetSavedPosts(db *database.Queries, user database.User, moderator database.Moderator) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		username := chi.URLParam(r, "username")
 
@@ -74,6 +74,21 @@ func GetSavedPosts(db *database.Queries) http.Handler {
 			return
 		}
 
+		settings, err := getPrivacySettings(r.Context(), db, userID)
+		if err != nil {
+			http.Error(w, "Couldn't get privacy settings", http.StatusInternalServerError)
+			return
+		}
+		allowed, err := canView(r.Context(), db, settings.SavedPostsVisibility, userID, user, moderator)
+		if err != nil {
+			http.Error(w, "Couldn't check privacy settings", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, "This user's saved posts are private", http.StatusForbidden)
+			return
+		}
+
 		savedPosts, err := db.ListSavedPostsByID(r.Context(), userID)
 		if err != nil {
 			http.Error(w, "Couldn't get saved posts", http.StatusInternalServerError)
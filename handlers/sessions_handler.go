@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/middlewares"
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+const refreshTokenLifetime = 24 * time.Hour
+
+type ReturnedSession struct {
+	SessionID    uuid.UUID `json:"session_id"`
+	Device       string    `json:"device"`
+	UserAgent    string    `json:"user_agent"`
+	IPAddress    string    `json:"ip_address"`
+	Location     string    `json:"location"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Current      bool      `json:"current"`
+}
+
+// issueSession records a new login session for a user or moderator and mints
+// the access/refresh token pair bound to it. Users are notified the first time
+// a device they haven't used before signs in.
+func issueSession(db *database.Queries, r *http.Request, ownerID uuid.UUID, isModerator bool) (string, string, error) {
+	sessionID := uuid.New()
+
+	accessToken, err := generateAccessToken(ownerID, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err := generateRefreshToken(ownerID, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	userAgent := r.UserAgent()
+	device := utils.DescribeDevice(userAgent)
+	location := utils.LocationHint(r)
+
+	var userID, moderatorID uuid.NullUUID
+	if isModerator {
+		moderatorID = uuid.NullUUID{UUID: ownerID, Valid: true}
+	} else {
+		userID = uuid.NullUUID{UUID: ownerID, Valid: true}
+	}
+
+	var history database.GetSessionDeviceHistoryRow
+	if !isModerator {
+		history, err = db.GetSessionDeviceHistory(r.Context(), database.GetSessionDeviceHistoryParams{
+			UserID: userID,
+			Device: device,
+		})
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	_, err = db.CreateSession(r.Context(), database.CreateSessionParams{
+		SessionID:        sessionID,
+		UserID:           userID,
+		ModeratorID:      moderatorID,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		UserAgent:        userAgent,
+		Device:           device,
+		IpAddress:        utils.ClientIP(r),
+		Location:         sql.NullString{String: location, Valid: location != ""},
+		ExpiresAt:        time.Now().UTC().Add(refreshTokenLifetime),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	if history.HasAnySession && !history.HasDeviceSession {
+		message := fmt.Sprintf("New sign-in from %s", device)
+		if location != "" {
+			message += " near " + location
+		}
+		err = notifyUser(r.Context(), db, ownerID, "new_device_sign_in", message, map[string]interface{}{
+			"session_id": sessionID,
+			"device":     device,
+			"ip_address": utils.ClientIP(r),
+			"location":   location,
+		})
+		if err != nil {
+			fmt.Println("Couldn't send new device notification:", err)
+		}
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// sessionIDFromRefreshCookie reads the session a refresh token belongs to
+// without caring whether the token is still valid, so logging out always
+// works.
+func sessionIDFromRefreshCookie(r *http.Request) uuid.UUID {
+	refreshCookie, err := r.Cookie("refresh_token")
+	if err != nil || refreshCookie.Value == "" {
+		return uuid.Nil
+	}
+
+	godotenv.Load(".env")
+	claims := &JWTClaims{}
+	_, err = jwt.ParseWithClaims(refreshCookie.Value, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("SECRET_KEY")), nil
+	}, jwt.WithoutClaimsValidation())
+	if err != nil {
+		return uuid.Nil
+	}
+	return claims.SessionID
+}
+
+func GetSessionsHandler(db *database.Queries, user database.User, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ownerID, _, _ := accountOwner(user, moderator)
+		currentSessionID := middlewares.SessionIDFromContext(r.Context())
+
+		sessions, err := db.ListActiveSessionsByOwner(r.Context(), ownerID)
+		if err != nil {
+			http.Error(w, "Couldn't get sessions", http.StatusInternalServerError)
+			return
+		}
+
+		returnedSessions := make([]ReturnedSession, len(sessions))
+		for i, session := range sessions {
+			returnedSessions[i] = ReturnedSession{
+				SessionID:    session.SessionID,
+				Device:       session.Device,
+				UserAgent:    session.UserAgent,
+				IPAddress:    session.IpAddress,
+				Location:     session.Location.String,
+				CreatedAt:    session.CreatedAt,
+				LastActiveAt: session.LastActiveAt,
+				ExpiresAt:    session.ExpiresAt,
+				Current:      session.SessionID == currentSessionID,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returnedSessions)
+	})
+}
+
+func RevokeSessionHandler(db *database.Queries, user database.User, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid session ID", http.StatusBadRequest)
+			return
+		}
+
+		ownerID, _, _ := accountOwner(user, moderator)
+
+		revoked, err := db.RevokeOwnedSession(r.Context(), database.RevokeOwnedSessionParams{
+			SessionID: sessionID,
+			OwnerID:   ownerID,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't revoke session", http.StatusInternalServerError)
+			return
+		}
+		if revoked == 0 {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// RevokeOtherSessionsHandler signs out every session except the one making the
+// request ("log out everywhere else").
+func RevokeOtherSessionsHandler(db *database.Queries, user database.User, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		currentSessionID := middlewares.SessionIDFromContext(r.Context())
+		if currentSessionID == uuid.Nil {
+			http.Error(w, "Current session unknown, please log in again", http.StatusBadRequest)
+			return
+		}
+
+		ownerID, _, _ := accountOwner(user, moderator)
+
+		revoked, err := db.RevokeOtherSessions(r.Context(), database.RevokeOtherSessionsParams{
+			OwnerID:          ownerID,
+			CurrentSessionID: currentSessionID,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't revoke sessions", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"revoked": revoked})
+	})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/google/uuid"
+)
+
+// Why a contributor was suggested, most specific first.
+const (
+	SuggestionReasonFollowedByFollowing = "followed_by_people_you_follow"
+	SuggestionReasonSharedExpertise     = "matches_your_interests"
+	SuggestionReasonPopular             = "popular"
+)
+
+type ReturnedFollowSuggestion struct {
+	UserID          uuid.UUID `json:"user_id"`
+	Name            string    `json:"name"`
+	Username        string    `json:"username"`
+	Headline        string    `json:"headline"`
+	AvatarURL       string    `json:"avatar_url"`
+	ExpertiseFields []string  `json:"expertise_fields"`
+	MutualFollows   int32     `json:"mutual_follows"`
+	SharedExpertise int32     `json:"shared_expertise"`
+	Reasons         []string  `json:"reasons"`
+}
+
+func suggestionReasons(s database.ListFollowSuggestionsRow) []string {
+	reasons := []string{}
+	if s.MutualFollows > 0 {
+		reasons = append(reasons, SuggestionReasonFollowedByFollowing)
+	}
+	if s.SharedExpertise > 0 {
+		reasons = append(reasons, SuggestionReasonSharedExpertise)
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, SuggestionReasonPopular)
+	}
+	return reasons
+}
+
+// GetFollowSuggestionsHandler lists contributors the user may want to follow,
+// best match first. The list is computed by the refresh-follow-suggestions job,
+// so people followed or blocked since then are filtered out at read time.
+func GetFollowSuggestionsHandler(db *database.Queries, user database.User) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := parsePagination(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		suggestions, err := db.ListFollowSuggestions(r.Context(), database.ListFollowSuggestionsParams{
+			UserID:     user.UserID,
+			PageLimit:  limit,
+			PageOffset: offset,
+		})
+		if err != nil {
+			http.Error(w, "Couldn't get follow suggestions", http.StatusInternalServerError)
+			return
+		}
+
+		returned := make([]ReturnedFollowSuggestion, len(suggestions))
+		for i, s := range suggestions {
+			expertise := s.ExpertiseFields
+			if expertise == nil {
+				expertise = []string{}
+			}
+			returned[i] = ReturnedFollowSuggestion{
+				UserID:          s.SuggestedUserID,
+				Name:            s.Name,
+				Username:        s.Username,
+				Headline:        s.Headline,
+				AvatarURL:       s.AvatarUrl,
+				ExpertiseFields: expertise,
+				MutualFollows:   s.MutualFollows,
+				SharedExpertise: s.SharedExpertise,
+				Reasons:         suggestionReasons(s),
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"suggestions": returned,
+			"limit":       limit,
+			"offset":      offset,
+		})
+	})
+}
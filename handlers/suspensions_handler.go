@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// suspendUser adds a suspension starting now that lasts days, or a permanent
+// ban when days is 0, and returns it with the user's suspended_until before
+// and after.
+func suspendUser(ctx context.Context, db *database.Queries, moderatorID, userID uuid.UUID, caseID, reportID uuid.NullUUID, days int, reason string) (database.Suspension, sql.NullTime, sql.NullTime, error) {
+	var suspension database.Suspension
+	var before, after sql.NullTime
+	err := db.RunInTx(ctx, func(q *database.Queries) error {
+		user, err := q.GetUserById(ctx, userID)
+		if err != nil {
+			return err
+		}
+		before = user.SuspendedUntil
+
+		now := time.Now().UTC()
+		endsAt := sql.NullTime{}
+		if days > 0 {
+			endsAt = sql.NullTime{Time: now.AddDate(0, 0, days), Valid: true}
+		}
+		suspension, err = q.CreateSuspension(ctx, database.CreateSuspensionParams{
+			SuspensionID: uuid.New(),
+			UserID:       userID,
+			CaseID:       caseID,
+			ReportID:     reportID,
+			IssuedBy:     uuid.NullUUID{UUID: moderatorID, Valid: true},
+			StartsAt:     now,
+			EndsAt:       endsAt,
+			Reason:       reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		after, err = refreshUserSuspension(ctx, q, userID)
+		return err
+	})
+	if err != nil {
+		return database.Suspension{}, sql.NullTime{}, sql.NullTime{}, err
+	}
+	return suspension, before, after, nil
+}
+
+// refreshUserSuspension sets users.suspended_until to the latest end of the
+// user's active suspensions, or permanentBanUntil if one of them is a ban.
+func refreshUserSuspension(ctx context.Context, db *database.Queries, userID uuid.UUID) (sql.NullTime, error) {
+	return db.RefreshUserSuspension(ctx, database.RefreshUserSuspensionParams{
+		BanUntil: permanentBanUntil,
+		UserID:   userID,
+		Now:      sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+}
+
+// liftReportSuspensions lifts the suspensions and bans the appealed report
+// gave, once the appeal is upheld, along with those given for its whole case
+// rather than one of its reports. Suspensions from the case's other reports
+// and from other cases keep running.
+func liftReportSuspensions(ctx context.Context, db *database.Queries, moderator database.Moderator, caseID, reportID, appealID uuid.UUID, reason string) error {
+	return db.RunInTx(ctx, func(q *database.Queries) error {
+		lifted, err := q.LiftReportSuspensions(ctx, database.LiftReportSuspensionsParams{
+			ReportID:       uuid.NullUUID{UUID: reportID, Valid: true},
+			CaseID:         uuid.NullUUID{UUID: caseID, Valid: true},
+			LiftedBy:       uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+			LiftedByAppeal: uuid.NullUUID{UUID: appealID, Valid: true},
+			LiftedAt:       sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		// Lifted suspensions are grouped per user, though a case only ever
+		// suspends its reported user.
+		liftedIDs := map[uuid.UUID][]uuid.UUID{}
+		for _, suspension := range lifted {
+			liftedIDs[suspension.UserID] = append(liftedIDs[suspension.UserID], suspension.SuspensionID)
+		}
+
+		for userID, ids := range liftedIDs {
+			user, err := q.GetUserById(ctx, userID)
+			if err != nil {
+				return err
+			}
+			suspendedUntil, err := refreshUserSuspension(ctx, q, userID)
+			if err != nil {
+				return err
+			}
+
+			if err := recordModerationAction(ctx, q, moderator.ModeratorID, moderationAction{
+				Action:        ActionSuspensionLifted,
+				TargetType:    "user",
+				TargetID:      userID,
+				SubjectUserID: uuid.NullUUID{UUID: userID, Valid: true},
+				Before:        map[string]interface{}{"suspended_until": nullTimePtr(user.SuspendedUntil)},
+				After: map[string]interface{}{
+					"suspended_until": nullTimePtr(suspendedUntil),
+					"suspension_ids":  ids,
+					"appeal_id":       appealID,
+					"case_id":         caseID,
+					"report_id":       reportID,
+				},
+				Reason: reason,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type ReturnedSuspension struct {
+	SuspensionID uuid.UUID  `json:"suspension_id"`
+	CaseID       *uuid.UUID `json:"case_id"`
+	ReportID     *uuid.UUID `json:"report_id"`
+	IssuedBy     *uuid.UUID `json:"issued_by"`
+	StartsAt     time.Time  `json:"starts_at"`
+	// EndsAt is null for a permanent ban.
+	EndsAt         *time.Time `json:"ends_at"`
+	Reason         string     `json:"reason"`
+	LiftedBy       *uuid.UUID `json:"lifted_by"`
+	LiftedByAppeal *uuid.UUID `json:"lifted_by_appeal"`
+	LiftedAt       *time.Time `json:"lifted_at"`
+	Active         bool       `json:"active"`
+}
+
+func toReturnedSuspension(suspension database.Suspension, now time.Time) ReturnedSuspension {
+	return ReturnedSuspension{
+		SuspensionID:   suspension.SuspensionID,
+		CaseID:         nullUUIDPtr(suspension.CaseID),
+		ReportID:       nullUUIDPtr(suspension.ReportID),
+		IssuedBy:       nullUUIDPtr(suspension.IssuedBy),
+		StartsAt:       suspension.StartsAt,
+		EndsAt:         nullTimePtr(suspension.EndsAt),
+		Reason:         suspension.Reason,
+		LiftedBy:       nullUUIDPtr(suspension.LiftedBy),
+		LiftedByAppeal: nullUUIDPtr(suspension.LiftedByAppeal),
+		LiftedAt:       nullTimePtr(suspension.LiftedAt),
+		Active:         !suspension.LiftedAt.Valid && (!suspension.EndsAt.Valid || suspension.EndsAt.Time.After(now)),
+	}
+}
+
+// GetUserSuspensionsHandler lists every suspension a user has had, newest
+// first, with the suspended_until they add up to.
+func GetUserSuspensionsHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest) // 400
+			return
+		}
+
+		user, err := db.GetUserById(r.Context(), userID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound) // 404
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get user", http.StatusInternalServerError) // 500
+			return
+		}
+
+		suspensions, err := db.ListSuspensionsByUser(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Couldn't get suspensions", http.StatusInternalServerError) // 500
+			return
+		}
+
+		now := time.Now().UTC()
+		returned := make([]ReturnedSuspension, len(suspensions))
+		for i, suspension := range suspensions {
+			returned[i] = toReturnedSuspension(suspension, now)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"user_id":         userID,
+			"suspended_until": nullTimePtr(user.SuspendedUntil),
+			"suspensions":     returned,
+		})
+	})
+}
+
+// LiftSuspensionHandler lifts one of a user's suspensions that has no case or
+// report behind it, such as those carried over from before suspensions were
+// tracked. Suspensions a case gave are lifted by upholding an appeal instead.
+func LiftSuspensionHandler(db *database.Queries, moderator database.Moderator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest) // 400
+			return
+		}
+		suspensionID, err := uuid.Parse(chi.URLParam(r, "suspensionID"))
+		if err != nil {
+			http.Error(w, "Invalid suspension ID", http.StatusBadRequest) // 400
+			return
+		}
+
+		type parameters struct {
+			Reason string `json:"reason"`
+		}
+		var params parameters
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest) // 400
+			return
+		}
+		params.Reason = strings.TrimSpace(params.Reason)
+		if params.Reason == "" {
+			http.Error(w, "reason is required", http.StatusBadRequest) // 400
+			return
+		}
+
+		tx, q, err := db.Begin(r.Context())
+		if err != nil {
+			http.Error(w, "Couldn't lift suspension", http.StatusInternalServerError) // 500
+			return
+		}
+		defer tx.Rollback()
+
+		user, err := q.GetUserById(r.Context(), userID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound) // 404
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't get user", http.StatusInternalServerError) // 500
+			return
+		}
+
+		now := time.Now().UTC()
+		lifted, err := q.LiftUnsourcedSuspension(r.Context(), database.LiftUnsourcedSuspensionParams{
+			SuspensionID: suspensionID,
+			UserID:       userID,
+			LiftedBy:     uuid.NullUUID{UUID: moderator.ModeratorID, Valid: true},
+			LiftedAt:     sql.NullTime{Time: now, Valid: true},
+		})
+		if err == sql.ErrNoRows {
+			http.Error(w, "No unlifted suspension without a case or report has that ID", http.StatusNotFound) // 404
+			return
+		}
+		if err != nil {
+			http.Error(w, "Couldn't lift suspension", http.StatusInternalServerError) // 500
+			return
+		}
+
+		suspendedUntil, err := refreshUserSuspension(r.Context(), q, userID)
+		if err != nil {
+			http.Error(w, "Couldn't lift suspension", http.StatusInternalServerError) // 500
+			return
+		}
+
+		if err := recordModerationAction(r.Context(), q, moderator.ModeratorID, moderationAction{
+			Action:        ActionSuspensionLifted,
+			TargetType:    "user",
+			TargetID:      userID,
+			SubjectUserID: uuid.NullUUID{UUID: userID, Valid: true},
+			Before:        map[string]interface{}{"suspended_until": nullTimePtr(user.SuspendedUntil)},
+			After: map[string]interface{}{
+				"suspended_until": nullTimePtr(suspendedUntil),
+				"suspension_ids":  []uuid.UUID{lifted.SuspensionID},
+			},
+			Reason: params.Reason,
+		}); err != nil {
+			http.Error(w, "Couldn't record the decision", http.StatusInternalServerError) // 500
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "Couldn't lift suspension", http.StatusInternalServerError) // 500
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"user_id":         userID,
+			"suspended_until": nullTimePtr(suspendedUntil),
+			"suspension":      toReturnedSuspension(lifted, now),
+		})
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"encoding/json"
 
+	"github.com/MyoMyatMin/expertly-backend/badges"
 	"github.com/MyoMyatMin/expertly-backend/pkg/database"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -34,6 +35,10 @@ func InsertUpvoteHandler(db *database.Queries, user database.User) http.Handler
 			return
 		}
 
+		if post, err := db.GetPost(r.Context(), postUUID); err == nil && post.UserID != user.UserID {
+			awardBadges(r.Context(), db, post.UserID, badges.HundredUpvotes)
+		}
+
 		w.WriteHeader(http.StatusCreated)
 
 	})
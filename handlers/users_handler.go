@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/MyoMyatMin/expertly-backend/pkg/database"
@@ -17,8 +18,10 @@ import (
 )
 
 type JWTClaims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Role   string    `json:"role"`
+	UserID          uuid.UUID `json:"user_id"`
+	SessionID       uuid.UUID `json:"session_id"`
+	ImpersonationID uuid.UUID `json:"impersonation_id"`
+	Role            string    `json:"role"`
 	jwt.RegisteredClaims
 }
 
@@ -32,23 +35,37 @@ type ReturnedUser struct {
 }
 
 type ReturnedProfileUser struct {
-	UserID         uuid.UUID `json:"user_id"`
-	Name           string    `json:"name"`
-	Email          string    `json:"email"`
-	Username       string    `json:"username"`
-	SuspendedUntil time.Time `json:"suspended_until"`
-	Role           string    `json:"role"`
-	Followers      int       `json:"followers"`
-	Following      int       `json:"following"`
-	IsFollowing    bool      `json:"is_following"`
+	UserID          uuid.UUID         `json:"user_id"`
+	Name            string            `json:"name"`
+	Email           string            `json:"email"`
+	Username        string            `json:"username"`
+	SuspendedUntil  time.Time         `json:"suspended_until"`
+	Role            string            `json:"role"`
+	Followers       int               `json:"followers"`
+	Following       int               `json:"following"`
+	IsFollowing     bool              `json:"is_following"`
+	Bio             string            `json:"bio"`
+	Headline        string            `json:"headline"`
+	Location        string            `json:"location"`
+	Website         string            `json:"website"`
+	Pronouns        string            `json:"pronouns"`
+	AvatarURL       string            `json:"avatar_url"`
+	SocialLinks     map[string]string `json:"social_links"`
+	PostCount       int               `json:"post_count"`
+	UpvotesReceived int               `json:"upvotes_received"`
+	Reputation      int64             `json:"reputation"`
+	// VerifiedExpertise is shown as badges on contributor profiles.
+	VerifiedExpertise []ReturnedExpertise `json:"verified_expertise"`
+	JoinedAt          *time.Time          `json:"joined_at"`
 }
 
-func generateAccessToken(userID uuid.UUID) (string, error) {
+func generateAccessToken(userID uuid.UUID, sessionID uuid.UUID) (string, error) {
 	godotenv.Load(".env")
 	var jwtSecretKey = os.Getenv("SECRET_KEY")
 	expirationTime := time.Now().Add(1 * time.Hour).Unix()
 	claims := jwt.MapClaims{
-		"user_id": userID,
+		"user_id":    userID,
+		"session_id": sessionID,
 
 		"exp": expirationTime,
 	}
@@ -57,12 +74,13 @@ func generateAccessToken(userID uuid.UUID) (string, error) {
 	return token.SignedString([]byte(jwtSecretKey))
 }
 
-func generateRefreshToken(userID uuid.UUID) (string, error) {
+func generateRefreshToken(userID uuid.UUID, sessionID uuid.UUID) (string, error) {
 	godotenv.Load(".env")
 	var jwtSecretKey = os.Getenv("SECRET_KEY")
-	expirationTime := time.Now().Add(24 * time.Hour).Unix()
+	expirationTime := time.Now().Add(refreshTokenLifetime).Unix()
 	claims := jwt.MapClaims{
-		"user_id": userID,
+		"user_id":    userID,
+		"session_id": sessionID,
 
 		"exp": expirationTime,
 	}
@@ -113,15 +131,9 @@ func SignUpHandler(db *database.Queries) http.Handler {
 			return
 		}
 
-		accessToken, err := generateAccessToken(user.UserID)
+		accessToken, refreshToken, err := issueSession(db, r, user.UserID, false)
 		if err != nil {
-			http.Error(w, "Couldn't generate access token", http.StatusInternalServerError)
-			return
-		}
-
-		refreshToken, err := generateRefreshToken(user.UserID)
-		if err != nil {
-			http.Error(w, "Couldn't generate refresh token", http.StatusInternalServerError)
+			http.Error(w, "Couldn't start session", http.StatusInternalServerError)
 			return
 		}
 
@@ -185,21 +197,20 @@ func LoginHandler(db *database.Queries) http.Handler {
 			return
 		}
 
-		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(params.Password))
-		if err != nil {
-			http.Error(w, "Invalid password", http.StatusUnauthorized)
+		if user.Password == "" {
+			http.Error(w, "This account signs in with Google, GitHub or another provider", http.StatusUnauthorized)
 			return
 		}
 
-		accessToken, err := generateAccessToken(user.UserID)
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(params.Password))
 		if err != nil {
-			http.Error(w, "Couldn't generate access token", http.StatusInternalServerError)
+			http.Error(w, "Invalid password", http.StatusUnauthorized)
 			return
 		}
 
-		refreshToken, err := generateRefreshToken(user.UserID)
+		accessToken, refreshToken, err := issueSession(db, r, user.UserID, false)
 		if err != nil {
-			http.Error(w, "Couldn't generate refresh token", http.StatusInternalServerError)
+			http.Error(w, "Couldn't start session", http.StatusInternalServerError)
 			return
 		}
 
@@ -256,32 +267,41 @@ func LoginHandler(db *database.Queries) http.Handler {
 	})
 }
 
-func LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     "access_token",
-		Value:    "",
-		Expires:  time.Now().Add(-1 * time.Hour),
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteNoneMode,
-	})
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    "",
-		Expires:  time.Now().Add(-1 * time.Hour),
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteNoneMode,
-	})
+func LogoutHandler(db *database.Queries) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sessionID := sessionIDFromRefreshCookie(r); sessionID != uuid.Nil {
+			if err := db.RevokeSession(r.Context(), sessionID); err != nil {
+				http.Error(w, "Couldn't end session", http.StatusInternalServerError)
+				return
+			}
+		}
 
-	response := map[string]interface{}{
-		"message": "Logged out",
-	}
+		http.SetCookie(w, &http.Cookie{
+			Name:     "access_token",
+			Value:    "",
+			Expires:  time.Now().Add(-1 * time.Hour),
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+		http.SetCookie(w, &http.Cookie{
+			Name:     "refresh_token",
+			Value:    "",
+			Expires:  time.Now().Add(-1 * time.Hour),
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+		response := map[string]interface{}{
+			"message": "Logged out",
+		}
 
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
 }
+
 func CheckAuthStatsHandler(db *database.Queries, user database.User, moderator database.Moderator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
@@ -369,16 +389,60 @@ func GetProfileDataHandler(db *database.Queries, user database.User, moderator d
 			return
 		}
 
+		profile, err := getUserProfile(r.Context(), db, aimedUser.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get profile", http.StatusInternalServerError)
+			return
+		}
+
+		stats, err := db.GetUserProfileStats(r.Context(), aimedUser.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get profile stats", http.StatusInternalServerError)
+			return
+		}
+
+		reputation, err := db.GetReputationScore(r.Context(), aimedUser.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get reputation", http.StatusInternalServerError)
+			return
+		}
+
+		settings, err := getPrivacySettings(r.Context(), db, aimedUser.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get privacy settings", http.StatusInternalServerError)
+			return
+		}
+		showEmail, err := canView(r.Context(), db, settings.EmailVisibility, aimedUser.UserID, user, moderator)
+		if err != nil {
+			http.Error(w, "Couldn't check privacy settings", http.StatusInternalServerError)
+			return
+		}
+		if !showEmail {
+			aimedUser.Email = ""
+		}
+
 		returnedUser := ReturnedProfileUser{
-			UserID:         aimedUser.UserID,
-			Name:           aimedUser.Name,
-			Email:          aimedUser.Email,
-			Username:       aimedUser.Username,
-			SuspendedUntil: aimedUser.SuspendedUntil.Time,
-			Role:           "user",
-			Followers:      int(follower_count),
-			Following:      int(following_count),
-			IsFollowing:    false,
+			UserID:            aimedUser.UserID,
+			Name:              aimedUser.Name,
+			Email:             aimedUser.Email,
+			Username:          aimedUser.Username,
+			SuspendedUntil:    aimedUser.SuspendedUntil.Time,
+			Role:              "user",
+			Followers:         int(follower_count),
+			Following:         int(following_count),
+			IsFollowing:       false,
+			Bio:               profile.Bio,
+			Headline:          profile.Headline,
+			Location:          profile.Location,
+			Website:           profile.Website,
+			Pronouns:          profile.Pronouns,
+			AvatarURL:         profile.AvatarUrl,
+			SocialLinks:       decodeSocialLinks(profile.SocialLinks),
+			PostCount:         int(stats.PostCount),
+			UpvotesReceived:   int(stats.UpvotesReceived),
+			Reputation:        reputation,
+			JoinedAt:          nullTimePtr(aimedUser.CreatedAt),
+			VerifiedExpertise: []ReturnedExpertise{},
 		}
 		if isFollowing {
 			returnedUser.IsFollowing = true
@@ -386,6 +450,11 @@ func GetProfileDataHandler(db *database.Queries, user database.User, moderator d
 
 		if isContributor {
 			returnedUser.Role = "contributor"
+			returnedUser.VerifiedExpertise, err = activeExpertise(r.Context(), db, aimedUser.UserID)
+			if err != nil {
+				http.Error(w, "Couldn't get verified expertise", http.StatusInternalServerError)
+				return
+			}
 		}
 
 		// followingList, err := db.GetFollowingList(r.Context(), aimedUser.UserID)
@@ -404,7 +473,7 @@ func GetProfileDataHandler(db *database.Queries, user database.User, moderator d
 	}
 }
 
-func GetContributorProfilePostsHandler(db *database.Queries) http.HandlerFunc {
+func GetContributorProfilePostsHandler(db *database.Queries, user database.User, moderator database.Moderator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		username := chi.URLParam(r, "username")
 
@@ -421,16 +490,45 @@ func GetContributorProfilePostsHandler(db *database.Queries) http.HandlerFunc {
 			return
 		}
 
+		takedowns, err := db.ListActivePostTakedownsByUser(r.Context(), aimedUser.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get posts by contributor", http.StatusInternalServerError)
+			return
+		}
+		if len(takedowns) > 0 {
+			skip := make(map[uuid.UUID]bool, len(takedowns))
+			for _, takedown := range takedowns {
+				if hidesContent(takedown, user, moderator.ModeratorID != uuid.Nil, aimedUser.UserID) {
+					skip[takedown.PostID.UUID] = true
+				}
+			}
+			visible := posts[:0]
+			for _, post := range posts {
+				if !skip[post.PostID] {
+					visible = append(visible, post)
+				}
+			}
+			posts = visible
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(posts)
 	}
 }
 
+// UpdateUserHandler updates the account name/username and the public profile.
+// Profile fields left out of the request keep their current value.
 func UpdateUserHandler(db *database.Queries, user database.User) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		type parameters struct {
-			Name     string `json:"name"`
-			Username string `json:"username"`
+			Name        string             `json:"name"`
+			Username    string             `json:"username"`
+			Bio         *string            `json:"bio"`
+			Headline    *string            `json:"headline"`
+			Location    *string            `json:"location"`
+			Website     *string            `json:"website"`
+			Pronouns    *string            `json:"pronouns"`
+			SocialLinks *map[string]string `json:"social_links"`
 		}
 
 		var params parameters
@@ -440,12 +538,85 @@ func UpdateUserHandler(db *database.Queries, user database.User) http.HandlerFun
 			return
 		}
 
-		err := db.UpdateUser(r.Context(), database.UpdateUserParams{
-			UserID:   user.UserID,
-			Name:     params.Name,
-			Username: params.Username,
-		})
+		if params.Name == "" {
+			params.Name = user.Name
+		}
+		if params.Username == "" {
+			params.Username = user.Username
+		}
 
+		profile, err := getUserProfile(r.Context(), db, user.UserID)
+		if err != nil {
+			http.Error(w, "Couldn't get profile", http.StatusInternalServerError)
+			return
+		}
+
+		textFields := []struct {
+			name  string
+			value *string
+			dest  *string
+			max   int
+		}{
+			{"bio", params.Bio, &profile.Bio, utils.MaxBioLength},
+			{"headline", params.Headline, &profile.Headline, utils.MaxHeadlineLength},
+			{"location", params.Location, &profile.Location, utils.MaxLocationLength},
+			{"pronouns", params.Pronouns, &profile.Pronouns, utils.MaxPronounsLength},
+		}
+		for _, field := range textFields {
+			if field.value == nil {
+				continue
+			}
+			value := strings.TrimSpace(*field.value)
+			if err := utils.CheckTextLength(field.name, value, field.max); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			*field.dest = value
+		}
+
+		if params.Website != nil {
+			profile.Website, err = utils.NormalizeProfileURL(*params.Website)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		}
+
+		if params.SocialLinks != nil {
+			links, err := utils.NormalizeSocialLinks(*params.SocialLinks)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			profile.SocialLinks, err = json.Marshal(links)
+			if err != nil {
+				http.Error(w, "Couldn't encode social links", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// The account and its profile are updated together, so a failed
+		// profile update doesn't leave a half-applied change.
+		err = db.RunInTx(r.Context(), func(q *database.Queries) error {
+			if err := q.UpdateUser(r.Context(), database.UpdateUserParams{
+				UserID:   user.UserID,
+				Name:     params.Name,
+				Username: params.Username,
+			}); err != nil {
+				return err
+			}
+
+			_, err := q.UpsertUserProfile(r.Context(), database.UpsertUserProfileParams{
+				UserID:      user.UserID,
+				Bio:         profile.Bio,
+				Headline:    profile.Headline,
+				Location:    profile.Location,
+				Website:     profile.Website,
+				Pronouns:    profile.Pronouns,
+				SocialLinks: profile.SocialLinks,
+			})
+			return err
+		})
 		if err != nil {
 			http.Error(w, "Couldn't update user", http.StatusInternalServerError)
 			return
@@ -472,7 +643,7 @@ func TestMiddlewaresHandler(db *database.Queries, user database.User) http.Handl
 	}
 }
 
-func SearchUsersHandler(db *database.Queries) http.HandlerFunc {
+func SearchUsersHandler(db *database.Queries, viewer database.User) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		query := r.URL.Query().Get("q")
 
@@ -482,8 +653,35 @@ func SearchUsersHandler(db *database.Queries) http.HandlerFunc {
 			return
 		}
 
+		userIDs := make([]uuid.UUID, len(users))
+		for i, user := range users {
+			userIDs[i] = user.UserID
+		}
+		visibilities, err := db.ListEmailVisibilities(r.Context(), userIDs)
+		if err != nil {
+			http.Error(w, "Couldn't get privacy settings", http.StatusInternalServerError)
+			return
+		}
+		emailVisibility := make(map[uuid.UUID]string, len(visibilities))
+		for _, v := range visibilities {
+			emailVisibility[v.UserID] = v.EmailVisibility
+		}
+
 		returnUsers := make([]ReturnedUser, len(users))
 		for i, user := range users {
+			visibility, ok := emailVisibility[user.UserID]
+			if !ok {
+				visibility = VisibilityPrivate
+			}
+			showEmail, err := canView(r.Context(), db, visibility, user.UserID, viewer, database.Moderator{})
+			if err != nil {
+				http.Error(w, "Couldn't check privacy settings", http.StatusInternalServerError)
+				return
+			}
+			if !showEmail {
+				user.Email = ""
+			}
+
 			returnUsers[i] = ReturnedUser{
 				UserID:         user.UserID,
 				Name:           user.Name,
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/utils"
+	"github.com/google/uuid"
+)
+
+// PurgeDeletedAccounts permanently removes accounts whose deletion grace
+// period has passed.
+func PurgeDeletedAccounts(ctx context.Context, db *sql.DB) error {
+	userIDs, err := database.New(db).ListDueAccountDeletions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list due deletions: %v", err)
+	}
+
+	// One account failing to purge mustn't hold up the others; it's retried
+	// on the next run.
+	var errs []error
+	for _, userID := range userIDs {
+		if err := purgeAccount(ctx, db, userID); err != nil {
+			fmt.Printf("Failed to purge account %s: %v\n", userID, err)
+			errs = append(errs, fmt.Errorf("failed to purge account %s: %v", userID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// purgeAccount hands the user's comments to the [deleted] placeholder so reply
+// threads stay intact, clears the rows whose foreign keys don't cascade, and
+// then deletes the user. Everything else (posts, upvotes, follows, reports,
+// appeals, sessions, ...) goes with the ON DELETE CASCADE constraints.
+func purgeAccount(ctx context.Context, db *sql.DB, userID uuid.UUID) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := database.New(db).WithTx(tx)
+
+	if err := q.AnonymizeCommentsByUser(ctx, database.AnonymizeCommentsByUserParams{
+		DeletedUserID: utils.DeletedUserID,
+		UserID:        userID,
+	}); err != nil {
+		return err
+	}
+	if err := q.DeleteSavedPostsByUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := q.DeleteContributorApplicationsByUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := q.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
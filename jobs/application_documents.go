@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/storage"
+)
+
+// unattachedDocumentTTL is how long an upload may sit without being attached
+// to an application.
+const unattachedDocumentTTL = 24 * time.Hour
+
+const documentPurgeBatch = 500
+
+// PurgeApplicationDocuments deletes identity documents past their retention
+// period, uploads never attached to an application, and documents of deleted
+// users, from storage and then from the database.
+func PurgeApplicationDocuments(ctx context.Context, db *sql.DB) error {
+	q := database.New(db)
+	now := time.Now().UTC()
+
+	documents, err := q.ListExpiredApplicationDocuments(ctx, database.ListExpiredApplicationDocumentsParams{
+		Now:              sql.NullTime{Time: now, Valid: true},
+		UnattachedBefore: now.Add(-unattachedDocumentTTL),
+		PageLimit:        documentPurgeBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list expired documents: %v", err)
+	}
+	if len(documents) == 0 {
+		return nil
+	}
+
+	store, err := storage.FromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %v", err)
+	}
+
+	for _, doc := range documents {
+		if err := store.Delete(ctx, doc.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("failed to delete %s from storage: %v", doc.StorageKey, err)
+		}
+		if err := q.DeleteApplicationDocument(ctx, doc.DocumentID); err != nil {
+			return fmt.Errorf("failed to delete document %s: %v", doc.DocumentID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/MyoMyatMin/expertly-backend/badges"
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/utils"
+)
+
+// EvaluateBadges checks every badge for everyone who has posted or commented.
+// It backfills badges earned before the engine existed and awards the ones
+// that depend on reputation, which no single request triggers.
+func EvaluateBadges(ctx context.Context, db *sql.DB) error {
+	q := database.New(db)
+
+	userIDs, err := q.ListBadgeCandidates(ctx, utils.DeletedUserID)
+	if err != nil {
+		return fmt.Errorf("failed to list badge candidates: %v", err)
+	}
+
+	for _, userID := range userIDs {
+		if _, err := badges.Evaluate(ctx, q, userID); err != nil {
+			return fmt.Errorf("failed to evaluate badges for %s: %v", userID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/google/uuid"
+)
+
+// renewalReminderWindow is how long before their status lapses contributors
+// are reminded to renew it.
+const renewalReminderWindow = 14 * 24 * time.Hour
+
+// UpdateContributorStatuses ends suspensions whose suspended_until has
+// passed, expires contributors past their expires_at, and reminds
+// contributors whose status is about to lapse.
+func UpdateContributorStatuses(ctx context.Context, db *sql.DB) error {
+	q := database.New(db)
+	now := time.Now().UTC()
+	nullNow := sql.NullTime{Time: now, Valid: true}
+
+	// One contributor failing mustn't hold up the others; they're retried on
+	// the next run.
+	var errs []error
+	fail := func(err error) {
+		fmt.Println(err)
+		errs = append(errs, err)
+	}
+
+	ended, err := q.ListEndedContributorSuspensions(ctx, nullNow)
+	if err != nil {
+		fail(fmt.Errorf("failed to list ended suspensions: %v", err))
+	}
+	for _, contributor := range ended {
+		if err := changeContributorStatus(ctx, db, contributor, "active", "Suspension ended", now); err != nil {
+			fail(fmt.Errorf("failed to end suspension of %s: %v", contributor.UserID, err))
+		}
+	}
+
+	lapsed, err := q.ListLapsedContributors(ctx, nullNow)
+	if err != nil {
+		fail(fmt.Errorf("failed to list lapsed contributors: %v", err))
+	}
+	for _, contributor := range lapsed {
+		if err := changeContributorStatus(ctx, db, contributor, "expired", "Contributor status expired", now); err != nil {
+			fail(fmt.Errorf("failed to expire %s: %v", contributor.UserID, err))
+		}
+	}
+
+	due, err := q.ListContributorsDueRenewalReminder(ctx, sql.NullTime{Time: now.Add(renewalReminderWindow), Valid: true})
+	if err != nil {
+		fail(fmt.Errorf("failed to list contributors due a renewal reminder: %v", err))
+	}
+	for _, contributor := range due {
+		if err := remindContributor(ctx, db, contributor, now); err != nil {
+			fail(fmt.Errorf("failed to remind %s: %v", contributor.UserID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// remindContributor tells a contributor their status is about to lapse and
+// marks them reminded, together so they're neither reminded twice nor marked
+// without being told.
+func remindContributor(ctx context.Context, db *sql.DB, contributor database.Contributor, now time.Time) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := database.New(db).WithTx(tx)
+
+	message := fmt.Sprintf("Your contributor status expires on %s; apply again to renew it", contributor.ExpiresAt.Time.Format("2 January 2006"))
+	if err := notifyContributor(ctx, q, contributor, "contributor_status_renewal", message, ""); err != nil {
+		return err
+	}
+	if err := q.MarkContributorRenewalReminded(ctx, database.MarkContributorRenewalRemindedParams{
+		UserID:            contributor.UserID,
+		RenewalRemindedAt: sql.NullTime{Time: now, Valid: true},
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// changeContributorStatus moves a contributor to status and records the
+// change, skipping contributors a moderator changed in the meantime.
+func changeContributorStatus(ctx context.Context, db *sql.DB, contributor database.Contributor, status, reason string, now time.Time) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := database.New(db).WithTx(tx)
+
+	updated, err := q.SetContributorStatus(ctx, database.SetContributorStatusParams{
+		UserID:       contributor.UserID,
+		Status:       status,
+		StatusReason: reason,
+		FromStatus:   contributor.Status,
+	})
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := q.CreateContributorStatusHistory(ctx, database.CreateContributorStatusHistoryParams{
+		HistoryID:  uuid.New(),
+		UserID:     contributor.UserID,
+		FromStatus: contributor.Status,
+		ToStatus:   status,
+		Reason:     reason,
+		ExpiresAt:  updated.ExpiresAt,
+		CreatedAt:  now,
+	}); err != nil {
+		return err
+	}
+
+	message := "Your contributor status is active again"
+	if status == "expired" {
+		message = "Your contributor status has expired; apply again to renew it"
+	}
+	if err := notifyContributor(ctx, q, updated, "contributor_status_"+status, message, reason); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func notifyContributor(ctx context.Context, q *database.Queries, contributor database.Contributor, notificationType, message, reason string) error {
+	var expiresAt *time.Time
+	if contributor.ExpiresAt.Valid {
+		expiresAt = &contributor.ExpiresAt.Time
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"reason":     reason,
+		"expires_at": expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return q.CreateNotification(ctx, database.CreateNotificationParams{
+		NotificationID: uuid.New(),
+		UserID:         contributor.UserID,
+		Type:           notificationType,
+		Message:        message,
+		Data:           data,
+	})
+}
@@ -0,0 +1,21 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+)
+
+// ExpireExpertiseVerifications drops expired verifications from
+// contributors.expertise_fields, which suggestions, leaderboards and badges
+// read.
+func ExpireExpertiseVerifications(ctx context.Context, db *sql.DB) error {
+	now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	if err := database.New(db).SyncContributorExpertiseFields(ctx, now); err != nil {
+		return fmt.Errorf("failed to sync expertise fields: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/utils"
+)
+
+// suggestionsPerUser is how many follow suggestions are kept for each user.
+const suggestionsPerUser = 50
+
+// RefreshFollowSuggestions rebuilds the "who to follow" table. Contributors
+// are scored per user on how many of the people the user follows already
+// follow them, how many of their expertise fields match the posts the user
+// upvoted or saved, and how much engagement their posts get overall; the most
+// engaged-with contributors are considered for everyone so new users still
+// get suggestions. Accounts the user follows, has blocked or muted, or that
+// blocked the user are left out.
+func RefreshFollowSuggestions(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := database.New(db).WithTx(tx)
+
+	if err := q.DeleteAllFollowSuggestions(ctx); err != nil {
+		return fmt.Errorf("failed to clear follow suggestions: %v", err)
+	}
+	if err := q.InsertFollowSuggestions(ctx, database.InsertFollowSuggestionsParams{
+		PerUser:       suggestionsPerUser,
+		DeletedUserID: utils.DeletedUserID,
+		ComputedAt:    time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to compute follow suggestions: %v", err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,59 @@
+// Package jobs holds periodic maintenance tasks. Long-running deployments run
+// them on a ticker via Start; on Vercel they are triggered through the cron
+// route instead.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+var ErrUnknownJob = errors.New("unknown job")
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, db *sql.DB) error
+}
+
+var All = []Job{
+	{Name: "purge-deleted-accounts", Interval: time.Hour, Run: PurgeDeletedAccounts},
+	{Name: "refresh-follow-suggestions", Interval: 6 * time.Hour, Run: RefreshFollowSuggestions},
+	{Name: "refresh-reputation", Interval: time.Hour, Run: RefreshReputation},
+	{Name: "evaluate-badges", Interval: 6 * time.Hour, Run: EvaluateBadges},
+	{Name: "expire-expertise-verifications", Interval: time.Hour, Run: ExpireExpertiseVerifications},
+	{Name: "purge-application-documents", Interval: time.Hour, Run: PurgeApplicationDocuments},
+	{Name: "update-contributor-statuses", Interval: time.Hour, Run: UpdateContributorStatuses},
+}
+
+// Start runs every job once and then on its interval until ctx is cancelled.
+func Start(ctx context.Context, db *sql.DB) {
+	for _, job := range All {
+		go func(job Job) {
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+			for {
+				if err := job.Run(ctx, db); err != nil {
+					log.Printf("job %s failed: %v", job.Name, err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(job)
+	}
+}
+
+func RunByName(ctx context.Context, db *sql.DB, name string) error {
+	for _, job := range All {
+		if job.Name == name {
+			return job.Run(ctx, db)
+		}
+	}
+	return ErrUnknownJob
+}
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+)
+
+// RefreshReputation brings the reputation ledger in line with the
+// reputation_sources view: new upvotes, comments, accepted applications and
+// upheld reports are recorded, and entries whose source disappeared (an upvote
+// withdrawn, a report overturned on appeal, a post deleted) are removed.
+func RefreshReputation(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := database.New(db).WithTx(tx)
+
+	if _, err := q.DeleteStaleReputationEntries(ctx); err != nil {
+		return fmt.Errorf("failed to remove stale reputation entries: %v", err)
+	}
+	if _, err := q.SyncReputationEntries(ctx, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record reputation entries: %v", err)
+	}
+
+	return tx.Commit()
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/MyoMyatMin/expertly-backend/jobs"
 	"github.com/MyoMyatMin/expertly-backend/routes"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
@@ -35,6 +37,8 @@ func main() {
 		log.Fatalf("Error connecting to the database: %v", err)
 	}
 
+	jobs.Start(context.Background(), db)
+
 	router := routes.SetUpRoutes(db)
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,121 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/utils"
+	"github.com/google/uuid"
+)
+
+// apiKeyResourceBySegment maps the first path segment after /api to the scope
+// resource that guards it. Segments missing from the map (e.g. "auth") can't
+// be reached with an API key at all.
+var apiKeyResourceBySegment = map[string]string{
+	"posts":                    "posts",
+	"upvotes":                  "upvotes",
+	"follow":                   "follows",
+	"users":                    "follows",
+	"feed":                     "feed",
+	"saved-posts":              "saved-posts",
+	"profile":                  "profile",
+	"search":                   "search",
+	"reports":                  "reports",
+	"appeals":                  "appeals",
+	"contributor-applications": "applications",
+	"admin":                    "admin",
+	"notifications":            "notifications",
+}
+
+// accountOnlyProfileRoutes are the /profile routes no API key can reach,
+// whatever its scopes: they hand over or delete the whole account.
+var accountOnlyProfileRoutes = map[string]bool{
+	"delete": true,
+	"export": true,
+}
+
+// accountOnlyAdminRoutes are the /admin routes no API key can reach, whatever
+// its scopes: impersonation acts as another user and needs a moderator's own
+// login.
+var accountOnlyAdminRoutes = map[string]bool{
+	"impersonations": true,
+}
+
+func extractAPIKey(r *http.Request) (string, bool) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key, true
+	}
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && strings.HasPrefix(bearer, utils.APIKeyPrefix) {
+		return bearer, true
+	}
+	return "", false
+}
+
+// requiredAPIKeyScope returns the scope a key needs to call the requested
+// route, or "" if the route is not available to API keys.
+func requiredAPIKeyScope(r *http.Request) string {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api"), "/"), "/")
+	resource, ok := apiKeyResourceBySegment[segments[0]]
+	if !ok {
+		return ""
+	}
+	if segments[0] == "profile" && len(segments) >= 2 && accountOnlyProfileRoutes[segments[1]] {
+		return ""
+	}
+	if segments[0] == "admin" && len(segments) >= 2 && accountOnlyAdminRoutes[segments[1]] {
+		return ""
+	}
+	// Blocking and muting share /users and /profile with follows and the
+	// profile itself, but get their own scope.
+	if segments[0] == "users" && len(segments) >= 3 && (segments[2] == "block" || segments[2] == "mute") {
+		resource = "blocks"
+	}
+	if segments[0] == "profile" && len(segments) >= 2 && (segments[1] == "blocks" || segments[1] == "mutes") {
+		resource = "blocks"
+	}
+	if segments[0] == "posts" && len(segments) >= 3 {
+		switch segments[2] {
+		case "upvotes":
+			resource = "upvotes"
+		case "comments":
+			resource = "comments"
+		}
+	}
+
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return resource + ":read"
+	}
+	return resource + ":write"
+}
+
+func authenticateAPIKey(db *database.Queries, r *http.Request, key string) (uuid.UUID, int, error) {
+	apiKey, err := db.GetActiveAPIKeyByHash(r.Context(), utils.HashToken(key))
+	if err != nil {
+		return uuid.Nil, http.StatusUnauthorized, errors.New("invalid or expired api key")
+	}
+
+	scope := requiredAPIKeyScope(r)
+	if scope == "" {
+		return uuid.Nil, http.StatusForbidden, errors.New("this route can't be called with an api key")
+	}
+	allowed := false
+	for _, s := range apiKey.Scopes {
+		if s == scope {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return uuid.Nil, http.StatusForbidden, errors.New("api key is missing the " + scope + " scope")
+	}
+
+	// Failing to record usage shouldn't fail the request itself.
+	_ = db.TouchAPIKey(r.Context(), apiKey.ApiKeyID)
+
+	if apiKey.UserID.Valid {
+		return apiKey.UserID.UUID, http.StatusOK, nil
+	}
+	return apiKey.ModeratorID.UUID, http.StatusOK, nil
+}
@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -38,22 +40,18 @@ func MiddlewareAuth(
 	return func(w http.ResponseWriter, r *http.Request) {
 		loadEnvIfLocal()
 
-		tokenString, err := extractTokenCookie(r)
+		creds, status, err := authenticate(db, r)
 		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, err.Error())
+			respondWithError(w, status, err.Error())
 			return
 		}
-
-		claims, err := parseJWTToken(tokenString)
-		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, err.Error())
-			return
-		}
-
-		userID, err := getUserIDFromClaims(claims)
-		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, err.Error())
-			return
+		userID := creds.userID
+		r = r.WithContext(context.WithValue(r.Context(), sessionIDContextKey, creds.sessionID))
+		if creds.impersonationID != uuid.Nil {
+			r = r.WithContext(context.WithValue(r.Context(), impersonatorIDContextKey, creds.impersonatorID))
+			var audit func()
+			w, audit = auditImpersonation(db, w, r, creds)
+			defer audit()
 		}
 
 		switch authType {
@@ -78,10 +76,25 @@ func MiddlewareAuth(
 				respondWithError(w, http.StatusUnauthorized, "Contributor not found")
 				return
 			}
+			userRow, err := db.GetUserById(r.Context(), userID)
+			if err != nil {
+				respondWithError(w, http.StatusUnauthorized, "User not found")
+				return
+			}
+			if err := checkUserSuspension(userRow.SuspendedUntil, time.Now().UTC()); err != nil {
+				respondWithError(w, http.StatusForbidden, err.Error())
+				return
+			}
+			if err := checkContributorStatus(contributorRow, time.Now().UTC()); err != nil {
+				respondWithError(w, http.StatusForbidden, err.Error())
+				return
+			}
 			contributor := database.Contributor{
 				UserID:          contributorRow.UserID,
 				ExpertiseFields: contributorRow.ExpertiseFields,
 				CreatedAt:       contributorRow.CreatedAt,
+				Status:          contributorRow.Status,
+				ExpiresAt:       contributorRow.ExpiresAt,
 			}
 			handlerWithContributor(w, r, contributor)
 
@@ -91,6 +104,10 @@ func MiddlewareAuth(
 				respondWithError(w, http.StatusUnauthorized, "User not found")
 				return
 			}
+			if err := checkUserSuspension(userRow.SuspendedUntil, time.Now().UTC()); err != nil {
+				respondWithError(w, http.StatusForbidden, err.Error())
+				return
+			}
 			user := database.User{
 				UserID:         userRow.UserID,
 				Name:           userRow.Name,
@@ -118,22 +135,18 @@ func MiddlewareModeratorOrUser(
 	return func(w http.ResponseWriter, r *http.Request) {
 		loadEnvIfLocal()
 
-		tokenString, err := extractTokenCookie(r)
-		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, err.Error())
-			return
-		}
-
-		claims, err := parseJWTToken(tokenString)
+		creds, status, err := authenticate(db, r)
 		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, err.Error())
+			respondWithError(w, status, err.Error())
 			return
 		}
-
-		userID, err := getUserIDFromClaims(claims)
-		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, err.Error())
-			return
+		userID := creds.userID
+		r = r.WithContext(context.WithValue(r.Context(), sessionIDContextKey, creds.sessionID))
+		if creds.impersonationID != uuid.Nil {
+			r = r.WithContext(context.WithValue(r.Context(), impersonatorIDContextKey, creds.impersonatorID))
+			var audit func()
+			w, audit = auditImpersonation(db, w, r, creds)
+			defer audit()
 		}
 
 		// Try moderator first
@@ -155,6 +168,10 @@ func MiddlewareModeratorOrUser(
 			respondWithError(w, http.StatusUnauthorized, "User not found")
 			return
 		}
+		if err := checkUserSuspension(userRow.SuspendedUntil, time.Now().UTC()); err != nil {
+			respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		user := database.User{
 			UserID:         userRow.UserID,
 			Name:           userRow.Name,
@@ -171,6 +188,112 @@ func MiddlewareModeratorOrUser(
 
 // --- Utility functions ---
 
+// checkUserSuspension rejects users whose suspension hasn't ended yet. A ban
+// is a suspension until 9999-12-31, so it never ends.
+func checkUserSuspension(suspendedUntil sql.NullTime, now time.Time) error {
+	if suspendedUntil.Valid && now.Before(suspendedUntil.Time) {
+		return fmt.Errorf("your account is suspended until %s", suspendedUntil.Time.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkContributorStatus rejects contributors who are suspended, revoked or
+// past their expiry. Suspensions and expiries are also applied by a periodic
+// job; checking the dates here keeps them exact in between runs.
+func checkContributorStatus(contributor database.Contributor, now time.Time) error {
+	switch contributor.Status {
+	case "revoked":
+		return errors.New("your contributor status was revoked")
+	case "expired":
+		return errors.New("your contributor status has expired")
+	case "suspended":
+		if !contributor.SuspendedUntil.Valid {
+			return errors.New("your contributor status is suspended")
+		}
+		if now.Before(contributor.SuspendedUntil.Time) {
+			return fmt.Errorf("your contributor status is suspended until %s", contributor.SuspendedUntil.Time.Format(time.RFC3339))
+		}
+	}
+	if contributor.ExpiresAt.Valid && !now.Before(contributor.ExpiresAt.Time) {
+		return errors.New("your contributor status has expired")
+	}
+	return nil
+}
+
+// credentials identifies who a request was made by and, for cookie logins,
+// which session it belongs to. impersonationID and impersonatorID are set when
+// a moderator is viewing the site as userID.
+type credentials struct {
+	userID          uuid.UUID
+	sessionID       uuid.UUID
+	impersonationID uuid.UUID
+	impersonatorID  uuid.UUID
+}
+
+// authenticate resolves the caller from a personal API key or an impersonation
+// bearer token if one is presented, falling back to the access token cookie.
+func authenticate(db *database.Queries, r *http.Request) (credentials, int, error) {
+	if key, ok := extractAPIKey(r); ok {
+		userID, status, err := authenticateAPIKey(db, r, key)
+		return credentials{userID: userID}, status, err
+	}
+	if token, ok := extractImpersonationToken(r); ok {
+		return authenticateImpersonation(db, r, token)
+	}
+
+	tokenString, err := extractTokenCookie(r)
+	if err != nil {
+		return credentials{}, http.StatusUnauthorized, err
+	}
+
+	claims, err := parseJWTToken(tokenString)
+	if err != nil {
+		return credentials{}, http.StatusUnauthorized, err
+	}
+	if _, ok := claims["impersonation_id"]; ok {
+		// Otherwise the token would pass as a pre-session login and skip the
+		// read-only check and audit log.
+		return credentials{}, http.StatusUnauthorized, errors.New("impersonation tokens must be sent as a bearer token")
+	}
+
+	userID, err := getUserIDFromClaims(claims)
+	if err != nil {
+		return credentials{}, http.StatusUnauthorized, err
+	}
+
+	sessionID, err := checkSessionFromClaims(db, r, claims)
+	if err != nil {
+		return credentials{}, http.StatusUnauthorized, err
+	}
+	return credentials{userID: userID, sessionID: sessionID}, http.StatusOK, nil
+}
+
+// checkSessionFromClaims makes sure the session an access token was issued for
+// hasn't been revoked. Tokens minted before sessions existed carry no
+// session_id and are accepted until they expire.
+func checkSessionFromClaims(db *database.Queries, r *http.Request, claims jwt.MapClaims) (uuid.UUID, error) {
+	sessionIDStr, ok := claims["session_id"].(string)
+	if !ok {
+		return uuid.Nil, nil
+	}
+	sessionID, err := uuid.Parse(sessionIDStr)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid session ID format in token")
+	}
+
+	session, err := db.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		return uuid.Nil, errors.New("session not found")
+	}
+	if session.RevokedAt.Valid || time.Now().After(session.ExpiresAt) {
+		return uuid.Nil, errors.New("session has been signed out")
+	}
+
+	// Activity tracking is best effort and throttled in the query itself.
+	_ = db.TouchSession(r.Context(), sessionID)
+	return sessionID, nil
+}
+
 func respondWithError(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
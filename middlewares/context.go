@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type contextKey string
+
+const (
+	sessionIDContextKey      contextKey = "session_id"
+	impersonatorIDContextKey contextKey = "impersonator_id"
+)
+
+// SessionIDFromContext returns the login session the request was authenticated
+// with, or uuid.Nil for API key requests and tokens issued before sessions.
+func SessionIDFromContext(ctx context.Context) uuid.UUID {
+	sessionID, _ := ctx.Value(sessionIDContextKey).(uuid.UUID)
+	return sessionID
+}
+
+// ImpersonatorIDFromContext returns the moderator viewing the site as the
+// authenticated user, or uuid.Nil for ordinary requests.
+func ImpersonatorIDFromContext(ctx context.Context) uuid.UUID {
+	impersonatorID, _ := ctx.Value(impersonatorIDContextKey).(uuid.UUID)
+	return impersonatorID
+}
@@ -0,0 +1,122 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Impersonation tokens are never allowed on these first path segments, even
+// when the moderator asked for write access: they manage the account's own
+// credentials and data.
+var impersonationBlockedSegments = map[string]bool{
+	"auth": true,
+}
+
+// impersonationBlockedPaths are individual routes refused to impersonation
+// tokens for the same reason.
+var impersonationBlockedPaths = map[string]bool{
+	"profile/delete": true,
+	"profile/export": true,
+}
+
+// extractImpersonationToken returns the bearer token if it's an impersonation
+// token. Other bearer values are left to the cookie auth; the token is only
+// verified by authenticateImpersonation.
+func extractImpersonationToken(r *http.Request) (string, bool) {
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || bearer == "" {
+		return "", false
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(bearer, claims); err != nil {
+		return "", false
+	}
+	if _, ok := claims["impersonation_id"]; !ok {
+		return "", false
+	}
+	return bearer, true
+}
+
+// authenticateImpersonation checks a "view as user" token minted by
+// CreateImpersonationHandler. The token must still be active in the database
+// (moderators can end it early) and, unless the moderator asked for write
+// access, only safe methods are allowed.
+func authenticateImpersonation(db *database.Queries, r *http.Request, tokenString string) (credentials, int, error) {
+	claims, err := parseJWTToken(tokenString)
+	if err != nil {
+		return credentials{}, http.StatusUnauthorized, err
+	}
+
+	impersonationIDStr, ok := claims["impersonation_id"].(string)
+	if !ok {
+		return credentials{}, http.StatusUnauthorized, errors.New("bearer tokens are only accepted for impersonation")
+	}
+	impersonationID, err := uuid.Parse(impersonationIDStr)
+	if err != nil {
+		return credentials{}, http.StatusUnauthorized, errors.New("invalid impersonation ID format in token")
+	}
+
+	impersonation, err := db.GetActiveImpersonation(r.Context(), impersonationID)
+	if err != nil {
+		return credentials{}, http.StatusUnauthorized, errors.New("impersonation has ended")
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api"), "/")
+	segment, _, _ := strings.Cut(path, "/")
+	if impersonationBlockedSegments[segment] || impersonationBlockedPaths[path] {
+		return credentials{}, http.StatusForbidden, errors.New("this route is not available while impersonating")
+	}
+	if impersonation.ReadOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return credentials{}, http.StatusForbidden, errors.New("impersonation is read-only")
+	}
+
+	return credentials{
+		userID:          impersonation.UserID,
+		impersonationID: impersonation.ImpersonationID,
+		impersonatorID:  impersonation.ModeratorID,
+	}, http.StatusOK, nil
+}
+
+// statusRecorder remembers the status code a handler wrote so impersonated
+// requests can be audited with their outcome.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// auditImpersonation flags the response and returns a function that records
+// the request in the impersonation audit log once the handler has run.
+func auditImpersonation(db *database.Queries, w http.ResponseWriter, r *http.Request, creds credentials) (http.ResponseWriter, func()) {
+	w.Header().Set("X-Impersonated-By", creds.impersonatorID.String())
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+	return recorder, func() {
+		// The request context may already be cancelled by the time we get here.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := db.CreateImpersonationRequest(ctx, database.CreateImpersonationRequestParams{
+			RequestID:       uuid.New(),
+			ImpersonationID: creds.impersonationID,
+			Method:          r.Method,
+			Path:            r.URL.RequestURI(),
+			StatusCode:      int32(recorder.status),
+		})
+		if err != nil {
+			log.Printf("failed to audit impersonated request: %v", err)
+		}
+	}
+}
@@ -8,6 +8,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -19,15 +20,17 @@ INSERT INTO contributor_applications(
     user_id,
     expertise_proofs,
     identity_proof,
-    initial_submission
+    initial_submission,
+    requested_fields
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6
 )
-RETURNING contri_app_id, user_id, expertise_proofs, identity_proof, initial_submission, status, created_at, reviewed_at
+RETURNING contri_app_id, user_id, expertise_proofs, identity_proof, initial_submission, status, created_at, reviewed_at, requested_fields, reviewer_notes, updated_at
 `
 
 type ApplyContributorApplicationParams struct {
@@ -36,6 +39,7 @@ type ApplyContributorApplicationParams struct {
 	ExpertiseProofs   []string
 	IdentityProof     string
 	InitialSubmission string
+	RequestedFields   []string
 }
 
 type ApplyContributorApplicationRow struct {
@@ -47,6 +51,9 @@ type ApplyContributorApplicationRow struct {
 	Status            sql.NullString
 	CreatedAt         sql.NullTime
 	ReviewedAt        sql.NullTime
+	RequestedFields   []string
+	ReviewerNotes     string
+	UpdatedAt         time.Time
 }
 
 func (q *Queries) ApplyContributorApplication(ctx context.Context, arg ApplyContributorApplicationParams) (ApplyContributorApplicationRow, error) {
@@ -56,6 +63,7 @@ func (q *Queries) ApplyContributorApplication(ctx context.Context, arg ApplyCont
 		pq.Array(arg.ExpertiseProofs),
 		arg.IdentityProof,
 		arg.InitialSubmission,
+		pq.Array(arg.RequestedFields),
 	)
 	var i ApplyContributorApplicationRow
 	err := row.Scan(
@@ -67,6 +75,9 @@ func (q *Queries) ApplyContributorApplication(ctx context.Context, arg ApplyCont
 		&i.Status,
 		&i.CreatedAt,
 		&i.ReviewedAt,
+		pq.Array(&i.RequestedFields),
+		&i.ReviewerNotes,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -81,6 +92,10 @@ SELECT
     ca.status,
     ca.created_at,
     ca.reviewed_at,
+    ca.reviewed_by,
+    ca.requested_fields,
+    ca.reviewer_notes,
+    ca.updated_at,
     u.name AS name,
     u.username AS username,
     m.name AS reviewer_name
@@ -99,6 +114,10 @@ type GetContributorApplicationRow struct {
 	Status            sql.NullString
 	CreatedAt         sql.NullTime
 	ReviewedAt        sql.NullTime
+	ReviewedBy        uuid.NullUUID
+	RequestedFields   []string
+	ReviewerNotes     string
+	UpdatedAt         time.Time
 	Name              string
 	Username          string
 	ReviewerName      sql.NullString
@@ -116,6 +135,10 @@ func (q *Queries) GetContributorApplication(ctx context.Context, contriAppID uui
 		&i.Status,
 		&i.CreatedAt,
 		&i.ReviewedAt,
+		&i.ReviewedBy,
+		pq.Array(&i.RequestedFields),
+		&i.ReviewerNotes,
+		&i.UpdatedAt,
 		&i.Name,
 		&i.Username,
 		&i.ReviewerName,
@@ -134,6 +157,9 @@ SELECT
     ca.created_at,
     ca.reviewed_at,
     ca.reviewed_by,
+    ca.requested_fields,
+    ca.reviewer_notes,
+    ca.updated_at,
     u.name AS name,
     m.name AS reviewer_name
 FROM contributor_applications ca
@@ -152,6 +178,9 @@ type ListContributorApplicationsRow struct {
 	CreatedAt         sql.NullTime
 	ReviewedAt        sql.NullTime
 	ReviewedBy        uuid.NullUUID
+	RequestedFields   []string
+	ReviewerNotes     string
+	UpdatedAt         time.Time
 	Name              string
 	ReviewerName      sql.NullString
 }
@@ -175,6 +204,9 @@ func (q *Queries) ListContributorApplications(ctx context.Context) ([]ListContri
 			&i.CreatedAt,
 			&i.ReviewedAt,
 			&i.ReviewedBy,
+			pq.Array(&i.RequestedFields),
+			&i.ReviewerNotes,
+			&i.UpdatedAt,
 			&i.Name,
 			&i.ReviewerName,
 		); err != nil {
@@ -191,20 +223,153 @@ func (q *Queries) ListContributorApplications(ctx context.Context) ([]ListContri
 	return items, nil
 }
 
-const updateContributorApplication = `-- name: UpdateContributorApplication :one
+const listContributorApplicationsByUser = `-- name: ListContributorApplicationsByUser :many
+SELECT 
+    contri_app_id,
+    user_id,
+    expertise_proofs,
+    identity_proof,
+    initial_submission,
+    status,
+    created_at,
+    reviewed_at,
+    requested_fields,
+    reviewer_notes,
+    updated_at
+FROM contributor_applications
+WHERE user_id = $1
+ORDER BY created_at DESC
+`
+
+type ListContributorApplicationsByUserRow struct {
+	ContriAppID       uuid.UUID
+	UserID            uuid.UUID
+	ExpertiseProofs   []string
+	IdentityProof     string
+	InitialSubmission string
+	Status            sql.NullString
+	CreatedAt         sql.NullTime
+	ReviewedAt        sql.NullTime
+	RequestedFields   []string
+	ReviewerNotes     string
+	UpdatedAt         time.Time
+}
+
+func (q *Queries) ListContributorApplicationsByUser(ctx context.Context, userID uuid.UUID) ([]ListContributorApplicationsByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, listContributorApplicationsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListContributorApplicationsByUserRow
+	for rows.Next() {
+		var i ListContributorApplicationsByUserRow
+		if err := rows.Scan(
+			&i.ContriAppID,
+			&i.UserID,
+			pq.Array(&i.ExpertiseProofs),
+			&i.IdentityProof,
+			&i.InitialSubmission,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ReviewedAt,
+			pq.Array(&i.RequestedFields),
+			&i.ReviewerNotes,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resubmitContributorApplication = `-- name: ResubmitContributorApplication :one
 UPDATE contributor_applications
 SET
-    status = $2,
-    reviewed_at = CURRENT_TIMESTAMP,
-    reviewed_by = $3
+    expertise_proofs = $2,
+    identity_proof = $3,
+    initial_submission = $4,
+    requested_fields = $5,
+    status = 'pending',
+    updated_at = CURRENT_TIMESTAMP
 WHERE contri_app_id = $1
-RETURNING contri_app_id, user_id, expertise_proofs, identity_proof, initial_submission, status, created_at, reviewed_at
+AND status = 'needs_more_info'
+RETURNING contri_app_id, user_id, expertise_proofs, identity_proof, initial_submission, status, created_at, reviewed_at, requested_fields, reviewer_notes, updated_at
+`
+
+type ResubmitContributorApplicationParams struct {
+	ContriAppID       uuid.UUID
+	ExpertiseProofs   []string
+	IdentityProof     string
+	InitialSubmission string
+	RequestedFields   []string
+}
+
+type ResubmitContributorApplicationRow struct {
+	ContriAppID       uuid.UUID
+	UserID            uuid.UUID
+	ExpertiseProofs   []string
+	IdentityProof     string
+	InitialSubmission string
+	Status            sql.NullString
+	CreatedAt         sql.NullTime
+	ReviewedAt        sql.NullTime
+	RequestedFields   []string
+	ReviewerNotes     string
+	UpdatedAt         time.Time
+}
+
+func (q *Queries) ResubmitContributorApplication(ctx context.Context, arg ResubmitContributorApplicationParams) (ResubmitContributorApplicationRow, error) {
+	row := q.db.QueryRowContext(ctx, resubmitContributorApplication,
+		arg.ContriAppID,
+		pq.Array(arg.ExpertiseProofs),
+		arg.IdentityProof,
+		arg.InitialSubmission,
+		pq.Array(arg.RequestedFields),
+	)
+	var i ResubmitContributorApplicationRow
+	err := row.Scan(
+		&i.ContriAppID,
+		&i.UserID,
+		pq.Array(&i.ExpertiseProofs),
+		&i.IdentityProof,
+		&i.InitialSubmission,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ReviewedAt,
+		pq.Array(&i.RequestedFields),
+		&i.ReviewerNotes,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateContributorApplication = `-- name: UpdateContributorApplication :one
+UPDATE contributor_applications
+SET
+    status = $1,
+    reviewed_at = CASE WHEN $1 = 'under_review' THEN reviewed_at ELSE CURRENT_TIMESTAMP END,
+    reviewed_by = $2,
+    reviewer_notes = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE contri_app_id = $4
+AND status = $5
+RETURNING contri_app_id, user_id, expertise_proofs, identity_proof, initial_submission, status, created_at, reviewed_at, requested_fields, reviewer_notes, updated_at
 `
 
 type UpdateContributorApplicationParams struct {
-	ContriAppID uuid.UUID
-	Status      sql.NullString
-	ReviewedBy  uuid.NullUUID
+	Status        sql.NullString
+	ReviewedBy    uuid.NullUUID
+	ReviewerNotes string
+	ContriAppID   uuid.UUID
+	FromStatus    sql.NullString
 }
 
 type UpdateContributorApplicationRow struct {
@@ -216,10 +381,19 @@ type UpdateContributorApplicationRow struct {
 	Status            sql.NullString
 	CreatedAt         sql.NullTime
 	ReviewedAt        sql.NullTime
+	RequestedFields   []string
+	ReviewerNotes     string
+	UpdatedAt         time.Time
 }
 
 func (q *Queries) UpdateContributorApplication(ctx context.Context, arg UpdateContributorApplicationParams) (UpdateContributorApplicationRow, error) {
-	row := q.db.QueryRowContext(ctx, updateContributorApplication, arg.ContriAppID, arg.Status, arg.ReviewedBy)
+	row := q.db.QueryRowContext(ctx, updateContributorApplication,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.ReviewerNotes,
+		arg.ContriAppID,
+		arg.FromStatus,
+	)
 	var i UpdateContributorApplicationRow
 	err := row.Scan(
 		&i.ContriAppID,
@@ -230,6 +404,9 @@ func (q *Queries) UpdateContributorApplication(ctx context.Context, arg UpdateCo
 		&i.Status,
 		&i.CreatedAt,
 		&i.ReviewedAt,
+		pq.Array(&i.RequestedFields),
+		&i.ReviewerNotes,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -8,6 +8,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -18,6 +19,7 @@ SELECT EXISTS (
     SELECT 1
     FROM Contributors
     WHERE user_id = $1
+    AND status = 'active'
 )
 `
 
@@ -36,7 +38,7 @@ INSERT INTO Contributors (
     $1,
     $2
 )
-RETURNING user_id, expertise_fields, created_at
+RETURNING user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at
 `
 
 type CreateContributorParams struct {
@@ -47,15 +49,72 @@ type CreateContributorParams struct {
 func (q *Queries) CreateContributor(ctx context.Context, arg CreateContributorParams) (Contributor, error) {
 	row := q.db.QueryRowContext(ctx, createContributor, arg.UserID, pq.Array(arg.ExpertiseFields))
 	var i Contributor
-	err := row.Scan(&i.UserID, pq.Array(&i.ExpertiseFields), &i.CreatedAt)
+	err := row.Scan(
+		&i.UserID,
+		pq.Array(&i.ExpertiseFields),
+		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+		&i.ExpiresAt,
+		&i.RenewalRemindedAt,
+	)
 	return i, err
 }
 
+const createContributorStatusHistory = `-- name: CreateContributorStatusHistory :exec
+INSERT INTO contributor_status_history (
+    history_id,
+    user_id,
+    from_status,
+    to_status,
+    reason,
+    changed_by,
+    suspended_until,
+    expires_at,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+`
+
+type CreateContributorStatusHistoryParams struct {
+	HistoryID      uuid.UUID
+	UserID         uuid.UUID
+	FromStatus     string
+	ToStatus       string
+	Reason         string
+	ChangedBy      uuid.NullUUID
+	SuspendedUntil sql.NullTime
+	ExpiresAt      sql.NullTime
+	CreatedAt      time.Time
+}
+
+func (q *Queries) CreateContributorStatusHistory(ctx context.Context, arg CreateContributorStatusHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, createContributorStatusHistory,
+		arg.HistoryID,
+		arg.UserID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.Reason,
+		arg.ChangedBy,
+		arg.SuspendedUntil,
+		arg.ExpiresAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
 const getContributorByUserId = `-- name: GetContributorByUserId :one
 SELECT 
     user_id,
     expertise_fields,
-    created_at
+    created_at,
+    status,
+    status_reason,
+    suspended_until,
+    expires_at,
+    renewal_reminded_at
 FROM Contributors
 WHERE user_id = $1
 `
@@ -63,7 +122,16 @@ WHERE user_id = $1
 func (q *Queries) GetContributorByUserId(ctx context.Context, userID uuid.UUID) (Contributor, error) {
 	row := q.db.QueryRowContext(ctx, getContributorByUserId, userID)
 	var i Contributor
-	err := row.Scan(&i.UserID, pq.Array(&i.ExpertiseFields), &i.CreatedAt)
+	err := row.Scan(
+		&i.UserID,
+		pq.Array(&i.ExpertiseFields),
+		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+		&i.ExpiresAt,
+		&i.RenewalRemindedAt,
+	)
 	return i, err
 }
 
@@ -130,3 +198,267 @@ func (q *Queries) GetPostsByContributor(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const listContributorStatusHistory = `-- name: ListContributorStatusHistory :many
+SELECT
+    h.history_id,
+    h.from_status,
+    h.to_status,
+    h.reason,
+    h.changed_by,
+    m.name AS changed_by_name,
+    h.suspended_until,
+    h.expires_at,
+    h.created_at
+FROM contributor_status_history h
+LEFT JOIN moderators m ON h.changed_by = m.moderator_id
+WHERE h.user_id = $1
+ORDER BY h.created_at DESC
+`
+
+type ListContributorStatusHistoryRow struct {
+	HistoryID      uuid.UUID
+	FromStatus     string
+	ToStatus       string
+	Reason         string
+	ChangedBy      uuid.NullUUID
+	ChangedByName  sql.NullString
+	SuspendedUntil sql.NullTime
+	ExpiresAt      sql.NullTime
+	CreatedAt      time.Time
+}
+
+func (q *Queries) ListContributorStatusHistory(ctx context.Context, userID uuid.UUID) ([]ListContributorStatusHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listContributorStatusHistory, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListContributorStatusHistoryRow
+	for rows.Next() {
+		var i ListContributorStatusHistoryRow
+		if err := rows.Scan(
+			&i.HistoryID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.Reason,
+			&i.ChangedBy,
+			&i.ChangedByName,
+			&i.SuspendedUntil,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContributorsDueRenewalReminder = `-- name: ListContributorsDueRenewalReminder :many
+SELECT user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at FROM contributors
+WHERE status = 'active'
+AND renewal_reminded_at IS NULL
+AND expires_at <= $1
+`
+
+func (q *Queries) ListContributorsDueRenewalReminder(ctx context.Context, remindBefore sql.NullTime) ([]Contributor, error) {
+	rows, err := q.db.QueryContext(ctx, listContributorsDueRenewalReminder, remindBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Contributor
+	for rows.Next() {
+		var i Contributor
+		if err := rows.Scan(
+			&i.UserID,
+			pq.Array(&i.ExpertiseFields),
+			&i.CreatedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.SuspendedUntil,
+			&i.ExpiresAt,
+			&i.RenewalRemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEndedContributorSuspensions = `-- name: ListEndedContributorSuspensions :many
+SELECT user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at FROM contributors
+WHERE status = 'suspended'
+AND suspended_until <= $1
+`
+
+func (q *Queries) ListEndedContributorSuspensions(ctx context.Context, now sql.NullTime) ([]Contributor, error) {
+	rows, err := q.db.QueryContext(ctx, listEndedContributorSuspensions, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Contributor
+	for rows.Next() {
+		var i Contributor
+		if err := rows.Scan(
+			&i.UserID,
+			pq.Array(&i.ExpertiseFields),
+			&i.CreatedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.SuspendedUntil,
+			&i.ExpiresAt,
+			&i.RenewalRemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLapsedContributors = `-- name: ListLapsedContributors :many
+SELECT user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at FROM contributors
+WHERE status = 'active'
+AND expires_at <= $1
+`
+
+func (q *Queries) ListLapsedContributors(ctx context.Context, now sql.NullTime) ([]Contributor, error) {
+	rows, err := q.db.QueryContext(ctx, listLapsedContributors, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Contributor
+	for rows.Next() {
+		var i Contributor
+		if err := rows.Scan(
+			&i.UserID,
+			pq.Array(&i.ExpertiseFields),
+			&i.CreatedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.SuspendedUntil,
+			&i.ExpiresAt,
+			&i.RenewalRemindedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markContributorRenewalReminded = `-- name: MarkContributorRenewalReminded :exec
+UPDATE contributors
+SET renewal_reminded_at = $2
+WHERE user_id = $1
+`
+
+type MarkContributorRenewalRemindedParams struct {
+	UserID            uuid.UUID
+	RenewalRemindedAt sql.NullTime
+}
+
+func (q *Queries) MarkContributorRenewalReminded(ctx context.Context, arg MarkContributorRenewalRemindedParams) error {
+	_, err := q.db.ExecContext(ctx, markContributorRenewalReminded, arg.UserID, arg.RenewalRemindedAt)
+	return err
+}
+
+const setContributorExpiry = `-- name: SetContributorExpiry :one
+UPDATE contributors
+SET
+    expires_at = $2,
+    renewal_reminded_at = NULL
+WHERE user_id = $1
+RETURNING user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at
+`
+
+type SetContributorExpiryParams struct {
+	UserID    uuid.UUID
+	ExpiresAt sql.NullTime
+}
+
+func (q *Queries) SetContributorExpiry(ctx context.Context, arg SetContributorExpiryParams) (Contributor, error) {
+	row := q.db.QueryRowContext(ctx, setContributorExpiry, arg.UserID, arg.ExpiresAt)
+	var i Contributor
+	err := row.Scan(
+		&i.UserID,
+		pq.Array(&i.ExpertiseFields),
+		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+		&i.ExpiresAt,
+		&i.RenewalRemindedAt,
+	)
+	return i, err
+}
+
+const setContributorStatus = `-- name: SetContributorStatus :one
+UPDATE contributors
+SET
+    status = $1,
+    status_reason = $2,
+    suspended_until = $3
+WHERE user_id = $4
+AND status = $5
+RETURNING user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at
+`
+
+type SetContributorStatusParams struct {
+	Status         string
+	StatusReason   string
+	SuspendedUntil sql.NullTime
+	UserID         uuid.UUID
+	FromStatus     string
+}
+
+func (q *Queries) SetContributorStatus(ctx context.Context, arg SetContributorStatusParams) (Contributor, error) {
+	row := q.db.QueryRowContext(ctx, setContributorStatus,
+		arg.Status,
+		arg.StatusReason,
+		arg.SuspendedUntil,
+		arg.UserID,
+		arg.FromStatus,
+	)
+	var i Contributor
+	err := row.Scan(
+		&i.UserID,
+		pq.Array(&i.ExpertiseFields),
+		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+		&i.ExpiresAt,
+		&i.RenewalRemindedAt,
+	)
+	return i, err
+}
@@ -10,12 +10,14 @@ import (
 	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-const createFollow = `-- name: CreateFollow :exec
+const createFollow = `-- name: CreateFollow :one
 INSERT INTO following (follower_id, following_id)
 VALUES ($1, $2)
 ON CONFLICT DO NOTHING
+RETURNING follower_id, following_id, created_at
 `
 
 type CreateFollowParams struct {
@@ -23,12 +25,14 @@ type CreateFollowParams struct {
 	FollowingID uuid.UUID
 }
 
-func (q *Queries) CreateFollow(ctx context.Context, arg CreateFollowParams) error {
-	_, err := q.db.ExecContext(ctx, createFollow, arg.FollowerID, arg.FollowingID)
-	return err
+func (q *Queries) CreateFollow(ctx context.Context, arg CreateFollowParams) (Following, error) {
+	row := q.db.QueryRowContext(ctx, createFollow, arg.FollowerID, arg.FollowingID)
+	var i Following
+	err := row.Scan(&i.FollowerID, &i.FollowingID, &i.CreatedAt)
+	return i, err
 }
 
-const deleteFollow = `-- name: DeleteFollow :exec
+const deleteFollow = `-- name: DeleteFollow :execrows
 DELETE FROM following
 WHERE follower_id = $1 AND following_id = $2
 `
@@ -38,9 +42,12 @@ type DeleteFollowParams struct {
 	FollowingID uuid.UUID
 }
 
-func (q *Queries) DeleteFollow(ctx context.Context, arg DeleteFollowParams) error {
-	_, err := q.db.ExecContext(ctx, deleteFollow, arg.FollowerID, arg.FollowingID)
-	return err
+func (q *Queries) DeleteFollow(ctx context.Context, arg DeleteFollowParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFollow, arg.FollowerID, arg.FollowingID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const getFeed = `-- name: GetFeed :many
@@ -51,6 +58,10 @@ FROM posts
 JOIN following ON posts.user_id = following.following_id
 JOIN users ON posts.user_id = users.user_id
 WHERE following.follower_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM content_takedowns t
+    WHERE t.post_id = posts.post_id AND t.restored_at IS NULL AND t.action <> 'label'
+)
 ORDER BY posts.created_at DESC
 `
 
@@ -123,6 +134,55 @@ func (q *Queries) GetFollowStatus(ctx context.Context, arg GetFollowStatusParams
 	return exists, err
 }
 
+const getFollowStatuses = `-- name: GetFollowStatuses :many
+SELECT 
+    users.user_id,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = $1::uuid AND v.following_id = users.user_id
+    ) AS is_following,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = users.user_id AND v.following_id = $1::uuid
+    ) AS follows_you
+FROM users
+WHERE users.user_id = ANY($2::uuid[])
+`
+
+type GetFollowStatusesParams struct {
+	ViewerID uuid.UUID
+	UserIds  []uuid.UUID
+}
+
+type GetFollowStatusesRow struct {
+	UserID      uuid.UUID
+	IsFollowing bool
+	FollowsYou  bool
+}
+
+func (q *Queries) GetFollowStatuses(ctx context.Context, arg GetFollowStatusesParams) ([]GetFollowStatusesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowStatuses, arg.ViewerID, pq.Array(arg.UserIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFollowStatusesRow
+	for rows.Next() {
+		var i GetFollowStatusesRow
+		if err := rows.Scan(&i.UserID, &i.IsFollowing, &i.FollowsYou); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFollowingCount = `-- name: GetFollowingCount :one
 SELECT COUNT(following_id)
 FROM following
@@ -187,3 +247,141 @@ func (q *Queries) GetFollwersCount(ctx context.Context, followingID uuid.UUID) (
 	err := row.Scan(&count)
 	return count, err
 }
+
+const listFollowers = `-- name: ListFollowers :many
+SELECT 
+    following.follower_id,
+    users.name,
+    users.username,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = $1::uuid AND v.following_id = following.follower_id
+    ) AS is_following,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = following.follower_id AND v.following_id = $1::uuid
+    ) AS follows_you
+FROM following
+JOIN users ON following.follower_id = users.user_id
+WHERE following.following_id = $2::uuid
+ORDER BY users.username
+LIMIT $3 OFFSET $4
+`
+
+type ListFollowersParams struct {
+	ViewerID   uuid.UUID
+	UserID     uuid.UUID
+	PageLimit  int32
+	PageOffset int32
+}
+
+type ListFollowersRow struct {
+	FollowerID  uuid.UUID
+	Name        string
+	Username    string
+	IsFollowing bool
+	FollowsYou  bool
+}
+
+func (q *Queries) ListFollowers(ctx context.Context, arg ListFollowersParams) ([]ListFollowersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFollowers,
+		arg.ViewerID,
+		arg.UserID,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFollowersRow
+	for rows.Next() {
+		var i ListFollowersRow
+		if err := rows.Scan(
+			&i.FollowerID,
+			&i.Name,
+			&i.Username,
+			&i.IsFollowing,
+			&i.FollowsYou,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFollowing = `-- name: ListFollowing :many
+SELECT 
+    following.following_id,
+    users.name,
+    users.username,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = $1::uuid AND v.following_id = following.following_id
+    ) AS is_following,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = following.following_id AND v.following_id = $1::uuid
+    ) AS follows_you
+FROM following
+JOIN users ON following.following_id = users.user_id
+WHERE following.follower_id = $2::uuid
+ORDER BY users.username
+LIMIT $3 OFFSET $4
+`
+
+type ListFollowingParams struct {
+	ViewerID   uuid.UUID
+	UserID     uuid.UUID
+	PageLimit  int32
+	PageOffset int32
+}
+
+type ListFollowingRow struct {
+	FollowingID uuid.UUID
+	Name        string
+	Username    string
+	IsFollowing bool
+	FollowsYou  bool
+}
+
+func (q *Queries) ListFollowing(ctx context.Context, arg ListFollowingParams) ([]ListFollowingRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFollowing,
+		arg.ViewerID,
+		arg.UserID,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFollowingRow
+	for rows.Next() {
+		var i ListFollowingRow
+		if err := rows.Scan(
+			&i.FollowingID,
+			&i.Name,
+			&i.Username,
+			&i.IsFollowing,
+			&i.FollowsYou,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
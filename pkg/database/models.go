@@ -6,11 +6,32 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AccountDeletion struct {
+	UserID       uuid.UUID
+	RequestedAt  time.Time
+	ScheduledFor time.Time
+}
+
+type ApiKey struct {
+	ApiKeyID    uuid.UUID
+	UserID      uuid.NullUUID
+	ModeratorID uuid.NullUUID
+	Name        string
+	KeyPrefix   string
+	KeyHash     string
+	Scopes      []string
+	ExpiresAt   sql.NullTime
+	LastUsedAt  sql.NullTime
+	RevokedAt   sql.NullTime
+	CreatedAt   time.Time
+}
+
 type Appeal struct {
 	AppealID       uuid.UUID
 	AppealedBy     uuid.UUID
@@ -22,6 +43,25 @@ type Appeal struct {
 	CreatedAt      sql.NullTime
 }
 
+type ApplicationDocument struct {
+	DocumentID    uuid.UUID
+	UserID        uuid.NullUUID
+	ApplicationID uuid.NullUUID
+	Kind          string
+	StorageKey    string
+	ContentType   string
+	SizeBytes     int64
+	OriginalName  string
+	CreatedAt     time.Time
+	DeleteAfter   sql.NullTime
+}
+
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
 type Comment struct {
 	CommentID       uuid.UUID
 	PostID          uuid.UUID
@@ -32,10 +72,28 @@ type Comment struct {
 	UpdatedAt       time.Time
 }
 
+type ContentTakedown struct {
+	TakedownID  uuid.UUID
+	CaseID      uuid.UUID
+	PostID      uuid.NullUUID
+	CommentID   uuid.NullUUID
+	Action      string
+	Label       string
+	TakenDownBy uuid.UUID
+	CreatedAt   time.Time
+	RestoredBy  uuid.NullUUID
+	RestoredAt  sql.NullTime
+}
+
 type Contributor struct {
-	UserID          uuid.UUID
-	ExpertiseFields []string
-	CreatedAt       sql.NullTime
+	UserID            uuid.UUID
+	ExpertiseFields   []string
+	CreatedAt         sql.NullTime
+	Status            string
+	StatusReason      string
+	SuspendedUntil    sql.NullTime
+	ExpiresAt         sql.NullTime
+	RenewalRemindedAt sql.NullTime
 }
 
 type ContributorApplication struct {
@@ -48,11 +106,103 @@ type ContributorApplication struct {
 	Status            sql.NullString
 	CreatedAt         sql.NullTime
 	ReviewedAt        sql.NullTime
+	RequestedFields   []string
+	ReviewerNotes     string
+	UpdatedAt         time.Time
+}
+
+type ContributorExpertise struct {
+	UserID            uuid.UUID
+	Field             string
+	VerificationLevel string
+	ApplicationID     uuid.NullUUID
+	VerifiedBy        uuid.NullUUID
+	VerifiedAt        time.Time
+	ExpiresAt         sql.NullTime
+}
+
+type ContributorStatusHistory struct {
+	HistoryID      uuid.UUID
+	UserID         uuid.UUID
+	FromStatus     string
+	ToStatus       string
+	Reason         string
+	ChangedBy      uuid.NullUUID
+	SuspendedUntil sql.NullTime
+	ExpiresAt      sql.NullTime
+	CreatedAt      time.Time
+}
+
+type EnforcementPolicy struct {
+	ID               bool
+	StrikeExpiryDays int32
+	Levels           json.RawMessage
+	UpdatedBy        uuid.NullUUID
+	UpdatedAt        time.Time
+}
+
+type ExpertiseField struct {
+	Slug      string
+	Name      string
+	Category  string
+	CreatedAt time.Time
+}
+
+type FollowSuggestion struct {
+	UserID          uuid.UUID
+	SuggestedUserID uuid.UUID
+	Score           float64
+	MutualFollows   int32
+	SharedExpertise int32
+	ComputedAt      time.Time
 }
 
 type Following struct {
 	FollowerID  uuid.UUID
 	FollowingID uuid.UUID
+	CreatedAt   time.Time
+}
+
+type Impersonation struct {
+	ImpersonationID uuid.UUID
+	ModeratorID     uuid.UUID
+	UserID          uuid.UUID
+	Reason          string
+	ReadOnly        bool
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+	EndedAt         sql.NullTime
+}
+
+type ImpersonationRequest struct {
+	RequestID       uuid.UUID
+	ImpersonationID uuid.UUID
+	Method          string
+	Path            string
+	StatusCode      int32
+	CreatedAt       time.Time
+}
+
+type ModerationAction struct {
+	ActionID      uuid.UUID
+	ModeratorID   uuid.NullUUID
+	Action        string
+	TargetType    string
+	TargetID      uuid.UUID
+	SubjectUserID uuid.NullUUID
+	BeforeState   json.RawMessage
+	AfterState    json.RawMessage
+	Reason        string
+	CreatedAt     time.Time
+}
+
+type ModerationClaim struct {
+	ItemType   string
+	ItemID     uuid.UUID
+	ClaimedBy  uuid.UUID
+	AssignedBy uuid.NullUUID
+	ClaimedAt  time.Time
+	ExpiresAt  time.Time
 }
 
 type Moderator struct {
@@ -66,6 +216,22 @@ type Moderator struct {
 	UpdatedAt   sql.NullTime
 }
 
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type Notification struct {
+	NotificationID uuid.UUID
+	UserID         uuid.UUID
+	Type           string
+	Message        string
+	Data           json.RawMessage
+	ReadAt         sql.NullTime
+	CreatedAt      time.Time
+}
+
 type Post struct {
 	PostID    uuid.UUID
 	Title     string
@@ -76,6 +242,34 @@ type Post struct {
 	UpdatedAt sql.NullTime
 }
 
+type PostTag struct {
+	PostID uuid.UUID
+	Tag    string
+}
+
+type PrivacySetting struct {
+	UserID               uuid.UUID
+	SavedPostsVisibility string
+	FollowsVisibility    string
+	EmailVisibility      string
+	UpdatedAt            time.Time
+}
+
+type RelatedPost struct {
+	PostID         uuid.UUID
+	RelatedPostID  uuid.UUID
+	Score          float64
+	SharedTags     int32
+	SameAuthor     bool
+	CoUpvotes      int32
+	TextSimilarity float64
+}
+
+type RelatedPostsCache struct {
+	PostID     uuid.UUID
+	ComputedAt time.Time
+}
+
 type Report struct {
 	ReportID        uuid.UUID
 	ReportedBy      uuid.UUID
@@ -88,6 +282,53 @@ type Report struct {
 	Reviewedby      uuid.NullUUID
 	CreatedAt       sql.NullTime
 	SuspendDays     sql.NullInt32
+	CaseID          uuid.UUID
+	ReasonCode      string
+}
+
+type ReportCase struct {
+	CaseID          uuid.UUID
+	TargetKey       string
+	TargetType      string
+	TargetUserID    uuid.UUID
+	TargetPostID    uuid.NullUUID
+	TargetCommentID uuid.NullUUID
+	Status          string
+	ResolvedBy      uuid.NullUUID
+	ResolvedAt      sql.NullTime
+	SuspendDays     sql.NullInt32
+	CreatedAt       time.Time
+	LastReportedAt  time.Time
+}
+
+type ReportReason struct {
+	Code        string
+	Label       string
+	Description string
+	Severity    int32
+	Active      bool
+	SortOrder   int32
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type ReputationLedger struct {
+	SourceKey  string
+	UserID     uuid.UUID
+	Reason     string
+	Points     int32
+	PostID     uuid.NullUUID
+	OccurredAt time.Time
+	RecordedAt time.Time
+}
+
+type ReputationSource struct {
+	SourceKey  interface{}
+	UserID     uuid.UUID
+	Reason     string
+	Points     int32
+	PostID     uuid.UUID
+	OccurredAt sql.NullTime
 }
 
 type SavedPost struct {
@@ -96,6 +337,55 @@ type SavedPost struct {
 	CreatedAt sql.NullTime
 }
 
+type Session struct {
+	SessionID                uuid.UUID
+	UserID                   uuid.NullUUID
+	ModeratorID              uuid.NullUUID
+	RefreshTokenHash         string
+	UserAgent                string
+	Device                   string
+	IpAddress                string
+	Location                 sql.NullString
+	CreatedAt                time.Time
+	LastActiveAt             time.Time
+	ExpiresAt                time.Time
+	RevokedAt                sql.NullTime
+	PreviousRefreshTokenHash sql.NullString
+	RotatedAt                sql.NullTime
+}
+
+type Strike struct {
+	StrikeID    uuid.UUID
+	UserID      uuid.UUID
+	CaseID      uuid.UUID
+	IssuedBy    uuid.UUID
+	Penalty     string
+	SuspendDays int32
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	RevokedBy   uuid.NullUUID
+	RevokedAt   sql.NullTime
+}
+
+type Suspension struct {
+	SuspensionID   uuid.UUID
+	UserID         uuid.UUID
+	CaseID         uuid.NullUUID
+	ReportID       uuid.NullUUID
+	IssuedBy       uuid.NullUUID
+	StartsAt       time.Time
+	EndsAt         sql.NullTime
+	Reason         string
+	LiftedBy       uuid.NullUUID
+	LiftedByAppeal uuid.NullUUID
+	LiftedAt       sql.NullTime
+}
+
+type UpgradedRefreshToken struct {
+	TokenHash  string
+	UpgradedAt time.Time
+}
+
 type Upvote struct {
 	UserID    uuid.UUID
 	PostID    uuid.UUID
@@ -112,3 +402,33 @@ type User struct {
 	CreatedAt      sql.NullTime
 	UpdatedAt      sql.NullTime
 }
+
+type UserBadge struct {
+	UserID    uuid.UUID
+	Badge     string
+	Field     string
+	AwardedAt time.Time
+}
+
+type UserIdentity struct {
+	IdentityID    uuid.UUID
+	UserID        uuid.UUID
+	Provider      string
+	Subject       string
+	Email         sql.NullString
+	EmailVerified bool
+	CreatedAt     time.Time
+	LastLoginAt   sql.NullTime
+}
+
+type UserProfile struct {
+	UserID      uuid.UUID
+	Bio         string
+	Headline    string
+	Location    string
+	Website     string
+	Pronouns    string
+	AvatarUrl   string
+	SocialLinks json.RawMessage
+	UpdatedAt   time.Time
+}
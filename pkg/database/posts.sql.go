@@ -10,6 +10,7 @@ import (
 	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createPost = `-- name: CreatePost :one
@@ -176,7 +177,14 @@ SELECT
     u.name AS author_name,
     u.username AS author_username,
     COALESCE(upvote_counts.count, 0) AS upvote_count,
-    COALESCE(comment_counts.count, 0) AS comment_count
+    COALESCE(comment_counts.count, 0) AS comment_count,
+    ARRAY(
+        SELECT ce.field
+        FROM contributor_expertise ce
+        WHERE ce.user_id = p.user_id
+        AND (ce.expires_at IS NULL OR ce.expires_at > $2)
+        ORDER BY ce.field
+    )::text[] AS author_verified_fields
 FROM posts p
 JOIN users u ON p.user_id = u.user_id
 LEFT JOIN (
@@ -196,22 +204,28 @@ LEFT JOIN (
 WHERE p.post_id = $1
 `
 
+type GetPostDetailsByIDParams struct {
+	PostID    uuid.UUID
+	ExpiresAt sql.NullTime
+}
+
 type GetPostDetailsByIDRow struct {
-	PostID         uuid.UUID
-	UserID         uuid.UUID
-	Slug           string
-	Title          string
-	Content        string
-	CreatedAt      sql.NullTime
-	UpdatedAt      sql.NullTime
-	AuthorName     string
-	AuthorUsername string
-	UpvoteCount    int64
-	CommentCount   int64
+	PostID               uuid.UUID
+	UserID               uuid.UUID
+	Slug                 string
+	Title                string
+	Content              string
+	CreatedAt            sql.NullTime
+	UpdatedAt            sql.NullTime
+	AuthorName           string
+	AuthorUsername       string
+	UpvoteCount          int64
+	CommentCount         int64
+	AuthorVerifiedFields []string
 }
 
-func (q *Queries) GetPostDetailsByID(ctx context.Context, postID uuid.UUID) (GetPostDetailsByIDRow, error) {
-	row := q.db.QueryRowContext(ctx, getPostDetailsByID, postID)
+func (q *Queries) GetPostDetailsByID(ctx context.Context, arg GetPostDetailsByIDParams) (GetPostDetailsByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostDetailsByID, arg.PostID, arg.ExpiresAt)
 	var i GetPostDetailsByIDRow
 	err := row.Scan(
 		&i.PostID,
@@ -225,6 +239,7 @@ func (q *Queries) GetPostDetailsByID(ctx context.Context, postID uuid.UUID) (Get
 		&i.AuthorUsername,
 		&i.UpvoteCount,
 		&i.CommentCount,
+		pq.Array(&i.AuthorVerifiedFields),
 	)
 	return i, err
 }
@@ -253,7 +268,14 @@ SELECT
         FROM saved_posts 
         WHERE saved_posts.post_id = p.post_id  -- Prefix ` + "`" + `post_id` + "`" + ` with table alias
         AND saved_posts.user_id = $2            -- Prefix ` + "`" + `user_id` + "`" + ` with table alias
-    ) AS has_saved
+    ) AS has_saved,
+    ARRAY(
+        SELECT ce.field
+        FROM contributor_expertise ce
+        WHERE ce.user_id = p.user_id
+        AND (ce.expires_at IS NULL OR ce.expires_at > $3)
+        ORDER BY ce.field
+    )::text[] AS author_verified_fields
 FROM posts p
 JOIN users u ON p.user_id = u.user_id
 LEFT JOIN (
@@ -274,28 +296,30 @@ WHERE p.post_id = $1
 `
 
 type GetPostDetailsForUsersByIDParams struct {
-	PostID uuid.UUID
-	UserID uuid.UUID
+	PostID    uuid.UUID
+	UserID    uuid.UUID
+	ExpiresAt sql.NullTime
 }
 
 type GetPostDetailsForUsersByIDRow struct {
-	PostID         uuid.UUID
-	UserID         uuid.UUID
-	Slug           string
-	Title          string
-	Content        string
-	CreatedAt      sql.NullTime
-	UpdatedAt      sql.NullTime
-	AuthorName     string
-	AuthorUsername string
-	UpvoteCount    int64
-	CommentCount   int64
-	HasUpvoted     bool
-	HasSaved       bool
+	PostID               uuid.UUID
+	UserID               uuid.UUID
+	Slug                 string
+	Title                string
+	Content              string
+	CreatedAt            sql.NullTime
+	UpdatedAt            sql.NullTime
+	AuthorName           string
+	AuthorUsername       string
+	UpvoteCount          int64
+	CommentCount         int64
+	HasUpvoted           bool
+	HasSaved             bool
+	AuthorVerifiedFields []string
 }
 
 func (q *Queries) GetPostDetailsForUsersByID(ctx context.Context, arg GetPostDetailsForUsersByIDParams) (GetPostDetailsForUsersByIDRow, error) {
-	row := q.db.QueryRowContext(ctx, getPostDetailsForUsersByID, arg.PostID, arg.UserID)
+	row := q.db.QueryRowContext(ctx, getPostDetailsForUsersByID, arg.PostID, arg.UserID, arg.ExpiresAt)
 	var i GetPostDetailsForUsersByIDRow
 	err := row.Scan(
 		&i.PostID,
@@ -311,6 +335,7 @@ func (q *Queries) GetPostDetailsForUsersByID(ctx context.Context, arg GetPostDet
 		&i.CommentCount,
 		&i.HasUpvoted,
 		&i.HasSaved,
+		pq.Array(&i.AuthorVerifiedFields),
 	)
 	return i, err
 }
@@ -342,6 +367,10 @@ LEFT JOIN (
     GROUP BY post_id
 ) upvote_counts ON p.post_id = upvote_counts.post_id
 WHERE p.created_at >= NOW() - INTERVAL '2 years'  -- Get posts from last 2 years
+AND NOT EXISTS (
+    SELECT 1 FROM content_takedowns t
+    WHERE t.post_id = p.post_id AND t.restored_at IS NULL AND t.action <> 'label'
+)
 ORDER BY 
     (COALESCE(upvote_counts.count, 0) * 2 + COALESCE(comment_counts.count, 0)) DESC, -- Weight upvotes & comments
     p.created_at DESC -- Prioritize newer posts if engagement is similar
@@ -407,6 +436,10 @@ SELECT
 FROM posts p
 JOIN users u ON p.user_id = u.user_id
 WHERE p.title ILIKE '%' || $1 || '%'
+AND NOT EXISTS (
+    SELECT 1 FROM content_takedowns t
+    WHERE t.post_id = p.post_id AND t.restored_at IS NULL AND t.action <> 'label'
+)
 ORDER BY 
     p.created_at DESC -- Prioritize newer posts
 LIMIT 20
@@ -13,7 +13,9 @@ import (
 )
 
 const createReport = `-- name: CreateReport :one
-INSERT INTO reports (report_id, reported_by, target_post_id, target_user_id, target_comment_id, reason) VALUES ($1, $2, $3, $4, $5, $6) RETURNING report_id, reported_by, target_post_id, target_user_id, target_comment_id, reason, status, reviewed_at, reviewedby, created_at, suspend_days
+INSERT INTO reports (report_id, reported_by, target_post_id, target_user_id, target_comment_id, reason, case_id, reason_code) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (case_id, reported_by) WHERE status = 'pending' DO NOTHING
+RETURNING report_id, reported_by, target_post_id, target_user_id, target_comment_id, reason, status, reviewed_at, reviewedby, created_at, suspend_days, case_id, reason_code
 `
 
 type CreateReportParams struct {
@@ -23,6 +25,8 @@ type CreateReportParams struct {
 	TargetUserID    uuid.UUID
 	TargetCommentID uuid.NullUUID
 	Reason          string
+	CaseID          uuid.UUID
+	ReasonCode      string
 }
 
 func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Report, error) {
@@ -33,6 +37,8 @@ func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Rep
 		arg.TargetUserID,
 		arg.TargetCommentID,
 		arg.Reason,
+		arg.CaseID,
+		arg.ReasonCode,
 	)
 	var i Report
 	err := row.Scan(
@@ -47,6 +53,8 @@ func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Rep
 		&i.Reviewedby,
 		&i.CreatedAt,
 		&i.SuspendDays,
+		&i.CaseID,
+		&i.ReasonCode,
 	)
 	return i, err
 }
@@ -61,7 +69,8 @@ SELECT
     r.reviewed_at,
     r.reviewedby,
     r.created_at,
-r.suspend_days
+r.suspend_days,
+    r.case_id
 FROM reports r
 WHERE r.report_id = $1
 `
@@ -76,6 +85,7 @@ type GetReportByIdRow struct {
 	Reviewedby   uuid.NullUUID
 	CreatedAt    sql.NullTime
 	SuspendDays  sql.NullInt32
+	CaseID       uuid.UUID
 }
 
 func (q *Queries) GetReportById(ctx context.Context, reportID uuid.UUID) (GetReportByIdRow, error) {
@@ -91,6 +101,7 @@ func (q *Queries) GetReportById(ctx context.Context, reportID uuid.UUID) (GetRep
 		&i.Reviewedby,
 		&i.CreatedAt,
 		&i.SuspendDays,
+		&i.CaseID,
 	)
 	return i, err
 }
@@ -211,6 +222,9 @@ SELECT
     r.reviewed_at,
     r.reviewedby,
     r.created_at,
+    r.reason_code,
+    rr.label AS reason_label,
+    rr.severity,
 
     -- Reporter Details
     ru.user_id AS reported_by_id,
@@ -241,8 +255,9 @@ INNER JOIN contributors ct ON tu.user_id = ct.user_id
 LEFT JOIN posts p ON r.target_post_id = p.post_id
 LEFT JOIN comments c ON r.target_comment_id = c.comment_id  
 LEFT JOIN moderators m ON r.reviewedby = m.moderator_id
+JOIN report_reasons rr ON r.reason_code = rr.code
 WHERE r.target_user_id IS NOT NULL
-ORDER BY r.created_at DESC
+ORDER BY (r.status = 'pending') DESC, rr.severity DESC, r.created_at DESC
 `
 
 type ListReportedContributorsRow struct {
@@ -254,6 +269,9 @@ type ListReportedContributorsRow struct {
 	ReviewedAt          sql.NullTime
 	Reviewedby          uuid.NullUUID
 	CreatedAt           sql.NullTime
+	ReasonCode          string
+	ReasonLabel         string
+	Severity            int32
 	ReportedByID        uuid.NullUUID
 	ReportedByName      sql.NullString
 	ReportedByUsername  sql.NullString
@@ -286,6 +304,9 @@ func (q *Queries) ListReportedContributors(ctx context.Context) ([]ListReportedC
 			&i.ReviewedAt,
 			&i.Reviewedby,
 			&i.CreatedAt,
+			&i.ReasonCode,
+			&i.ReasonLabel,
+			&i.Severity,
 			&i.ReportedByID,
 			&i.ReportedByName,
 			&i.ReportedByUsername,
@@ -322,6 +343,9 @@ SELECT
     r.reviewed_at,
     r.reviewedby,
     r.created_at,
+    r.reason_code,
+    rr.label AS reason_label,
+    rr.severity,
 
     -- Reporter Details
     ru.user_id AS reported_by_id,
@@ -352,9 +376,10 @@ LEFT JOIN contributors ct ON tu.user_id = ct.user_id  -- Check if the user is a
 LEFT JOIN posts p ON r.target_post_id = p.post_id
 LEFT JOIN comments c ON r.target_comment_id = c.comment_id  
 LEFT JOIN moderators m ON r.reviewedby = m.moderator_id
+JOIN report_reasons rr ON r.reason_code = rr.code
 WHERE r.target_user_id IS NOT NULL
 AND ct.user_id IS NULL  -- Ensures reported user is NOT a contributor
-ORDER BY r.created_at DESC
+ORDER BY (r.status = 'pending') DESC, rr.severity DESC, r.created_at DESC
 `
 
 type ListReportedUsersRow struct {
@@ -366,6 +391,9 @@ type ListReportedUsersRow struct {
 	ReviewedAt          sql.NullTime
 	Reviewedby          uuid.NullUUID
 	CreatedAt           sql.NullTime
+	ReasonCode          string
+	ReasonLabel         string
+	Severity            int32
 	ReportedByID        uuid.NullUUID
 	ReportedByName      sql.NullString
 	ReportedByUsername  sql.NullString
@@ -398,6 +426,9 @@ func (q *Queries) ListReportedUsers(ctx context.Context) ([]ListReportedUsersRow
 			&i.ReviewedAt,
 			&i.Reviewedby,
 			&i.CreatedAt,
+			&i.ReasonCode,
+			&i.ReasonLabel,
+			&i.Severity,
 			&i.ReportedByID,
 			&i.ReportedByName,
 			&i.ReportedByUsername,
@@ -425,7 +456,7 @@ func (q *Queries) ListReportedUsers(ctx context.Context) ([]ListReportedUsersRow
 }
 
 const updateReportStatus = `-- name: UpdateReportStatus :one
-UPDATE reports SET status = $1, reviewedby = $2, reviewed_at = CURRENT_TIMESTAMP, suspend_days = $3 WHERE report_id = $4 RETURNING report_id, reported_by, target_post_id, target_user_id, target_comment_id, reason, status, reviewed_at, reviewedby, created_at, suspend_days
+UPDATE reports SET status = $1, reviewedby = $2, reviewed_at = CURRENT_TIMESTAMP, suspend_days = $3 WHERE report_id = $4 RETURNING report_id, reported_by, target_post_id, target_user_id, target_comment_id, reason, status, reviewed_at, reviewedby, created_at, suspend_days, case_id, reason_code
 `
 
 type UpdateReportStatusParams struct {
@@ -455,6 +486,8 @@ func (q *Queries) UpdateReportStatus(ctx context.Context, arg UpdateReportStatus
 		&i.Reviewedby,
 		&i.CreatedAt,
 		&i.SuspendDays,
+		&i.CaseID,
+		&i.ReasonCode,
 	)
 	return i, err
 }
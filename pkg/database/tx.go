@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Begin starts a transaction and returns it with queries bound to it. The
+// caller commits it, and should defer a Rollback.
+func (q *Queries) Begin(ctx context.Context) (*sql.Tx, *Queries, error) {
+	db, ok := q.db.(*sql.DB)
+	if !ok {
+		return nil, nil, errors.New("database: transactions need a *sql.DB")
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, q.WithTx(tx), nil
+}
+
+// RunInTx runs fn with queries bound to a transaction, committing if fn
+// returns nil and rolling back otherwise. Called on queries that are already
+// in a transaction, fn joins it instead.
+func (q *Queries) RunInTx(ctx context.Context, fn func(*Queries) error) error {
+	if _, ok := q.db.(*sql.Tx); ok {
+		return fn(q)
+	}
+	tx, txq, err := q.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(txq); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,244 @@
+// Package oauth wraps the social login providers configured through the
+// environment: Google and any generic OpenID Connect issuer via discovery, and
+// GitHub via plain OAuth2 plus its REST API.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+var ErrUnknownProvider = errors.New("unknown or unconfigured login provider")
+
+// Identity is what we learn about a person from a provider after they log in.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Provider struct {
+	Name     string
+	config   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	// apiURL is the GitHub REST API root; OIDC providers don't use it.
+	apiURL string
+}
+
+var (
+	providersMu sync.Mutex
+	providers   = map[string]*Provider{}
+)
+
+// Get returns the named provider, running OIDC discovery the first time it is
+// requested. Providers whose client ID isn't set are reported as unknown.
+func Get(ctx context.Context, name string) (*Provider, error) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if p, ok := providers[name]; ok {
+		return p, nil
+	}
+
+	var (
+		p   *Provider
+		err error
+	)
+	switch name {
+	case "google":
+		p, err = newOIDCProvider(ctx, name, "https://accounts.google.com", os.Getenv("GOOGLE_CLIENT_ID"), os.Getenv("GOOGLE_CLIENT_SECRET"))
+	case "oidc":
+		p, err = newOIDCProvider(ctx, name, os.Getenv("OIDC_ISSUER_URL"), os.Getenv("OIDC_CLIENT_ID"), os.Getenv("OIDC_CLIENT_SECRET"))
+	case "github":
+		p, err = newGitHubProvider(os.Getenv("GITHUB_CLIENT_ID"), os.Getenv("GITHUB_CLIENT_SECRET"),
+			envOr("GITHUB_BASE_URL", "https://github.com"), envOr("GITHUB_API_URL", "https://api.github.com"))
+	default:
+		return nil, ErrUnknownProvider
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	providers[name] = p
+	return p, nil
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func redirectURL(name string) string {
+	base := strings.TrimRight(os.Getenv("OAUTH_REDIRECT_BASE_URL"), "/")
+	return fmt.Sprintf("%s/api/auth/oauth/%s/callback", base, name)
+}
+
+func newOIDCProvider(ctx context.Context, name, issuer, clientID, clientSecret string) (*Provider, error) {
+	if issuer == "" || clientID == "" {
+		return nil, ErrUnknownProvider
+	}
+
+	discovered, err := oidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %v", issuer, err)
+	}
+
+	return &Provider{
+		Name: name,
+		config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint:     discovered.Endpoint(),
+			RedirectURL:  redirectURL(name),
+			Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+		},
+		verifier: discovered.Verifier(&oidc.Config{ClientID: clientID}),
+	}, nil
+}
+
+// newGitHubProvider logs in through the GitHub at baseURL, whose REST API is
+// at apiURL, so GitHub Enterprise Server (or a mock) can stand in for
+// github.com.
+func newGitHubProvider(clientID, clientSecret, baseURL, apiURL string) (*Provider, error) {
+	if clientID == "" {
+		return nil, ErrUnknownProvider
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	return &Provider{
+		Name: "github",
+		config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  baseURL + "/login/oauth/authorize",
+				TokenURL: baseURL + "/login/oauth/access_token",
+				// Enterprise Server takes credentials the way github.com does.
+				AuthStyle: github.Endpoint.AuthStyle,
+			},
+			RedirectURL: redirectURL("github"),
+			Scopes:      []string{"read:user", "user:email"},
+		},
+		apiURL: strings.TrimRight(apiURL, "/"),
+	}, nil
+}
+
+// AuthCodeURL builds the URL to send the browser to. state and nonce guard the
+// callback against CSRF and token replay; verifier is the PKCE secret.
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	opts := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)}
+	if p.verifier != nil {
+		opts = append(opts, oidc.Nonce(nonce))
+	}
+	return p.config.AuthCodeURL(state, opts...)
+}
+
+// Exchange trades the authorization code for tokens and returns the identity
+// of the person who logged in.
+func (p *Provider) Exchange(ctx context.Context, code, nonce, verifier string) (Identity, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to exchange code: %v", err)
+	}
+
+	if p.verifier == nil {
+		return fetchGitHubIdentity(ctx, p.config.Client(ctx, token), p.apiURL)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return Identity{}, errors.New("provider response is missing an id_token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to verify id_token: %v", err)
+	}
+	if idToken.Nonce != nonce {
+		return Identity{}, errors.New("id_token nonce mismatch")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return Identity{}, fmt.Errorf("failed to read id_token claims: %v", err)
+	}
+
+	return Identity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+func fetchGitHubIdentity(ctx context.Context, client *http.Client, apiURL string) (Identity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := getJSON(ctx, client, apiURL+"/user", &user); err != nil {
+		return Identity{}, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, apiURL+"/user/emails", &emails); err != nil {
+		return Identity{}, err
+	}
+
+	identity := Identity{
+		Subject: fmt.Sprintf("%d", user.ID),
+		Name:    user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+	return identity, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
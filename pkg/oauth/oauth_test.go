@@ -0,0 +1,197 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID = "expertly-test"
+	testCode     = "test-code"
+	testVerifier = "test-verifier-0123456789-0123456789-0123456789"
+)
+
+// mockOIDCProvider serves discovery, a JWKS publishing key and a token
+// endpoint that answers testCode with an id_token signed by signer and
+// carrying nonce.
+func mockOIDCProvider(t *testing.T, key, signer *rsa.PrivateKey, nonce string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"issuer":                                server.URL,
+			"authorization_endpoint":                server.URL + "/authorize",
+			"token_endpoint":                        server.URL + "/token",
+			"jwks_uri":                              server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != testCode || r.FormValue("code_verifier") != testVerifier {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		now := time.Now()
+		idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            server.URL,
+			"sub":            "subject-1",
+			"aud":            testClientID,
+			"iat":            now.Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+			"nonce":          nonce,
+			"email":          "ada@example.com",
+			"email_verified": true,
+			"name":           "Ada Lovelace",
+		})
+		idToken.Header["kid"] = "test"
+		signed, err := idToken.SignedString(signer)
+		if err != nil {
+			t.Errorf("failed to sign id_token: %v", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     signed,
+		})
+	})
+
+	return server
+}
+
+func TestOIDCExchange(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := mockOIDCProvider(t, key, key, "test-nonce")
+
+	ctx := context.Background()
+	p, err := newOIDCProvider(ctx, "oidc", server.URL, testClientID, "secret")
+	if err != nil {
+		t.Fatalf("newOIDCProvider: %v", err)
+	}
+
+	identity, err := p.Exchange(ctx, testCode, "test-nonce", testVerifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := Identity{Subject: "subject-1", Email: "ada@example.com", EmailVerified: true, Name: "Ada Lovelace"}
+	if identity != want {
+		t.Errorf("Exchange returned %+v, want %+v", identity, want)
+	}
+}
+
+func TestOIDCExchangeRejectsNonceMismatch(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := mockOIDCProvider(t, key, key, "replayed-nonce")
+
+	ctx := context.Background()
+	p, err := newOIDCProvider(ctx, "oidc", server.URL, testClientID, "secret")
+	if err != nil {
+		t.Fatalf("newOIDCProvider: %v", err)
+	}
+
+	if _, err := p.Exchange(ctx, testCode, "test-nonce", testVerifier); err == nil {
+		t.Error("Exchange accepted an id_token with the wrong nonce")
+	}
+}
+
+func TestOIDCExchangeRejectsForeignSigningKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := mockOIDCProvider(t, key, other, "test-nonce")
+
+	ctx := context.Background()
+	p, err := newOIDCProvider(ctx, "oidc", server.URL, testClientID, "secret")
+	if err != nil {
+		t.Fatalf("newOIDCProvider: %v", err)
+	}
+
+	if _, err := p.Exchange(ctx, testCode, "test-nonce", testVerifier); err == nil {
+		t.Error("Exchange accepted an id_token signed with an unpublished key")
+	}
+}
+
+func TestGitHubExchange(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != testCode || r.FormValue("client_id") != testClientID {
+			http.Error(w, `{"error":"bad_verification_code"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"access-token","token_type":"bearer"}`))
+	})
+	mux.HandleFunc("/api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			http.Error(w, "", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":42,"login":"ada","name":""}`))
+	})
+	mux.HandleFunc("/api/v3/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"email":"old@example.com","primary":false,"verified":true},{"email":"ada@example.com","primary":true,"verified":true}]`))
+	})
+
+	p, err := newGitHubProvider(testClientID, "secret", server.URL+"/", server.URL+"/api/v3")
+	if err != nil {
+		t.Fatalf("newGitHubProvider: %v", err)
+	}
+
+	identity, err := p.Exchange(context.Background(), testCode, "", testVerifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := Identity{Subject: "42", Email: "ada@example.com", EmailVerified: true, Name: "ada"}
+	if identity != want {
+		t.Errorf("Exchange returned %+v, want %+v", identity, want)
+	}
+}
@@ -3,16 +3,39 @@ package routes
 
 import (
 	"database/sql"
+	"log"
 	"net/http"
+	"sync"
 
 	"github.com/MyoMyatMin/expertly-backend/handlers"
 	"github.com/MyoMyatMin/expertly-backend/middlewares"
 	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/MyoMyatMin/expertly-backend/storage"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"github.com/joho/godotenv"
 )
 
+var (
+	documentStore     storage.Storage
+	documentStoreOnce sync.Once
+)
+
+// openDocumentStore opens the private storage for application documents once
+// per process, however many times SetUpRoutes runs. Without it the document
+// routes answer 503 rather than writing files that won't last.
+func openDocumentStore() storage.Storage {
+	documentStoreOnce.Do(func() {
+		store, err := storage.FromEnv()
+		if err != nil {
+			log.Printf("Document storage is disabled: %v", err)
+			return
+		}
+		documentStore = store
+	})
+	return documentStore
+}
+
 func SetUpRoutes(db *sql.DB) *chi.Mux {
 	r := chi.NewRouter()
 	godotenv.Load(".env")
@@ -21,7 +44,7 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 		cors.Handler(cors.Options{
 			AllowedOrigins:   []string{"https://expertly-psi.vercel.app", "http://localhost:3000"},
 			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-			AllowedHeaders:   []string{"Content-Type", "Authorization"},
+			AllowedHeaders:   []string{"Content-Type", "Authorization", "X-API-Key"},
 			ExposedHeaders:   []string{"Link"},
 			AllowCredentials: true,
 			MaxAge:           300,
@@ -51,9 +74,11 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 
 	queries := database.New(db)
 
+	store := openDocumentStore()
+
 	apiRouter.Post("/auth/signup", handlers.SignUpHandler(queries).ServeHTTP)
 	apiRouter.Post("/auth/login", handlers.LoginHandler(queries).ServeHTTP)
-	apiRouter.Post("/auth/logout", handlers.LogoutHandler)
+	apiRouter.Post("/auth/logout", handlers.LogoutHandler(queries).ServeHTTP)
 	apiRouter.Post("/auth/refresh-token", handlers.RefreshTokenHandler(queries).ServeHTTP)
 	apiRouter.Get("/auth/me", middlewares.MiddlewareModeratorOrUser(queries,
 		func(w http.ResponseWriter, r *http.Request, user database.User) {
@@ -63,6 +88,73 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 			handlers.CheckAuthStatsHandler(queries, database.User{}, moderator).ServeHTTP(w, r)
 		}))
 
+	// Background jobs, triggered by Vercel Cron
+	apiRouter.Get("/cron/{job}", handlers.CronHandler(db).ServeHTTP)
+
+	// Social Login Routes
+	apiRouter.Get("/auth/oauth/{provider}", handlers.OAuthStartHandler(queries).ServeHTTP)
+	apiRouter.Get("/auth/oauth/{provider}/callback", handlers.OAuthCallbackHandler(queries).ServeHTTP)
+
+	// Session Routes
+	apiRouter.Get("/auth/sessions", middlewares.MiddlewareModeratorOrUser(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.GetSessionsHandler(queries, u, database.Moderator{}).ServeHTTP(w, r)
+		},
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetSessionsHandler(queries, database.User{}, m).ServeHTTP(w, r)
+		}))
+	apiRouter.Delete("/auth/sessions", middlewares.MiddlewareModeratorOrUser(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.RevokeOtherSessionsHandler(queries, u, database.Moderator{}).ServeHTTP(w, r)
+		},
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.RevokeOtherSessionsHandler(queries, database.User{}, m).ServeHTTP(w, r)
+		}))
+	apiRouter.Delete("/auth/sessions/{id}", middlewares.MiddlewareModeratorOrUser(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.RevokeSessionHandler(queries, u, database.Moderator{}).ServeHTTP(w, r)
+		},
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.RevokeSessionHandler(queries, database.User{}, m).ServeHTTP(w, r)
+		}))
+
+	// Notification Routes
+	apiRouter.Get("/notifications", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.GetNotificationsHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Put("/notifications/read", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.MarkAllNotificationsReadHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Put("/notifications/{id}/read", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.MarkNotificationReadHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+
+	// API Key Routes
+	apiRouter.Post("/auth/api-keys", middlewares.MiddlewareModeratorOrUser(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.CreateAPIKeyHandler(queries, u, database.Moderator{}).ServeHTTP(w, r)
+		},
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.CreateAPIKeyHandler(queries, database.User{}, m).ServeHTTP(w, r)
+		}))
+	apiRouter.Get("/auth/api-keys", middlewares.MiddlewareModeratorOrUser(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.GetAPIKeysHandler(queries, u, database.Moderator{}).ServeHTTP(w, r)
+		},
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetAPIKeysHandler(queries, database.User{}, m).ServeHTTP(w, r)
+		}))
+	apiRouter.Delete("/auth/api-keys/{id}", middlewares.MiddlewareModeratorOrUser(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.RevokeAPIKeyHandler(queries, u, database.Moderator{}).ServeHTTP(w, r)
+		},
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.RevokeAPIKeyHandler(queries, database.User{}, m).ServeHTTP(w, r)
+		}))
+
 	// Post Routes
 	apiRouter.Get("/posts", handlers.GetAllPostsHandler(queries).ServeHTTP)
 	apiRouter.Post("/posts", middlewares.MiddlewareAuth(queries, nil,
@@ -84,6 +176,13 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
 			handlers.GetPostBySlugHandler(queries, database.User{}, m).ServeHTTP(w, r)
 		}))
+	apiRouter.Get("/posts/{slug}/related", middlewares.MiddlewareModeratorOrUser(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.GetRelatedPostsHandler(queries, u).ServeHTTP(w, r)
+		},
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetRelatedPostsHandler(queries, database.User{}).ServeHTTP(w, r)
+		}))
 
 	// Post Interactions Routes
 	apiRouter.Post("/posts/{postID}/upvotes", middlewares.MiddlewareAuth(queries,
@@ -114,10 +213,10 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 		}, nil, nil, "user"))
 	apiRouter.Get("/posts/{postSlug}/comments", middlewares.MiddlewareModeratorOrUser(queries,
 		func(w http.ResponseWriter, r *http.Request, u database.User) {
-			handlers.GetAllCommentsByPostHandler(queries).ServeHTTP(w, r)
+			handlers.GetAllCommentsByPostHandler(queries, u, database.Moderator{}).ServeHTTP(w, r)
 		},
 		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
-			handlers.GetAllCommentsByPostHandler(queries).ServeHTTP(w, r)
+			handlers.GetAllCommentsByPostHandler(queries, database.User{}, m).ServeHTTP(w, r)
 		}))
 
 	// Follow Routes
@@ -129,13 +228,71 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 		func(w http.ResponseWriter, r *http.Request, user database.User) {
 			handlers.DeleteFollowHandler(queries, user).ServeHTTP(w, r)
 		}, nil, nil, "user"))
+	apiRouter.Post("/users/{username}/follow", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, user database.User) {
+			handlers.FollowByUsernameHandler(queries, user).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Delete("/users/{username}/follow", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, user database.User) {
+			handlers.UnfollowByUsernameHandler(queries, user).ServeHTTP(w, r)
+		}, nil, nil, "user"))
 	apiRouter.Get("/users/{username}/following", middlewares.MiddlewareModeratorOrUser(queries,
 		func(w http.ResponseWriter, r *http.Request, u database.User) {
-			handlers.GetFollowingListByIDHandler(queries).ServeHTTP(w, r)
+			handlers.GetFollowingListByIDHandler(queries, u, database.Moderator{}).ServeHTTP(w, r)
+		},
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetFollowingListByIDHandler(queries, database.User{}, m).ServeHTTP(w, r)
+		}))
+
+	apiRouter.Get("/users/{username}/followers", middlewares.MiddlewareModeratorOrUser(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.GetFollowersHandler(queries, u, database.Moderator{}).ServeHTTP(w, r)
 		},
 		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
-			handlers.GetFollowingListByIDHandler(queries).ServeHTTP(w, r)
+			handlers.GetFollowersHandler(queries, database.User{}, m).ServeHTTP(w, r)
 		}))
+	apiRouter.Get("/follow/status", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, user database.User) {
+			handlers.GetFollowStatusesHandler(queries, user).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Get("/follow/suggestions", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, user database.User) {
+			handlers.GetFollowSuggestionsHandler(queries, user).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+
+	// Reputation Routes
+	apiRouter.Get("/users/{username}/reputation", handlers.GetUserReputationHandler(queries).ServeHTTP)
+	apiRouter.Get("/leaderboard", handlers.GetLeaderboardHandler(queries).ServeHTTP)
+
+	// Badge Routes
+	apiRouter.Get("/badges", handlers.GetBadgeDefinitionsHandler(queries).ServeHTTP)
+	apiRouter.Get("/users/{username}/badges", handlers.GetUserBadgesHandler(queries).ServeHTTP)
+
+	// Block & Mute Routes
+	apiRouter.Post("/users/{username}/block", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, user database.User) {
+			handlers.BlockUserHandler(queries, user).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Delete("/users/{username}/block", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, user database.User) {
+			handlers.UnblockUserHandler(queries, user).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Post("/users/{username}/mute", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, user database.User) {
+			handlers.MuteUserHandler(queries, user).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Delete("/users/{username}/mute", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, user database.User) {
+			handlers.UnmuteUserHandler(queries, user).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Get("/profile/blocks", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, user database.User) {
+			handlers.GetBlockedUsersHandler(queries, user).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Get("/profile/mutes", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, user database.User) {
+			handlers.GetMutedUsersHandler(queries, user).ServeHTTP(w, r)
+		}, nil, nil, "user"))
 
 	// Feed Route
 	apiRouter.Get("/feed", middlewares.MiddlewareAuth(queries,
@@ -154,10 +311,10 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 		}, nil, nil, "user"))
 	apiRouter.Get("/saved-posts/{username}", middlewares.MiddlewareModeratorOrUser(queries,
 		func(w http.ResponseWriter, r *http.Request, u database.User) {
-			handlers.GetSavedPosts(queries).ServeHTTP(w, r)
+			handlers.GetSavedPosts(queries, u, database.Moderator{}).ServeHTTP(w, r)
 		},
 		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
-			handlers.GetSavedPosts(queries).ServeHTTP(w, r)
+			handlers.GetSavedPosts(queries, database.User{}, m).ServeHTTP(w, r)
 		}))
 
 	// Profile Routes
@@ -170,10 +327,10 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 		}))
 	apiRouter.Get("/profile/{username}/posts", middlewares.MiddlewareModeratorOrUser(queries,
 		func(w http.ResponseWriter, r *http.Request, u database.User) {
-			handlers.GetContributorProfilePostsHandler(queries).ServeHTTP(w, r)
+			handlers.GetContributorProfilePostsHandler(queries, u, database.Moderator{}).ServeHTTP(w, r)
 		},
 		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
-			handlers.GetContributorProfilePostsHandler(queries).ServeHTTP(w, r)
+			handlers.GetContributorProfilePostsHandler(queries, database.User{}, m).ServeHTTP(w, r)
 		}))
 	apiRouter.Put("/profile/update", middlewares.MiddlewareAuth(queries,
 		func(w http.ResponseWriter, r *http.Request, u database.User) {
@@ -184,6 +341,45 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 			handlers.GetResolvedReportsWithSuspensionHandler(queries, u).ServeHTTP(w, r)
 		}, nil, nil, "user"))
 
+	apiRouter.Get("/profile/privacy", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.GetPrivacySettingsHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Put("/profile/privacy", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.UpdatePrivacySettingsHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Put("/profile/avatar", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.UploadAvatarHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Delete("/profile/avatar", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.DeleteAvatarHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Get("/profile/impersonations", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.GetMyImpersonationsHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+
+	// Account Data Routes
+	apiRouter.Get("/profile/export", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.ExportAccountHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Get("/profile/delete", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.GetAccountDeletionHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Post("/profile/delete", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.ScheduleAccountDeletionHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Delete("/profile/delete", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.CancelAccountDeletionHandler(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+
 	// Admin Routes
 	apiRouter.Post("/admin/login", handlers.LoginModeratorController(queries).ServeHTTP)
 	apiRouter.Post("/admin/create", middlewares.MiddlewareAuth(queries, nil, nil,
@@ -195,11 +391,44 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 			handlers.GetAllModerators(queries).ServeHTTP(w, r)
 		}, "moderator"))
 
+	// Impersonation Routes
+	apiRouter.Post("/admin/impersonations", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.CreateImpersonationHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Get("/admin/impersonations", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetImpersonationsHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Get("/admin/impersonations/{id}/requests", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetImpersonationRequestsHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Delete("/admin/impersonations/{id}", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.EndImpersonationHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+
 	// Contributor Application Routes
+	// Signed links to files in local storage; see storage.Local.
+	apiRouter.Get("/files/*", handlers.ServeStoredFileHandler(store))
+	apiRouter.Get("/expertise-fields", handlers.GetExpertiseFieldsHandler(queries).ServeHTTP)
 	apiRouter.Post("/contributor-applications", middlewares.MiddlewareAuth(queries,
 		func(w http.ResponseWriter, r *http.Request, u database.User) {
 			handlers.CreateContributorApplication(queries, u).ServeHTTP(w, r)
 		}, nil, nil, "user"))
+	apiRouter.Post("/contributor-applications/documents", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.UploadApplicationDocumentHandler(queries, store, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Get("/contributor-applications/mine", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.GetMyContributorApplications(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
+	apiRouter.Put("/contributor-applications/mine", middlewares.MiddlewareAuth(queries,
+		func(w http.ResponseWriter, r *http.Request, u database.User) {
+			handlers.ResubmitMyContributorApplication(queries, u).ServeHTTP(w, r)
+		}, nil, nil, "user"))
 	apiRouter.Get("/admin/contributor-applications", middlewares.MiddlewareAuth(queries, nil, nil,
 		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
 			handlers.GetContributorApplications(queries, m).ServeHTTP(w, r)
@@ -208,12 +437,47 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
 			handlers.GetContributorApplicationByID(queries, m).ServeHTTP(w, r)
 		}, "moderator"))
+	apiRouter.Get("/admin/contributor-applications/{id}/documents", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetApplicationDocumentsHandler(queries, store, m).ServeHTTP(w, r)
+		}, "moderator"))
 	apiRouter.Put("/admin/contributor-applications/{id}/status", middlewares.MiddlewareAuth(queries, nil, nil,
 		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
 			handlers.UpdateContributorApplication(queries, m).ServeHTTP(w, r)
 		}, "moderator"))
 
+	// Contributor Status Routes
+	apiRouter.Get("/admin/contributors/{id}/status", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetContributorStatusHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Put("/admin/contributors/{id}/status", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.UpdateContributorStatusHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Put("/admin/contributors/{id}/expiry", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.UpdateContributorExpiryHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+
 	// Reports Routes
+	apiRouter.Get("/report-reasons", handlers.GetReportReasonsHandler(queries).ServeHTTP)
+	apiRouter.Get("/admin/report-reasons", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetAllReportReasonsHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Post("/admin/report-reasons", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.CreateReportReasonHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Put("/admin/report-reasons/{code}", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.UpdateReportReasonHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Get("/admin/report-reasons/stats", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetReportReasonStatsHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
 	apiRouter.Post("/reports", middlewares.MiddlewareAuth(queries,
 		func(w http.ResponseWriter, r *http.Request, u database.User) {
 			handlers.CreateReportHandler(queries, u).ServeHTTP(w, r)
@@ -230,6 +494,72 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
 			handlers.UpdateReportStatusHandler(queries, m).ServeHTTP(w, r)
 		}, "moderator"))
+	apiRouter.Get("/admin/report-cases", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetReportCasesHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Get("/admin/report-cases/{caseID}", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetReportCaseHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Put("/admin/report-cases/{caseID}/status", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.UpdateReportCaseStatusHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Get("/admin/report-cases/{caseID}/enforcement-preview", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetCaseEnforcementPreviewHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+
+	// Enforcement Policy Routes
+	apiRouter.Get("/admin/enforcement-policy", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetEnforcementPolicyHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Put("/admin/enforcement-policy", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.UpdateEnforcementPolicyHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Get("/admin/users/{id}/strikes", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetUserStrikesHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Get("/admin/users/{id}/suspensions", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetUserSuspensionsHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Post("/admin/users/{id}/suspensions/{suspensionID}/lift", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.LiftSuspensionHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+
+	// Moderation Queue Routes
+	apiRouter.Get("/admin/moderation-queue", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetModerationQueueHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Post("/admin/moderation-queue/{type}/{id}/claim", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.ClaimQueueItemHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Delete("/admin/moderation-queue/{type}/{id}/claim", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.ReleaseQueueItemHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Put("/admin/moderation-queue/{type}/{id}/assignee", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.AssignQueueItemHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+
+	// Moderation Audit Log Routes
+	apiRouter.Get("/admin/moderation-actions", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetModerationActionsHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
+	apiRouter.Get("/admin/users/{id}/moderation-history", middlewares.MiddlewareAuth(queries, nil, nil,
+		func(w http.ResponseWriter, r *http.Request, m database.Moderator) {
+			handlers.GetUserModerationHistoryHandler(queries, m).ServeHTTP(w, r)
+		}, "moderator"))
 
 	// Appeals Routes
 	apiRouter.Post("/appeals", middlewares.MiddlewareAuth(queries,
@@ -260,11 +590,11 @@ func SetUpRoutes(db *sql.DB) *chi.Mux {
 	// Search Routes
 	apiRouter.Get("/search/posts", middlewares.MiddlewareAuth(queries,
 		func(w http.ResponseWriter, r *http.Request, u database.User) {
-			handlers.SearchPostsHandler(queries).ServeHTTP(w, r)
+			handlers.SearchPostsHandler(queries, u).ServeHTTP(w, r)
 		}, nil, nil, "user"))
 	apiRouter.Get("/search/users", middlewares.MiddlewareAuth(queries,
 		func(w http.ResponseWriter, r *http.Request, u database.User) {
-			handlers.SearchUsersHandler(queries).ServeHTTP(w, r)
+			handlers.SearchUsersHandler(queries, u).ServeHTTP(w, r)
 		}, nil, nil, "user"))
 
 	return r
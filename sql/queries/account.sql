@@ -0,0 +1,86 @@
+-- name: ScheduleAccountDeletion :one
+INSERT INTO account_deletions (user_id, scheduled_for)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET requested_at = NOW(), scheduled_for = EXCLUDED.scheduled_for
+RETURNING *;
+
+-- name: GetAccountDeletion :one
+SELECT *
+FROM account_deletions
+WHERE user_id = $1;
+
+-- name: CancelAccountDeletion :execrows
+DELETE FROM account_deletions
+WHERE user_id = $1;
+
+-- name: ListDueAccountDeletions :many
+SELECT user_id
+FROM account_deletions
+WHERE scheduled_for <= NOW();
+
+-- name: AnonymizeCommentsByUser :exec
+UPDATE comments
+SET user_id = sqlc.arg(deleted_user_id)
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: DeleteSavedPostsByUser :exec
+DELETE FROM saved_posts
+WHERE user_id = $1;
+
+-- name: DeleteContributorApplicationsByUser :exec
+DELETE FROM contributor_applications
+WHERE user_id = $1;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE user_id = $1;
+
+-- name: ExportCommentsByUser :many
+SELECT 
+    c.comment_id,
+    c.post_id,
+    p.slug AS post_slug,
+    c.parent_comment_id,
+    c.content,
+    c.created_at,
+    c.updated_at
+FROM comments c
+JOIN posts p ON c.post_id = p.post_id
+WHERE c.user_id = $1
+ORDER BY c.created_at ASC;
+
+-- name: ExportFollowersByUser :many
+SELECT 
+    following.follower_id,
+    users.name,
+    users.username
+FROM following
+JOIN users ON following.follower_id = users.user_id
+WHERE following.following_id = $1;
+
+-- name: ExportReportsFiledByUser :many
+SELECT 
+    report_id,
+    target_post_id,
+    target_user_id,
+    target_comment_id,
+    reason,
+    status,
+    created_at,
+    reviewed_at
+FROM reports
+WHERE reported_by = $1
+ORDER BY created_at ASC;
+
+-- name: ExportAppealsByUser :many
+SELECT 
+    appeal_id,
+    target_report_id,
+    reason,
+    status,
+    created_at,
+    reviewed_at
+FROM appeals
+WHERE appealed_by = $1
+ORDER BY created_at ASC;
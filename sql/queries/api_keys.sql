@@ -0,0 +1,61 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    api_key_id,
+    user_id,
+    moderator_id,
+    name,
+    key_prefix,
+    key_hash,
+    scopes,
+    expires_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING *;
+
+-- name: ListAPIKeysByOwner :many
+SELECT 
+    api_key_id,
+    name,
+    key_prefix,
+    scopes,
+    expires_at,
+    last_used_at,
+    revoked_at,
+    created_at
+FROM api_keys
+WHERE user_id = sqlc.arg(owner_id)::uuid OR moderator_id = sqlc.arg(owner_id)::uuid
+ORDER BY created_at DESC;
+
+-- name: CountActiveAPIKeysByOwner :one
+SELECT COUNT(*)
+FROM api_keys
+WHERE (user_id = sqlc.arg(owner_id)::uuid OR moderator_id = sqlc.arg(owner_id)::uuid)
+AND revoked_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT *
+FROM api_keys
+WHERE key_hash = $1
+AND revoked_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = NOW()
+WHERE api_key_id = sqlc.arg(api_key_id)
+AND (user_id = sqlc.arg(owner_id)::uuid OR moderator_id = sqlc.arg(owner_id)::uuid)
+AND revoked_at IS NULL;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE api_key_id = $1;
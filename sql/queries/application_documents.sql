@@ -0,0 +1,51 @@
+-- name: CreateApplicationDocument :one
+INSERT INTO application_documents (
+    document_id,
+    user_id,
+    kind,
+    storage_key,
+    content_type,
+    size_bytes,
+    original_name,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: AttachApplicationDocuments :many
+UPDATE application_documents
+SET application_id = sqlc.arg(application_id)
+WHERE user_id = sqlc.arg(user_id)
+AND document_id = ANY(sqlc.arg(document_ids)::uuid[])
+AND (application_id IS NULL OR application_id = sqlc.arg(application_id))
+RETURNING *;
+
+-- name: ListApplicationDocuments :many
+SELECT * FROM application_documents
+WHERE application_id = $1
+ORDER BY kind, created_at;
+
+-- name: ScheduleIdentityDocumentDeletion :exec
+UPDATE application_documents
+SET delete_after = sqlc.arg(delete_after)
+WHERE application_id = sqlc.arg(application_id)
+AND kind = 'identity'
+AND delete_after IS NULL;
+
+-- name: ListExpiredApplicationDocuments :many
+SELECT * FROM application_documents
+WHERE delete_after < sqlc.arg(now)
+OR user_id IS NULL
+OR (application_id IS NULL AND created_at < sqlc.arg(unattached_before))
+ORDER BY created_at
+LIMIT sqlc.arg(page_limit);
+
+-- name: DeleteApplicationDocument :exec
+DELETE FROM application_documents
+WHERE document_id = $1;
+
+-- name: ListApplicationDocumentsByIDs :many
+SELECT * FROM application_documents
+WHERE user_id = sqlc.arg(user_id)
+AND document_id = ANY(sqlc.arg(document_ids)::uuid[]);
@@ -0,0 +1,66 @@
+-- name: AwardBadge :execrows
+INSERT INTO user_badges (user_id, badge, field, awarded_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING;
+
+-- name: ListUserBadges :many
+SELECT *
+FROM user_badges
+WHERE user_id = $1
+ORDER BY awarded_at, badge, field;
+
+-- name: CountBadgeHolders :many
+SELECT badge, COUNT(DISTINCT user_id) AS holders
+FROM user_badges
+GROUP BY badge;
+
+-- name: GetBadgeProgress :one
+SELECT
+    (SELECT COUNT(*) FROM posts WHERE posts.user_id = sqlc.arg(user_id)::uuid) AS post_count,
+    (
+        SELECT COUNT(*)
+        FROM upvotes
+        JOIN posts ON posts.post_id = upvotes.post_id
+        WHERE posts.user_id = sqlc.arg(user_id)::uuid AND upvotes.user_id <> posts.user_id
+    ) AS upvotes_received,
+    (
+        SELECT COUNT(*)
+        FROM comments
+        WHERE comments.user_id = sqlc.arg(user_id)::uuid
+          AND EXISTS (
+              SELECT 1 FROM comments replies
+              WHERE replies.parent_comment_id = comments.comment_id
+                AND replies.user_id <> comments.user_id
+          )
+    ) AS helpful_comments;
+
+-- name: ListTopContributorFields :many
+WITH fields AS (
+    SELECT DISTINCT unnest(contributors.expertise_fields) AS field
+    FROM contributors
+    WHERE contributors.user_id = sqlc.arg(user_id)::uuid
+),
+scores AS (
+    SELECT fields.field, contributors.user_id, SUM(reputation_ledger.points) AS score
+    FROM fields
+    JOIN contributors ON fields.field = ANY(contributors.expertise_fields)
+    JOIN reputation_ledger ON reputation_ledger.user_id = contributors.user_id
+    WHERE reputation_ledger.occurred_at >= sqlc.arg(since)
+    GROUP BY fields.field, contributors.user_id
+),
+ranked AS (
+    SELECT scores.field, scores.user_id, scores.score,
+        RANK() OVER (PARTITION BY scores.field ORDER BY scores.score DESC) AS field_rank
+    FROM scores
+)
+SELECT ranked.field::text AS field
+FROM ranked
+WHERE ranked.user_id = sqlc.arg(user_id)::uuid
+  AND ranked.field_rank <= sqlc.arg(max_rank)::int
+  AND ranked.score >= sqlc.arg(min_score)::int
+ORDER BY ranked.field;
+
+-- name: ListBadgeCandidates :many
+SELECT posts.user_id FROM posts WHERE posts.user_id <> sqlc.arg(deleted_user_id)::uuid
+UNION
+SELECT comments.user_id FROM comments WHERE comments.user_id <> sqlc.arg(deleted_user_id)::uuid;
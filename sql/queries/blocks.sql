@@ -0,0 +1,51 @@
+-- name: CreateBlock :execrows
+INSERT INTO blocks (blocker_id, blocked_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBlock :execrows
+DELETE FROM blocks
+WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: IsBlocked :one
+SELECT EXISTS (
+    SELECT 1
+    FROM blocks
+    WHERE blocker_id = $1 AND blocked_id = $2
+);
+
+-- name: ListBlockedUsers :many
+SELECT 
+    blocks.blocked_id,
+    users.name,
+    users.username,
+    blocks.created_at
+FROM blocks
+JOIN users ON blocks.blocked_id = users.user_id
+WHERE blocks.blocker_id = $1
+ORDER BY blocks.created_at DESC;
+
+-- name: CreateMute :execrows
+INSERT INTO mutes (muter_id, muted_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteMute :execrows
+DELETE FROM mutes
+WHERE muter_id = $1 AND muted_id = $2;
+
+-- name: ListMutedUsers :many
+SELECT 
+    mutes.muted_id,
+    users.name,
+    users.username,
+    mutes.created_at
+FROM mutes
+JOIN users ON mutes.muted_id = users.user_id
+WHERE mutes.muter_id = $1
+ORDER BY mutes.created_at DESC;
+
+-- name: ListHiddenUserIDs :many
+SELECT muted_id AS user_id FROM mutes WHERE muter_id = $1
+UNION
+SELECT blocked_id AS user_id FROM blocks WHERE blocker_id = $1;
@@ -0,0 +1,33 @@
+-- name: CreateContentTakedown :one
+INSERT INTO content_takedowns(takedown_id, case_id, post_id, comment_id, action, label, taken_down_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: LiftActiveTakedown :exec
+UPDATE content_takedowns
+SET restored_by = sqlc.arg(restored_by), restored_at = sqlc.arg(restored_at)
+WHERE restored_at IS NULL
+  AND (post_id = sqlc.narg(post_id)::uuid OR comment_id = sqlc.narg(comment_id)::uuid);
+
+-- name: RestoreCaseTakedowns :many
+UPDATE content_takedowns
+SET restored_by = $2, restored_at = $3
+WHERE case_id = $1 AND restored_at IS NULL
+RETURNING *;
+
+-- name: GetActivePostTakedown :one
+SELECT * FROM content_takedowns
+WHERE post_id = $1 AND restored_at IS NULL;
+
+-- name: ListActiveCommentTakedownsByPost :many
+SELECT content_takedowns.*
+FROM content_takedowns
+JOIN comments ON comments.comment_id = content_takedowns.comment_id
+WHERE comments.post_id = $1 AND content_takedowns.restored_at IS NULL;
+
+-- name: ListActivePostTakedownsByUser :many
+SELECT content_takedowns.*
+FROM content_takedowns
+JOIN posts ON posts.post_id = content_takedowns.post_id
+WHERE posts.user_id = $1
+  AND content_takedowns.restored_at IS NULL;
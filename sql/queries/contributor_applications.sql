@@ -4,15 +4,17 @@ INSERT INTO contributor_applications(
     user_id,
     expertise_proofs,
     identity_proof,
-    initial_submission
+    initial_submission,
+    requested_fields
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6
 )
-RETURNING contri_app_id, user_id, expertise_proofs, identity_proof, initial_submission, status, created_at, reviewed_at;
+RETURNING contri_app_id, user_id, expertise_proofs, identity_proof, initial_submission, status, created_at, reviewed_at, requested_fields, reviewer_notes, updated_at;
 
 -- name: GetContributorApplication :one
 SELECT 
@@ -24,6 +26,10 @@ SELECT
     ca.status,
     ca.created_at,
     ca.reviewed_at,
+    ca.reviewed_by,
+    ca.requested_fields,
+    ca.reviewer_notes,
+    ca.updated_at,
     u.name AS name,
     u.username AS username,
     m.name AS reviewer_name
@@ -35,11 +41,14 @@ WHERE ca.contri_app_id = $1;
 -- name: UpdateContributorApplication :one
 UPDATE contributor_applications
 SET
-    status = $2,
-    reviewed_at = CURRENT_TIMESTAMP,
-    reviewed_by = $3
-WHERE contri_app_id = $1
-RETURNING contri_app_id, user_id, expertise_proofs, identity_proof, initial_submission, status, created_at, reviewed_at;
+    status = sqlc.arg(status),
+    reviewed_at = CASE WHEN sqlc.arg(status) = 'under_review' THEN reviewed_at ELSE CURRENT_TIMESTAMP END,
+    reviewed_by = sqlc.arg(reviewed_by),
+    reviewer_notes = sqlc.arg(reviewer_notes),
+    updated_at = CURRENT_TIMESTAMP
+WHERE contri_app_id = sqlc.arg(contri_app_id)
+AND status = sqlc.arg(from_status)
+RETURNING contri_app_id, user_id, expertise_proofs, identity_proof, initial_submission, status, created_at, reviewed_at, requested_fields, reviewer_notes, updated_at;
 
 -- name: ListContributorApplications :many
 SELECT 
@@ -52,9 +61,42 @@ SELECT
     ca.created_at,
     ca.reviewed_at,
     ca.reviewed_by,
+    ca.requested_fields,
+    ca.reviewer_notes,
+    ca.updated_at,
     u.name AS name,
     m.name AS reviewer_name
 FROM contributor_applications ca
 JOIN users u ON ca.user_id = u.user_id
 LEFT JOIN moderators m ON ca.reviewed_by = m.moderator_id
 ORDER BY ca.created_at DESC;
+
+-- name: ListContributorApplicationsByUser :many
+SELECT 
+    contri_app_id,
+    user_id,
+    expertise_proofs,
+    identity_proof,
+    initial_submission,
+    status,
+    created_at,
+    reviewed_at,
+    requested_fields,
+    reviewer_notes,
+    updated_at
+FROM contributor_applications
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: ResubmitContributorApplication :one
+UPDATE contributor_applications
+SET
+    expertise_proofs = $2,
+    identity_proof = $3,
+    initial_submission = $4,
+    requested_fields = $5,
+    status = 'pending',
+    updated_at = CURRENT_TIMESTAMP
+WHERE contri_app_id = $1
+AND status = 'needs_more_info'
+RETURNING contri_app_id, user_id, expertise_proofs, identity_proof, initial_submission, status, created_at, reviewed_at, requested_fields, reviewer_notes, updated_at;
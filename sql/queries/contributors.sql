@@ -6,13 +6,18 @@ INSERT INTO Contributors (
     $1,
     $2
 )
-RETURNING user_id, expertise_fields, created_at;
+RETURNING user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at;
 
 -- name: GetContributorByUserId :one
 SELECT 
     user_id,
     expertise_fields,
-    created_at
+    created_at,
+    status,
+    status_reason,
+    suspended_until,
+    expires_at,
+    renewal_reminded_at
 FROM Contributors
 WHERE user_id = $1;
 
@@ -21,6 +26,7 @@ SELECT EXISTS (
     SELECT 1
     FROM Contributors
     WHERE user_id = $1
+    AND status = 'active'
 );
 
 
@@ -41,3 +47,73 @@ LEFT JOIN comments c ON p.post_id = c.post_id
 WHERE p.user_id = $1
 GROUP BY p.post_id
 ORDER BY p.created_at DESC;
+
+-- name: SetContributorStatus :one
+UPDATE contributors
+SET
+    status = sqlc.arg(status),
+    status_reason = sqlc.arg(status_reason),
+    suspended_until = sqlc.arg(suspended_until)
+WHERE user_id = sqlc.arg(user_id)
+AND status = sqlc.arg(from_status)
+RETURNING user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at;
+
+-- name: SetContributorExpiry :one
+UPDATE contributors
+SET
+    expires_at = $2,
+    renewal_reminded_at = NULL
+WHERE user_id = $1
+RETURNING user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at;
+
+-- name: CreateContributorStatusHistory :exec
+INSERT INTO contributor_status_history (
+    history_id,
+    user_id,
+    from_status,
+    to_status,
+    reason,
+    changed_by,
+    suspended_until,
+    expires_at,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+);
+
+-- name: ListContributorStatusHistory :many
+SELECT
+    h.history_id,
+    h.from_status,
+    h.to_status,
+    h.reason,
+    h.changed_by,
+    m.name AS changed_by_name,
+    h.suspended_until,
+    h.expires_at,
+    h.created_at
+FROM contributor_status_history h
+LEFT JOIN moderators m ON h.changed_by = m.moderator_id
+WHERE h.user_id = $1
+ORDER BY h.created_at DESC;
+
+-- name: ListEndedContributorSuspensions :many
+SELECT user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at FROM contributors
+WHERE status = 'suspended'
+AND suspended_until <= sqlc.arg(now);
+
+-- name: ListLapsedContributors :many
+SELECT user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at FROM contributors
+WHERE status = 'active'
+AND expires_at <= sqlc.arg(now);
+
+-- name: ListContributorsDueRenewalReminder :many
+SELECT user_id, expertise_fields, created_at, status, status_reason, suspended_until, expires_at, renewal_reminded_at FROM contributors
+WHERE status = 'active'
+AND renewal_reminded_at IS NULL
+AND expires_at <= sqlc.arg(remind_before);
+
+-- name: MarkContributorRenewalReminded :exec
+UPDATE contributors
+SET renewal_reminded_at = $2
+WHERE user_id = $1;
@@ -0,0 +1,32 @@
+-- name: GetEnforcementPolicy :one
+SELECT * FROM enforcement_policy WHERE id;
+
+-- name: UpdateEnforcementPolicy :one
+UPDATE enforcement_policy
+SET strike_expiry_days = $1, levels = $2, updated_by = $3, updated_at = $4
+WHERE id
+RETURNING *;
+
+-- name: CreateStrike :one
+INSERT INTO strikes(strike_id, user_id, case_id, issued_by, penalty, suspend_days, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (case_id) DO NOTHING
+RETURNING *;
+
+-- name: GetStrikeByCase :one
+SELECT * FROM strikes WHERE case_id = $1;
+
+-- name: CountActiveStrikes :one
+SELECT COUNT(*) FROM strikes
+WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2;
+
+-- name: ListStrikesByUser :many
+SELECT * FROM strikes
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeCaseStrike :one
+UPDATE strikes
+SET revoked_by = $2, revoked_at = $3
+WHERE case_id = $1 AND revoked_at IS NULL
+RETURNING *;
@@ -0,0 +1,39 @@
+-- name: ListExpertiseFields :many
+SELECT *
+FROM expertise_fields
+ORDER BY category, name;
+
+-- name: UpsertContributorExpertise :exec
+INSERT INTO contributor_expertise (user_id, field, verification_level, application_id, verified_by, verified_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, field) DO UPDATE SET
+    verification_level = EXCLUDED.verification_level,
+    application_id = EXCLUDED.application_id,
+    verified_by = EXCLUDED.verified_by,
+    verified_at = EXCLUDED.verified_at,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: ListActiveContributorExpertise :many
+SELECT
+    contributor_expertise.field,
+    expertise_fields.name,
+    expertise_fields.category,
+    contributor_expertise.verification_level,
+    contributor_expertise.verified_at,
+    contributor_expertise.expires_at
+FROM contributor_expertise
+JOIN expertise_fields ON expertise_fields.slug = contributor_expertise.field
+WHERE contributor_expertise.user_id = sqlc.arg(user_id)::uuid
+  AND (contributor_expertise.expires_at IS NULL OR contributor_expertise.expires_at > sqlc.arg(now))
+ORDER BY expertise_fields.name;
+
+-- name: SyncContributorExpertiseFields :exec
+UPDATE contributors
+SET expertise_fields = ARRAY(
+    SELECT contributor_expertise.field
+    FROM contributor_expertise
+    WHERE contributor_expertise.user_id = contributors.user_id
+      AND (contributor_expertise.expires_at IS NULL OR contributor_expertise.expires_at > sqlc.arg(now))
+    ORDER BY contributor_expertise.field
+)
+WHERE contributors.user_id IN (SELECT contributor_expertise.user_id FROM contributor_expertise);
@@ -0,0 +1,119 @@
+-- name: DeleteAllFollowSuggestions :exec
+DELETE FROM follow_suggestions;
+
+-- name: InsertFollowSuggestions :exec
+WITH fof AS (
+    SELECT f1.follower_id AS user_id, f2.following_id AS candidate_id, COUNT(*) AS mutual_follows
+    FROM following f1
+    JOIN following f2 ON f2.follower_id = f1.following_id
+    GROUP BY f1.follower_id, f2.following_id
+),
+interests AS (
+    SELECT DISTINCT engaged.user_id, field
+    FROM (
+        SELECT upvotes.user_id, upvotes.post_id FROM upvotes
+        UNION
+        SELECT saved_posts.user_id, saved_posts.post_id FROM saved_posts
+    ) engaged
+    JOIN posts ON posts.post_id = engaged.post_id
+    JOIN contributors ON contributors.user_id = posts.user_id
+    CROSS JOIN LATERAL unnest(contributors.expertise_fields) AS field
+),
+expertise AS (
+    SELECT interests.user_id, contributors.user_id AS candidate_id, COUNT(DISTINCT interests.field) AS shared_expertise
+    FROM interests
+    JOIN contributors ON interests.field = ANY(contributors.expertise_fields)
+    GROUP BY interests.user_id, contributors.user_id
+),
+engagement AS (
+    SELECT contributors.user_id AS candidate_id,
+        (SELECT COUNT(*) FROM upvotes JOIN posts ON posts.post_id = upvotes.post_id WHERE posts.user_id = contributors.user_id)
+        + (SELECT COUNT(*) FROM comments JOIN posts ON posts.post_id = comments.post_id WHERE posts.user_id = contributors.user_id)
+        + (SELECT COUNT(*) FROM following WHERE following.following_id = contributors.user_id) AS engagement
+    FROM contributors
+    WHERE contributors.status = 'active'
+),
+popular AS (
+    SELECT candidate_id FROM engagement
+    ORDER BY engagement DESC
+    LIMIT sqlc.arg(per_user)::int
+),
+pairs AS (
+    SELECT fof.user_id, fof.candidate_id FROM fof
+    UNION
+    SELECT expertise.user_id, expertise.candidate_id FROM expertise
+    UNION
+    SELECT users.user_id, popular.candidate_id FROM users CROSS JOIN popular
+),
+scored AS (
+    SELECT pairs.user_id, pairs.candidate_id,
+        COALESCE(fof.mutual_follows, 0) AS mutual_follows,
+        COALESCE(expertise.shared_expertise, 0) AS shared_expertise,
+        COALESCE(fof.mutual_follows, 0) * 3.0
+            + COALESCE(expertise.shared_expertise, 0) * 2.0
+            + LN(1 + engagement.engagement) AS score
+    FROM pairs
+    JOIN engagement ON engagement.candidate_id = pairs.candidate_id
+    LEFT JOIN fof ON fof.user_id = pairs.user_id AND fof.candidate_id = pairs.candidate_id
+    LEFT JOIN expertise ON expertise.user_id = pairs.user_id AND expertise.candidate_id = pairs.candidate_id
+    WHERE pairs.user_id <> pairs.candidate_id
+      AND pairs.user_id <> sqlc.arg(deleted_user_id)::uuid
+      AND NOT EXISTS (
+          SELECT 1 FROM following
+          WHERE following.follower_id = pairs.user_id AND following.following_id = pairs.candidate_id
+      )
+      AND NOT EXISTS (
+          SELECT 1 FROM blocks
+          WHERE (blocks.blocker_id = pairs.user_id AND blocks.blocked_id = pairs.candidate_id)
+             OR (blocks.blocker_id = pairs.candidate_id AND blocks.blocked_id = pairs.user_id)
+      )
+      AND NOT EXISTS (
+          SELECT 1 FROM mutes
+          WHERE mutes.muter_id = pairs.user_id AND mutes.muted_id = pairs.candidate_id
+      )
+      AND NOT EXISTS (
+          SELECT 1 FROM account_deletions WHERE account_deletions.user_id IN (pairs.user_id, pairs.candidate_id)
+      )
+),
+ranked AS (
+    SELECT scored.*, ROW_NUMBER() OVER (PARTITION BY scored.user_id ORDER BY scored.score DESC, scored.candidate_id) AS rank
+    FROM scored
+)
+INSERT INTO follow_suggestions (user_id, suggested_user_id, score, mutual_follows, shared_expertise, computed_at)
+SELECT ranked.user_id, ranked.candidate_id, ranked.score, ranked.mutual_follows, ranked.shared_expertise, sqlc.arg(computed_at)
+FROM ranked
+WHERE ranked.rank <= sqlc.arg(per_user)::int;
+
+-- name: ListFollowSuggestions :many
+SELECT
+    follow_suggestions.suggested_user_id,
+    users.name,
+    users.username,
+    COALESCE(user_profiles.headline, '') AS headline,
+    COALESCE(user_profiles.avatar_url, '') AS avatar_url,
+    contributors.expertise_fields,
+    follow_suggestions.mutual_follows,
+    follow_suggestions.shared_expertise,
+    follow_suggestions.score
+FROM follow_suggestions
+JOIN users ON users.user_id = follow_suggestions.suggested_user_id
+JOIN contributors ON contributors.user_id = follow_suggestions.suggested_user_id
+LEFT JOIN user_profiles ON user_profiles.user_id = follow_suggestions.suggested_user_id
+WHERE follow_suggestions.user_id = sqlc.arg(user_id)::uuid
+  AND contributors.status = 'active'
+  AND NOT EXISTS (
+      SELECT 1 FROM following
+      WHERE following.follower_id = follow_suggestions.user_id
+        AND following.following_id = follow_suggestions.suggested_user_id
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM blocks
+      WHERE (blocks.blocker_id = follow_suggestions.user_id AND blocks.blocked_id = follow_suggestions.suggested_user_id)
+         OR (blocks.blocker_id = follow_suggestions.suggested_user_id AND blocks.blocked_id = follow_suggestions.user_id)
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM mutes
+      WHERE mutes.muter_id = follow_suggestions.user_id AND mutes.muted_id = follow_suggestions.suggested_user_id
+  )
+ORDER BY follow_suggestions.score DESC, follow_suggestions.suggested_user_id
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
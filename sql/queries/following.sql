@@ -8,12 +8,13 @@ JOIN users ON following.following_id = users.user_id
 WHERE following.follower_id = $1;
 
 
--- name: CreateFollow :exec
+-- name: CreateFollow :one
 INSERT INTO following (follower_id, following_id)
 VALUES ($1, $2)
-ON CONFLICT DO NOTHING;
+ON CONFLICT DO NOTHING
+RETURNING *;
 
--- name: DeleteFollow :exec
+-- name: DeleteFollow :execrows
 DELETE FROM following
 WHERE follower_id = $1 AND following_id = $2;
 
@@ -25,6 +26,10 @@ FROM posts
 JOIN following ON posts.user_id = following.following_id
 JOIN users ON posts.user_id = users.user_id
 WHERE following.follower_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM content_takedowns t
+    WHERE t.post_id = posts.post_id AND t.restored_at IS NULL AND t.action <> 'label'
+)
 ORDER BY posts.created_at DESC;
 
 -- name: GetFollwersCount :one
@@ -43,3 +48,55 @@ SELECT EXISTS (
     FROM following
     WHERE follower_id = $1 AND following_id = $2
 );
+
+-- name: ListFollowers :many
+SELECT 
+    following.follower_id,
+    users.name,
+    users.username,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = sqlc.arg(viewer_id)::uuid AND v.following_id = following.follower_id
+    ) AS is_following,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = following.follower_id AND v.following_id = sqlc.arg(viewer_id)::uuid
+    ) AS follows_you
+FROM following
+JOIN users ON following.follower_id = users.user_id
+WHERE following.following_id = sqlc.arg(user_id)::uuid
+ORDER BY users.username
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: ListFollowing :many
+SELECT 
+    following.following_id,
+    users.name,
+    users.username,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = sqlc.arg(viewer_id)::uuid AND v.following_id = following.following_id
+    ) AS is_following,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = following.following_id AND v.following_id = sqlc.arg(viewer_id)::uuid
+    ) AS follows_you
+FROM following
+JOIN users ON following.following_id = users.user_id
+WHERE following.follower_id = sqlc.arg(user_id)::uuid
+ORDER BY users.username
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: GetFollowStatuses :many
+SELECT 
+    users.user_id,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = sqlc.arg(viewer_id)::uuid AND v.following_id = users.user_id
+    ) AS is_following,
+    EXISTS (
+        SELECT 1 FROM following v
+        WHERE v.follower_id = users.user_id AND v.following_id = sqlc.arg(viewer_id)::uuid
+    ) AS follows_you
+FROM users
+WHERE users.user_id = ANY(sqlc.arg(user_ids)::uuid[]);
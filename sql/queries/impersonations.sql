@@ -0,0 +1,67 @@
+-- name: CreateImpersonation :one
+INSERT INTO impersonations (impersonation_id, moderator_id, user_id, reason, read_only, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetActiveImpersonation :one
+SELECT *
+FROM impersonations
+WHERE impersonation_id = $1
+  AND ended_at IS NULL
+  AND expires_at > NOW();
+
+-- name: EndImpersonation :execrows
+UPDATE impersonations
+SET ended_at = NOW()
+WHERE impersonation_id = $1
+  AND moderator_id = $2
+  AND ended_at IS NULL;
+
+-- name: ListImpersonations :many
+SELECT 
+    i.impersonation_id,
+    i.moderator_id,
+    m.name AS moderator_name,
+    i.user_id,
+    u.username,
+    i.reason,
+    i.read_only,
+    i.created_at,
+    i.expires_at,
+    i.ended_at
+FROM impersonations i
+JOIN moderators m ON i.moderator_id = m.moderator_id
+JOIN users u ON i.user_id = u.user_id
+ORDER BY i.created_at DESC
+LIMIT 100;
+
+-- name: ListImpersonationsOfUser :many
+SELECT 
+    i.impersonation_id,
+    m.name AS moderator_name,
+    i.reason,
+    i.read_only,
+    i.created_at,
+    i.expires_at,
+    i.ended_at
+FROM impersonations i
+JOIN moderators m ON i.moderator_id = m.moderator_id
+WHERE i.user_id = $1
+ORDER BY i.created_at DESC;
+
+-- name: CreateImpersonationRequest :exec
+INSERT INTO impersonation_requests (request_id, impersonation_id, method, path, status_code)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListImpersonationRequests :many
+SELECT *
+FROM impersonation_requests
+WHERE impersonation_id = $1
+ORDER BY created_at ASC;
+
+-- name: ListImpersonationRequestsOfUser :many
+SELECT r.request_id, r.impersonation_id, r.method, r.path, r.status_code, r.created_at
+FROM impersonation_requests r
+JOIN impersonations i ON r.impersonation_id = i.impersonation_id
+WHERE i.user_id = $1
+ORDER BY r.created_at ASC;
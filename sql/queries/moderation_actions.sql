@@ -0,0 +1,30 @@
+-- name: CreateModerationAction :exec
+INSERT INTO moderation_actions(action_id, moderator_id, action, target_type, target_id, subject_user_id, before_state, after_state, reason, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: ListModerationActions :many
+SELECT
+    ma.action_id,
+    ma.moderator_id,
+    m.name AS moderator_name,
+    ma.action,
+    ma.target_type,
+    ma.target_id,
+    ma.subject_user_id,
+    u.username AS subject_username,
+    ma.before_state,
+    ma.after_state,
+    ma.reason,
+    ma.created_at
+FROM moderation_actions ma
+LEFT JOIN moderators m ON m.moderator_id = ma.moderator_id
+LEFT JOIN users u ON u.user_id = ma.subject_user_id
+WHERE (sqlc.narg(moderator_id)::uuid IS NULL OR ma.moderator_id = sqlc.narg(moderator_id)::uuid)
+  AND (@action::text = '' OR ma.action = @action::text)
+  AND (@target_type::text = '' OR ma.target_type = @target_type::text)
+  AND (sqlc.narg(target_id)::uuid IS NULL OR ma.target_id = sqlc.narg(target_id)::uuid)
+  AND (sqlc.narg(subject_user_id)::uuid IS NULL OR ma.subject_user_id = sqlc.narg(subject_user_id)::uuid)
+  AND (sqlc.narg(since)::timestamp IS NULL OR ma.created_at >= sqlc.narg(since)::timestamp)
+  AND (sqlc.narg(until)::timestamp IS NULL OR ma.created_at < sqlc.narg(until)::timestamp)
+ORDER BY ma.created_at DESC, ma.action_id
+LIMIT @page_limit OFFSET @page_offset;
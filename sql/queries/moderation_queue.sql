@@ -0,0 +1,109 @@
+-- name: ListModerationQueue :many
+SELECT
+    q.item_type::text AS item_type,
+    q.item_id::uuid AS item_id,
+    q.status::text AS status,
+    q.summary::text AS summary,
+    q.severity::int AS severity,
+    q.created_at::timestamp AS created_at,
+    u.user_id AS subject_user_id,
+    u.name AS subject_name,
+    u.username AS subject_username,
+    c.claimed_by,
+    m.name AS claimed_by_name,
+    c.assigned_by,
+    c.claimed_at,
+    c.expires_at AS claim_expires_at
+FROM (
+    SELECT
+        'report' AS item_type,
+        rc.case_id AS item_id,
+        rc.status,
+        rc.target_type AS summary,
+        rc.target_user_id AS subject_user_id,
+        COALESCE((
+            SELECT MAX(rr.severity)
+            FROM reports r
+            JOIN report_reasons rr ON rr.code = r.reason_code
+            WHERE r.case_id = rc.case_id
+        ), 0) AS severity,
+        rc.created_at
+    FROM report_cases rc
+    WHERE rc.status = 'open'
+    UNION ALL
+    SELECT
+        'appeal',
+        a.appeal_id,
+        COALESCE(a.status, 'pending'),
+        a.reason,
+        a.appealed_by,
+        0,
+        COALESCE(a.created_at, CURRENT_TIMESTAMP)
+    FROM appeals a
+    WHERE COALESCE(a.status, 'pending') = 'pending'
+    UNION ALL
+    SELECT
+        'application',
+        ca.contri_app_id,
+        ca.status,
+        ca.initial_submission,
+        ca.user_id,
+        0,
+        COALESCE(ca.created_at, CURRENT_TIMESTAMP)
+    FROM contributor_applications ca
+    WHERE ca.status IN ('pending', 'under_review')
+) q
+JOIN users u ON u.user_id = q.subject_user_id
+LEFT JOIN moderation_claims c
+    ON c.item_type = q.item_type AND c.item_id = q.item_id AND c.expires_at > @now::timestamp
+LEFT JOIN moderators m ON m.moderator_id = c.claimed_by
+WHERE (@item_type::text = '' OR q.item_type = @item_type::text)
+  AND (@status::text = '' OR q.status = @status::text)
+  AND (sqlc.narg(created_before)::timestamp IS NULL OR q.created_at <= sqlc.narg(created_before)::timestamp)
+  AND (@claim_state::text = ''
+       OR (@claim_state::text = 'unclaimed' AND c.claimed_by IS NULL)
+       OR (@claim_state::text = 'claimed' AND c.claimed_by IS NOT NULL))
+  AND (sqlc.narg(claimed_by)::uuid IS NULL OR c.claimed_by = sqlc.narg(claimed_by)::uuid)
+ORDER BY q.severity DESC, q.created_at ASC, q.item_id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: GetModerationClaim :one
+SELECT * FROM moderation_claims
+WHERE item_type = $1 AND item_id = $2 AND expires_at > $3;
+
+-- name: ClaimModerationItem :one
+INSERT INTO moderation_claims(item_type, item_id, claimed_by, assigned_by, claimed_at, expires_at)
+VALUES ($1, $2, $3, NULL, $4, $5)
+ON CONFLICT (item_type, item_id) DO UPDATE
+SET claimed_by = EXCLUDED.claimed_by,
+    assigned_by = CASE
+        WHEN moderation_claims.claimed_by = EXCLUDED.claimed_by
+         AND moderation_claims.expires_at > EXCLUDED.claimed_at THEN moderation_claims.assigned_by
+    END,
+    claimed_at = CASE
+        WHEN moderation_claims.claimed_by = EXCLUDED.claimed_by
+         AND moderation_claims.expires_at > EXCLUDED.claimed_at THEN moderation_claims.claimed_at
+        ELSE EXCLUDED.claimed_at
+    END,
+    expires_at = GREATEST(moderation_claims.expires_at, EXCLUDED.expires_at)
+WHERE moderation_claims.claimed_by = EXCLUDED.claimed_by
+   OR moderation_claims.expires_at <= EXCLUDED.claimed_at
+RETURNING *;
+
+-- name: AssignModerationItem :one
+INSERT INTO moderation_claims(item_type, item_id, claimed_by, assigned_by, claimed_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (item_type, item_id) DO UPDATE
+SET claimed_by = EXCLUDED.claimed_by,
+    assigned_by = EXCLUDED.assigned_by,
+    claimed_at = EXCLUDED.claimed_at,
+    expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: ReleaseModerationItem :execrows
+DELETE FROM moderation_claims
+WHERE item_type = $1 AND item_id = $2 AND claimed_by = $3;
+
+-- name: DeleteModerationClaim :exec
+DELETE FROM moderation_claims
+WHERE item_type = $1 AND item_id = $2;
@@ -0,0 +1,40 @@
+-- name: CreateNotification :exec
+INSERT INTO notifications (
+    notification_id,
+    user_id,
+    type,
+    message,
+    data
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
+
+-- name: ListNotificationsByUser :many
+SELECT *
+FROM notifications
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 50;
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = $1
+AND read_at IS NULL;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET read_at = NOW()
+WHERE notification_id = $1
+AND user_id = $2
+AND read_at IS NULL;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = $1
+AND read_at IS NULL;
@@ -0,0 +1,14 @@
+-- name: AddPostTags :exec
+INSERT INTO post_tags (post_id, tag)
+SELECT sqlc.arg(post_id)::uuid, unnest(sqlc.arg(tags)::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeletePostTags :exec
+DELETE FROM post_tags
+WHERE post_id = $1;
+
+-- name: ListPostTags :many
+SELECT tag
+FROM post_tags
+WHERE post_id = $1
+ORDER BY tag;
@@ -67,6 +67,10 @@ LEFT JOIN (
     GROUP BY post_id
 ) upvote_counts ON p.post_id = upvote_counts.post_id
 WHERE p.created_at >= NOW() - INTERVAL '2 years'  -- Get posts from last 2 years
+AND NOT EXISTS (
+    SELECT 1 FROM content_takedowns t
+    WHERE t.post_id = p.post_id AND t.restored_at IS NULL AND t.action <> 'label'
+)
 ORDER BY 
     (COALESCE(upvote_counts.count, 0) * 2 + COALESCE(comment_counts.count, 0)) DESC, -- Weight upvotes & comments
     p.created_at DESC -- Prioritize newer posts if engagement is similar
@@ -96,7 +100,14 @@ SELECT
     u.name AS author_name,
     u.username AS author_username,
     COALESCE(upvote_counts.count, 0) AS upvote_count,
-    COALESCE(comment_counts.count, 0) AS comment_count
+    COALESCE(comment_counts.count, 0) AS comment_count,
+    ARRAY(
+        SELECT ce.field
+        FROM contributor_expertise ce
+        WHERE ce.user_id = p.user_id
+        AND (ce.expires_at IS NULL OR ce.expires_at > $2)
+        ORDER BY ce.field
+    )::text[] AS author_verified_fields
 FROM posts p
 JOIN users u ON p.user_id = u.user_id
 LEFT JOIN (
@@ -139,7 +150,14 @@ SELECT
         FROM saved_posts 
         WHERE saved_posts.post_id = p.post_id  -- Prefix `post_id` with table alias
         AND saved_posts.user_id = $2            -- Prefix `user_id` with table alias
-    ) AS has_saved
+    ) AS has_saved,
+    ARRAY(
+        SELECT ce.field
+        FROM contributor_expertise ce
+        WHERE ce.user_id = p.user_id
+        AND (ce.expires_at IS NULL OR ce.expires_at > $3)
+        ORDER BY ce.field
+    )::text[] AS author_verified_fields
 FROM posts p
 JOIN users u ON p.user_id = u.user_id
 LEFT JOIN (
@@ -177,6 +195,10 @@ SELECT
 FROM posts p
 JOIN users u ON p.user_id = u.user_id
 WHERE p.title ILIKE '%' || $1 || '%'
+AND NOT EXISTS (
+    SELECT 1 FROM content_takedowns t
+    WHERE t.post_id = p.post_id AND t.restored_at IS NULL AND t.action <> 'label'
+)
 ORDER BY 
     p.created_at DESC -- Prioritize newer posts
 LIMIT 20;
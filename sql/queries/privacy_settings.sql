@@ -0,0 +1,19 @@
+-- name: GetPrivacySettings :one
+SELECT *
+FROM privacy_settings
+WHERE user_id = $1;
+
+-- name: UpsertPrivacySettings :one
+INSERT INTO privacy_settings (user_id, saved_posts_visibility, follows_visibility, email_visibility, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET saved_posts_visibility = EXCLUDED.saved_posts_visibility,
+    follows_visibility = EXCLUDED.follows_visibility,
+    email_visibility = EXCLUDED.email_visibility,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: ListEmailVisibilities :many
+SELECT user_id, email_visibility
+FROM privacy_settings
+WHERE user_id = ANY($1::uuid[]);
@@ -0,0 +1,130 @@
+-- name: GetRelatedPostsComputedAt :one
+SELECT computed_at
+FROM related_posts_cache
+WHERE post_id = $1;
+
+-- name: ResetRelatedPostsCache :exec
+INSERT INTO related_posts_cache (post_id, computed_at)
+VALUES ($1, $2)
+ON CONFLICT (post_id) DO UPDATE SET computed_at = EXCLUDED.computed_at;
+
+-- name: DeleteRelatedPosts :exec
+DELETE FROM related_posts
+WHERE post_id = $1;
+
+-- name: InsertRelatedPosts :exec
+WITH source AS (
+    SELECT
+        posts.post_id,
+        posts.user_id,
+        websearch_to_tsquery('english', array_to_string(tsvector_to_array(to_tsvector('english', posts.title)), ' or ')) AS query
+    FROM posts
+    WHERE posts.post_id = sqlc.arg(post_id)::uuid
+),
+tag_matches AS (
+    SELECT other.post_id, COUNT(*) AS shared_tags
+    FROM post_tags mine
+    JOIN post_tags other ON other.tag = mine.tag
+    WHERE mine.post_id = sqlc.arg(post_id)::uuid
+    GROUP BY other.post_id
+),
+upvote_matches AS (
+    SELECT other.post_id, COUNT(*) AS co_upvotes
+    FROM upvotes mine
+    JOIN upvotes other ON other.user_id = mine.user_id
+    WHERE mine.post_id = sqlc.arg(post_id)::uuid
+    GROUP BY other.post_id
+),
+author_matches AS (
+    SELECT posts.post_id
+    FROM posts
+    JOIN source ON posts.user_id = source.user_id
+),
+text_matches AS (
+    SELECT posts.post_id, ts_rank_cd(to_tsvector('english', posts.title || ' ' || posts.content), source.query, 32) AS text_similarity
+    FROM posts, source
+    WHERE to_tsvector('english', posts.title || ' ' || posts.content) @@ source.query
+    ORDER BY text_similarity DESC
+    LIMIT 100
+),
+candidates AS (
+    SELECT tag_matches.post_id FROM tag_matches
+    UNION
+    SELECT upvote_matches.post_id FROM upvote_matches
+    UNION
+    SELECT author_matches.post_id FROM author_matches
+    UNION
+    SELECT text_matches.post_id FROM text_matches
+),
+scored AS (
+    SELECT
+        candidates.post_id,
+        COALESCE(tag_matches.shared_tags, 0) AS shared_tags,
+        author_matches.post_id IS NOT NULL AS same_author,
+        COALESCE(upvote_matches.co_upvotes, 0) AS co_upvotes,
+        COALESCE(text_matches.text_similarity, 0) AS text_similarity
+    FROM candidates
+    LEFT JOIN tag_matches ON tag_matches.post_id = candidates.post_id
+    LEFT JOIN upvote_matches ON upvote_matches.post_id = candidates.post_id
+    LEFT JOIN author_matches ON author_matches.post_id = candidates.post_id
+    LEFT JOIN text_matches ON text_matches.post_id = candidates.post_id
+    WHERE candidates.post_id <> sqlc.arg(post_id)::uuid
+)
+INSERT INTO related_posts (post_id, related_post_id, score, shared_tags, same_author, co_upvotes, text_similarity)
+SELECT
+    sqlc.arg(post_id)::uuid,
+    scored.post_id,
+    scored.shared_tags * 3.0
+        + CASE WHEN scored.same_author THEN 1.5 ELSE 0 END
+        + LN(1 + scored.co_upvotes) * 2.0
+        + scored.text_similarity * 4.0,
+    scored.shared_tags,
+    scored.same_author,
+    scored.co_upvotes,
+    scored.text_similarity
+FROM scored
+ORDER BY 3 DESC
+LIMIT sqlc.arg(max_related)::int
+ON CONFLICT DO NOTHING;
+
+-- name: ListRelatedPosts :many
+SELECT
+    posts.post_id,
+    posts.slug,
+    posts.title,
+    posts.user_id,
+    posts.created_at,
+    users.name AS author_name,
+    users.username AS author_username,
+    related_posts.shared_tags,
+    related_posts.same_author,
+    related_posts.co_upvotes,
+    related_posts.text_similarity
+FROM related_posts
+JOIN posts ON posts.post_id = related_posts.related_post_id
+JOIN users ON users.user_id = posts.user_id
+WHERE related_posts.post_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM content_takedowns t
+    WHERE t.post_id = posts.post_id AND t.restored_at IS NULL AND t.action <> 'label'
+)
+ORDER BY related_posts.score DESC, posts.created_at DESC;
+
+-- name: InvalidateRelatedPosts :exec
+DELETE FROM related_posts_cache
+WHERE related_posts_cache.post_id = sqlc.arg(post_id)::uuid
+   OR related_posts_cache.post_id IN (
+       SELECT related_posts.post_id
+       FROM related_posts
+       WHERE related_posts.related_post_id = sqlc.arg(post_id)::uuid
+       UNION
+       SELECT other.post_id
+       FROM post_tags mine
+       JOIN post_tags other ON other.tag = mine.tag
+       WHERE mine.post_id = sqlc.arg(post_id)::uuid
+       UNION
+       SELECT other.post_id
+       FROM posts mine
+       JOIN posts other ON other.user_id = mine.user_id
+       WHERE mine.post_id = sqlc.arg(post_id)::uuid
+   );
@@ -0,0 +1,113 @@
+-- name: OpenReportCase :one
+INSERT INTO report_cases (
+    case_id,
+    target_key,
+    target_type,
+    target_user_id,
+    target_post_id,
+    target_comment_id,
+    created_at,
+    last_reported_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $7
+)
+ON CONFLICT (target_key) WHERE status = 'open'
+DO UPDATE SET last_reported_at = EXCLUDED.last_reported_at
+RETURNING *;
+
+-- name: GetReportCase :one
+SELECT * FROM report_cases
+WHERE case_id = $1;
+
+-- name: ListReportCases :many
+SELECT
+    rc.case_id,
+    rc.target_type,
+    rc.target_user_id,
+    rc.target_post_id,
+    rc.target_comment_id,
+    rc.status,
+    rc.resolved_by,
+    rc.resolved_at,
+    rc.suspend_days,
+    rc.created_at,
+    rc.last_reported_at,
+    COUNT(r.report_id) AS report_count,
+    COUNT(DISTINCT r.reported_by) AS reporter_count,
+    COALESCE(array_agg(DISTINCT r.reason) FILTER (WHERE r.reason <> ''), '{}')::text[] AS reasons,
+    array_agg(DISTINCT r.reason_code)::text[] AS reason_codes,
+    MAX(rr.severity)::int AS severity,
+    tu.name AS target_name,
+    tu.username AS target_username,
+    EXISTS (SELECT 1 FROM contributors ct WHERE ct.user_id = rc.target_user_id) AS target_is_contributor,
+    p.slug AS target_post_slug,
+    p.title AS target_post_title,
+    c.content AS target_comment
+FROM report_cases rc
+JOIN reports r ON r.case_id = rc.case_id
+JOIN report_reasons rr ON rr.code = r.reason_code
+JOIN users tu ON tu.user_id = rc.target_user_id
+LEFT JOIN posts p ON p.post_id = rc.target_post_id
+LEFT JOIN comments c ON c.comment_id = rc.target_comment_id
+WHERE rc.status = sqlc.arg(status)
+AND (sqlc.arg(target_type)::text = '' OR rc.target_type = sqlc.arg(target_type)::text)
+AND (sqlc.arg(reason_code)::text = '' OR EXISTS (
+    SELECT 1 FROM reports rf WHERE rf.case_id = rc.case_id AND rf.reason_code = sqlc.arg(reason_code)::text
+))
+GROUP BY rc.case_id, tu.user_id, p.post_id, c.comment_id
+ORDER BY severity DESC, reporter_count DESC, rc.last_reported_at DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: ListReportsByCase :many
+SELECT
+    r.report_id,
+    r.reported_by,
+    u.name AS reported_by_name,
+    u.username AS reported_by_username,
+    r.reason,
+    r.reason_code,
+    rr.label AS reason_label,
+    rr.severity,
+    r.status,
+    r.created_at
+FROM reports r
+JOIN users u ON u.user_id = r.reported_by
+JOIN report_reasons rr ON rr.code = r.reason_code
+WHERE r.case_id = $1
+ORDER BY rr.severity DESC, r.created_at;
+
+-- name: CloseReportCase :one
+UPDATE report_cases
+SET
+    status = sqlc.arg(status),
+    resolved_by = sqlc.arg(resolved_by),
+    resolved_at = sqlc.arg(resolved_at),
+    suspend_days = sqlc.arg(suspend_days)
+WHERE case_id = sqlc.arg(case_id)
+AND status = 'open'
+RETURNING *;
+
+-- name: CloseCaseReports :execrows
+UPDATE reports
+SET
+    status = sqlc.arg(status),
+    reviewedby = sqlc.arg(reviewedby),
+    reviewed_at = sqlc.arg(reviewed_at),
+    suspend_days = sqlc.arg(suspend_days)
+WHERE case_id = sqlc.arg(case_id)
+AND status = 'pending';
+
+-- name: SettleReportCase :execrows
+UPDATE report_cases rc
+SET
+    status = CASE WHEN EXISTS (
+        SELECT 1 FROM reports r WHERE r.case_id = rc.case_id AND r.status = 'resolved'
+    ) THEN 'resolved' ELSE 'dismissed' END,
+    resolved_by = sqlc.arg(resolved_by),
+    resolved_at = sqlc.arg(resolved_at),
+    suspend_days = (SELECT MAX(r.suspend_days) FROM reports r WHERE r.case_id = rc.case_id)
+WHERE rc.case_id = sqlc.arg(case_id)
+AND rc.status = 'open'
+AND NOT EXISTS (
+    SELECT 1 FROM reports r WHERE r.case_id = rc.case_id AND r.status = 'pending'
+);
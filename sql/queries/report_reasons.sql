@@ -0,0 +1,52 @@
+-- name: ListReportReasons :many
+SELECT * FROM report_reasons
+WHERE active OR sqlc.arg(include_inactive)::boolean
+ORDER BY sort_order, code;
+
+-- name: GetReportReason :one
+SELECT * FROM report_reasons
+WHERE code = $1;
+
+-- name: CreateReportReason :one
+INSERT INTO report_reasons (
+    code,
+    label,
+    description,
+    severity,
+    sort_order,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $6
+)
+ON CONFLICT (code) DO NOTHING
+RETURNING *;
+
+-- name: UpdateReportReason :one
+UPDATE report_reasons
+SET
+    label = $2,
+    description = $3,
+    severity = $4,
+    active = $5,
+    sort_order = $6,
+    updated_at = $7
+WHERE code = $1
+RETURNING *;
+
+-- name: GetReportReasonStats :many
+SELECT
+    rr.code,
+    rr.label,
+    rr.severity,
+    COUNT(r.report_id) AS report_count,
+    COUNT(r.report_id) FILTER (WHERE r.status = 'pending') AS pending_count,
+    COUNT(r.report_id) FILTER (WHERE r.status = 'resolved') AS resolved_count,
+    COUNT(r.report_id) FILTER (WHERE r.status = 'dismissed') AS dismissed_count,
+    COUNT(DISTINCT r.case_id) AS case_count,
+    COUNT(DISTINCT r.reported_by) AS reporter_count
+FROM report_reasons rr
+LEFT JOIN reports r ON r.reason_code = rr.code
+    AND (sqlc.narg(since)::timestamp IS NULL OR r.created_at >= sqlc.narg(since)::timestamp)
+GROUP BY rr.code
+ORDER BY report_count DESC, rr.sort_order;
@@ -1,5 +1,7 @@
 -- name: CreateReport :one
-INSERT INTO reports (report_id, reported_by, target_post_id, target_user_id, target_comment_id, reason) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+INSERT INTO reports (report_id, reported_by, target_post_id, target_user_id, target_comment_id, reason, case_id, reason_code) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (case_id, reported_by) WHERE status = 'pending' DO NOTHING
+RETURNING *;
 
 -- name: UpdateReportStatus :one
 UPDATE reports SET status = $1, reviewedby = $2, reviewed_at = CURRENT_TIMESTAMP, suspend_days = $3 WHERE report_id = $4 RETURNING *;
@@ -14,6 +16,9 @@ SELECT
     r.reviewed_at,
     r.reviewedby,
     r.created_at,
+    r.reason_code,
+    rr.label AS reason_label,
+    rr.severity,
 
     -- Reporter Details
     ru.user_id AS reported_by_id,
@@ -44,8 +49,9 @@ INNER JOIN contributors ct ON tu.user_id = ct.user_id
 LEFT JOIN posts p ON r.target_post_id = p.post_id
 LEFT JOIN comments c ON r.target_comment_id = c.comment_id  
 LEFT JOIN moderators m ON r.reviewedby = m.moderator_id
+JOIN report_reasons rr ON r.reason_code = rr.code
 WHERE r.target_user_id IS NOT NULL
-ORDER BY r.created_at DESC;
+ORDER BY (r.status = 'pending') DESC, rr.severity DESC, r.created_at DESC;
 
 -- name: ListReportedUsers :many
 SELECT 
@@ -57,6 +63,9 @@ SELECT
     r.reviewed_at,
     r.reviewedby,
     r.created_at,
+    r.reason_code,
+    rr.label AS reason_label,
+    rr.severity,
 
     -- Reporter Details
     ru.user_id AS reported_by_id,
@@ -87,9 +96,10 @@ LEFT JOIN contributors ct ON tu.user_id = ct.user_id  -- Check if the user is a
 LEFT JOIN posts p ON r.target_post_id = p.post_id
 LEFT JOIN comments c ON r.target_comment_id = c.comment_id  
 LEFT JOIN moderators m ON r.reviewedby = m.moderator_id
+JOIN report_reasons rr ON r.reason_code = rr.code
 WHERE r.target_user_id IS NOT NULL
 AND ct.user_id IS NULL  -- Ensures reported user is NOT a contributor
-ORDER BY r.created_at DESC;
+ORDER BY (r.status = 'pending') DESC, rr.severity DESC, r.created_at DESC;
 
 -- name: GetResolvedReportsWithSuspensionByUserId :many
 SELECT DISTINCT ON (r.report_id)
@@ -141,6 +151,7 @@ SELECT
     r.reviewed_at,
     r.reviewedby,
     r.created_at,
-r.suspend_days
+r.suspend_days,
+    r.case_id
 FROM reports r
 WHERE r.report_id = $1;
\ No newline at end of file
@@ -0,0 +1,66 @@
+-- name: DeleteStaleReputationEntries :execrows
+DELETE FROM reputation_ledger
+WHERE NOT EXISTS (
+    SELECT 1 FROM reputation_sources
+    WHERE reputation_sources.source_key = reputation_ledger.source_key
+);
+
+-- name: SyncReputationEntries :execrows
+INSERT INTO reputation_ledger (source_key, user_id, reason, points, post_id, occurred_at, recorded_at)
+SELECT
+    reputation_sources.source_key,
+    reputation_sources.user_id,
+    reputation_sources.reason,
+    reputation_sources.points,
+    reputation_sources.post_id,
+    reputation_sources.occurred_at,
+    sqlc.arg(recorded_at)
+FROM reputation_sources
+ON CONFLICT (source_key) DO UPDATE SET points = EXCLUDED.points
+WHERE reputation_ledger.points <> EXCLUDED.points;
+
+-- name: GetReputationScore :one
+SELECT COALESCE(SUM(points), 0)::bigint AS score
+FROM reputation_ledger
+WHERE user_id = $1;
+
+-- name: GetReputationBreakdown :many
+SELECT
+    reason,
+    COUNT(*) AS events,
+    SUM(points)::bigint AS points
+FROM reputation_ledger
+WHERE user_id = $1
+GROUP BY reason
+ORDER BY reason;
+
+-- name: ListReputationEntries :many
+SELECT
+    reputation_ledger.reason,
+    reputation_ledger.points,
+    reputation_ledger.post_id,
+    posts.slug AS post_slug,
+    posts.title AS post_title,
+    reputation_ledger.occurred_at
+FROM reputation_ledger
+LEFT JOIN posts ON posts.post_id = reputation_ledger.post_id
+WHERE reputation_ledger.user_id = sqlc.arg(user_id)::uuid
+ORDER BY reputation_ledger.occurred_at DESC, reputation_ledger.source_key
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: GetLeaderboard :many
+SELECT
+    users.user_id,
+    users.name,
+    users.username,
+    SUM(reputation_ledger.points)::bigint AS score
+FROM reputation_ledger
+JOIN users ON users.user_id = reputation_ledger.user_id
+LEFT JOIN contributors ON contributors.user_id = reputation_ledger.user_id
+WHERE reputation_ledger.occurred_at >= sqlc.arg(since)
+  AND (sqlc.arg(expertise_field)::text = '' OR sqlc.arg(expertise_field)::text = ANY(contributors.expertise_fields))
+  AND reputation_ledger.user_id <> sqlc.arg(deleted_user_id)::uuid
+GROUP BY users.user_id, users.name, users.username
+HAVING SUM(reputation_ledger.points) > 0
+ORDER BY score DESC, users.username
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
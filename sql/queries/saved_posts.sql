@@ -28,5 +28,9 @@ LEFT JOIN (
     GROUP BY post_id
 ) c ON p.post_id = c.post_id
 WHERE s.user_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM content_takedowns t
+    WHERE t.post_id = p.post_id AND t.restored_at IS NULL AND t.action <> 'label'
+)
 ORDER BY p.created_at DESC;
 
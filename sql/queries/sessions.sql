@@ -0,0 +1,96 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+    session_id,
+    user_id,
+    moderator_id,
+    refresh_token_hash,
+    user_agent,
+    device,
+    ip_address,
+    location,
+    expires_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+RETURNING *;
+
+-- name: GetSessionByID :one
+SELECT *
+FROM sessions
+WHERE session_id = $1;
+
+-- name: GetSessionDeviceHistory :one
+SELECT 
+    EXISTS (
+        SELECT 1 FROM sessions s WHERE s.user_id = $1
+    ) AS has_any_session,
+    EXISTS (
+        SELECT 1 FROM sessions s WHERE s.user_id = $1 AND s.device = $2
+    ) AS has_device_session;
+
+-- name: ListActiveSessionsByOwner :many
+SELECT 
+    session_id,
+    device,
+    user_agent,
+    ip_address,
+    location,
+    created_at,
+    last_active_at,
+    expires_at
+FROM sessions
+WHERE (user_id = sqlc.arg(owner_id)::uuid OR moderator_id = sqlc.arg(owner_id)::uuid)
+AND revoked_at IS NULL
+AND expires_at > NOW()
+ORDER BY last_active_at DESC;
+
+-- name: RotateSessionRefreshToken :execrows
+UPDATE sessions
+SET
+    previous_refresh_token_hash = refresh_token_hash,
+    refresh_token_hash = sqlc.arg(refresh_token_hash),
+    rotated_at = sqlc.arg(rotated_at),
+    expires_at = sqlc.arg(expires_at),
+    ip_address = sqlc.arg(ip_address),
+    last_active_at = NOW()
+WHERE session_id = sqlc.arg(session_id)
+AND refresh_token_hash = sqlc.arg(used_refresh_token_hash);
+
+-- name: MarkRefreshTokenUpgraded :execrows
+INSERT INTO upgraded_refresh_tokens (token_hash)
+VALUES ($1)
+ON CONFLICT (token_hash) DO NOTHING;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_active_at = NOW()
+WHERE session_id = $1
+AND last_active_at < NOW() - INTERVAL '5 minutes';
+
+-- name: RevokeSession :exec
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE session_id = $1
+AND revoked_at IS NULL;
+
+-- name: RevokeOwnedSession :execrows
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE session_id = sqlc.arg(session_id)
+AND (user_id = sqlc.arg(owner_id)::uuid OR moderator_id = sqlc.arg(owner_id)::uuid)
+AND revoked_at IS NULL;
+
+-- name: RevokeOtherSessions :execrows
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE (user_id = sqlc.arg(owner_id)::uuid OR moderator_id = sqlc.arg(owner_id)::uuid)
+AND session_id <> sqlc.arg(current_session_id)
+AND revoked_at IS NULL;
@@ -0,0 +1,36 @@
+-- name: CreateSuspension :one
+INSERT INTO suspensions(suspension_id, user_id, case_id, report_id, issued_by, starts_at, ends_at, reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: LiftReportSuspensions :many
+UPDATE suspensions
+SET lifted_by = $3, lifted_by_appeal = $4, lifted_at = $5
+WHERE lifted_at IS NULL
+AND (report_id = $1 OR (case_id = $2 AND report_id IS NULL))
+RETURNING *;
+
+-- name: LiftUnsourcedSuspension :one
+UPDATE suspensions
+SET lifted_by = $3, lifted_at = $4
+WHERE suspension_id = $1 AND user_id = $2
+AND case_id IS NULL AND report_id IS NULL
+AND lifted_at IS NULL
+RETURNING *;
+
+-- name: ListSuspensionsByUser :many
+SELECT * FROM suspensions
+WHERE user_id = $1
+ORDER BY starts_at DESC;
+
+-- name: RefreshUserSuspension :one
+UPDATE users
+SET suspended_until = (
+    SELECT CASE WHEN bool_or(s.ends_at IS NULL) THEN sqlc.arg(ban_until)::timestamp ELSE MAX(s.ends_at) END
+    FROM suspensions s
+    WHERE s.user_id = sqlc.arg(user_id)
+    AND s.lifted_at IS NULL
+    AND (s.ends_at IS NULL OR s.ends_at > sqlc.arg(now))
+)
+WHERE user_id = sqlc.arg(user_id)
+RETURNING suspended_until;
@@ -0,0 +1,33 @@
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+    identity_id,
+    user_id,
+    provider,
+    subject,
+    email,
+    email_verified
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetUserIdentity :one
+SELECT *
+FROM user_identities
+WHERE provider = $1 AND subject = $2;
+
+-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET last_login_at = NOW()
+WHERE identity_id = $1;
+
+-- name: ListUserIdentitiesByUser :many
+SELECT *
+FROM user_identities
+WHERE user_id = $1
+ORDER BY created_at ASC;
@@ -0,0 +1,29 @@
+-- name: GetUserProfile :one
+SELECT *
+FROM user_profiles
+WHERE user_id = $1;
+
+-- name: UpsertUserProfile :one
+INSERT INTO user_profiles (user_id, bio, headline, location, website, pronouns, social_links, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET bio = EXCLUDED.bio,
+    headline = EXCLUDED.headline,
+    location = EXCLUDED.location,
+    website = EXCLUDED.website,
+    pronouns = EXCLUDED.pronouns,
+    social_links = EXCLUDED.social_links,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: SetUserAvatar :exec
+INSERT INTO user_profiles (user_id, avatar_url, updated_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET avatar_url = EXCLUDED.avatar_url,
+    updated_at = NOW();
+
+-- name: GetUserProfileStats :one
+SELECT
+    (SELECT COUNT(*) FROM posts p WHERE p.user_id = $1) AS post_count,
+    (SELECT COUNT(*) FROM upvotes u JOIN posts p ON u.post_id = p.post_id WHERE p.user_id = $1) AS upvotes_received;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE api_keys (
+    api_key_id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(user_id) ON DELETE CASCADE,
+    moderator_id UUID REFERENCES moderators(moderator_id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    key_prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (num_nonnulls(user_id, moderator_id) = 1)
+);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys(user_id);
+CREATE INDEX api_keys_moderator_id_idx ON api_keys(moderator_id);
+
+-- +goose Down
+DROP TABLE api_keys;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE sessions (
+    session_id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(user_id) ON DELETE CASCADE,
+    moderator_id UUID REFERENCES moderators(moderator_id) ON DELETE CASCADE,
+    refresh_token_hash TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    device TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    location TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_active_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    CHECK (num_nonnulls(user_id, moderator_id) = 1)
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions(user_id);
+CREATE INDEX sessions_moderator_id_idx ON sessions(moderator_id);
+
+-- +goose Down
+DROP TABLE sessions;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE notifications (
+    notification_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    message TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX notifications_user_id_created_at_idx ON notifications(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE notifications;
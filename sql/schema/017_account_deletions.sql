@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE account_deletions (
+    user_id UUID PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    requested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    scheduled_for TIMESTAMP NOT NULL
+);
+
+-- Comments written by deleted accounts are reassigned to this placeholder so
+-- replies underneath them keep their place in the thread.
+INSERT INTO users (user_id, name, email, username, password)
+VALUES ('00000000-0000-0000-0000-000000000001', '[deleted]', 'deleted@expertly.invalid', '[deleted]', '')
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- The placeholder stays: deleting it would cascade to every anonymized comment.
+DROP TABLE account_deletions;
@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE impersonations (
+    impersonation_id UUID PRIMARY KEY,
+    moderator_id UUID NOT NULL REFERENCES moderators(moderator_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    read_only BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP
+);
+
+CREATE INDEX idx_impersonations_user_id ON impersonations(user_id);
+
+CREATE TABLE impersonation_requests (
+    request_id UUID PRIMARY KEY,
+    impersonation_id UUID NOT NULL REFERENCES impersonations(impersonation_id) ON DELETE CASCADE,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    status_code INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_impersonation_requests_impersonation_id ON impersonation_requests(impersonation_id);
+
+-- +goose Down
+DROP TABLE impersonation_requests;
+DROP TABLE impersonations;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE privacy_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    saved_posts_visibility TEXT NOT NULL DEFAULT 'private' CHECK (saved_posts_visibility IN ('public', 'followers', 'private')),
+    follows_visibility TEXT NOT NULL DEFAULT 'public' CHECK (follows_visibility IN ('public', 'followers', 'private')),
+    email_visibility TEXT NOT NULL DEFAULT 'private' CHECK (email_visibility IN ('public', 'followers', 'private')),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE privacy_settings;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE following ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE following DROP COLUMN created_at;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Precomputed "who to follow" recommendations, rebuilt periodically by the
+-- refresh-follow-suggestions job.
+CREATE TABLE follow_suggestions (
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    suggested_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    mutual_follows INT NOT NULL DEFAULT 0,
+    shared_expertise INT NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, suggested_user_id),
+    CHECK (user_id <> suggested_user_id)
+);
+
+CREATE INDEX idx_follow_suggestions_user_score ON follow_suggestions(user_id, score DESC);
+
+-- +goose Down
+DROP TABLE follow_suggestions;
@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE post_tags (
+    post_id UUID NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (post_id, tag)
+);
+
+CREATE INDEX idx_post_tags_tag ON post_tags(tag);
+
+-- Full-text similarity between posts is computed against this expression.
+CREATE INDEX idx_posts_document ON posts USING GIN (to_tsvector('english', title || ' ' || content));
+
+-- One row per post whose related posts have been computed; deleting it
+-- invalidates the cached list.
+CREATE TABLE related_posts_cache (
+    post_id UUID PRIMARY KEY REFERENCES posts(post_id) ON DELETE CASCADE,
+    computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE related_posts (
+    post_id UUID NOT NULL REFERENCES related_posts_cache(post_id) ON DELETE CASCADE,
+    related_post_id UUID NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    shared_tags INT NOT NULL DEFAULT 0,
+    same_author BOOLEAN NOT NULL DEFAULT FALSE,
+    co_upvotes INT NOT NULL DEFAULT 0,
+    text_similarity DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, related_post_id),
+    CHECK (post_id <> related_post_id)
+);
+
+-- +goose Down
+DROP TABLE related_posts;
+DROP TABLE related_posts_cache;
+DROP INDEX idx_posts_document;
+DROP TABLE post_tags;
@@ -0,0 +1,67 @@
+-- +goose Up
+-- Every event that currently earns or costs a user reputation, with its point
+-- value. The refresh-reputation job syncs reputation_ledger from this view, so
+-- changing a weight here re-scores history on the next run.
+CREATE VIEW reputation_sources AS
+SELECT
+    'upvote:' || upvotes.post_id || ':' || upvotes.user_id AS source_key,
+    posts.user_id,
+    'upvote_received' AS reason,
+    10 AS points,
+    posts.post_id,
+    COALESCE(upvotes.created_at, posts.created_at, CURRENT_TIMESTAMP) AS occurred_at
+FROM upvotes
+JOIN posts ON posts.post_id = upvotes.post_id
+WHERE upvotes.user_id <> posts.user_id
+UNION ALL
+SELECT
+    'comment:' || comments.comment_id,
+    posts.user_id,
+    'comment_received',
+    2,
+    posts.post_id,
+    comments.created_at
+FROM comments
+JOIN posts ON posts.post_id = comments.post_id
+WHERE comments.user_id <> posts.user_id
+UNION ALL
+SELECT
+    'application:' || contributor_applications.contri_app_id,
+    contributor_applications.user_id,
+    'contribution_accepted',
+    50,
+    NULL::uuid,
+    COALESCE(contributor_applications.reviewed_at, contributor_applications.created_at, CURRENT_TIMESTAMP)
+FROM contributor_applications
+WHERE contributor_applications.status = 'approved'
+UNION ALL
+SELECT
+    'report:' || reports.report_id,
+    reports.target_user_id,
+    'report_upheld',
+    -50,
+    reports.target_post_id,
+    COALESCE(reports.reviewed_at, reports.created_at, CURRENT_TIMESTAMP)
+FROM reports
+WHERE reports.status = 'resolved'
+  AND NOT EXISTS (
+      SELECT 1 FROM appeals
+      WHERE appeals.target_report_id = reports.report_id AND appeals.status = 'resolved'
+  );
+
+CREATE TABLE reputation_ledger (
+    source_key TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    points INT NOT NULL,
+    post_id UUID REFERENCES posts(post_id) ON DELETE SET NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reputation_ledger_user ON reputation_ledger(user_id, occurred_at DESC);
+CREATE INDEX idx_reputation_ledger_occurred_at ON reputation_ledger(occurred_at);
+
+-- +goose Down
+DROP TABLE reputation_ledger;
+DROP VIEW reputation_sources;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Badges a user has earned. Definitions live in code (badges.Definitions);
+-- field is the expertise field for per-field badges and '' otherwise.
+CREATE TABLE user_badges (
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    badge TEXT NOT NULL,
+    field TEXT NOT NULL DEFAULT '',
+    awarded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, badge, field)
+);
+
+-- +goose Down
+DROP TABLE user_badges;
@@ -0,0 +1,72 @@
+-- +goose Up
+-- Curated list of fields a contributor can be verified in.
+CREATE TABLE expertise_fields (
+    slug TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    category TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO expertise_fields (slug, name, category) VALUES
+    ('software-engineering', 'Software Engineering', 'Technology'),
+    ('web-development', 'Web Development', 'Technology'),
+    ('mobile-development', 'Mobile Development', 'Technology'),
+    ('data-science', 'Data Science', 'Technology'),
+    ('machine-learning', 'Machine Learning', 'Technology'),
+    ('cybersecurity', 'Cybersecurity', 'Technology'),
+    ('cloud-infrastructure', 'Cloud Infrastructure', 'Technology'),
+    ('product-design', 'Product Design', 'Design'),
+    ('graphic-design', 'Graphic Design', 'Design'),
+    ('medicine', 'Medicine', 'Health'),
+    ('nutrition', 'Nutrition', 'Health'),
+    ('mental-health', 'Mental Health', 'Health'),
+    ('finance', 'Finance', 'Business'),
+    ('accounting', 'Accounting', 'Business'),
+    ('marketing', 'Marketing', 'Business'),
+    ('entrepreneurship', 'Entrepreneurship', 'Business'),
+    ('law', 'Law', 'Society'),
+    ('education', 'Education', 'Society'),
+    ('economics', 'Economics', 'Society'),
+    ('physics', 'Physics', 'Science'),
+    ('chemistry', 'Chemistry', 'Science'),
+    ('biology', 'Biology', 'Science'),
+    ('mathematics', 'Mathematics', 'Science'),
+    ('environmental-science', 'Environmental Science', 'Science');
+
+-- Fields the applicant asks to be verified in; expertise_proofs keeps the
+-- supporting links.
+ALTER TABLE contributor_applications ADD COLUMN requested_fields TEXT[] NOT NULL DEFAULT '{}';
+
+-- Fields a moderator verified for a contributor. contributors.expertise_fields
+-- mirrors the ones that haven't expired.
+CREATE TABLE contributor_expertise (
+    user_id UUID NOT NULL REFERENCES contributors(user_id) ON DELETE CASCADE,
+    field TEXT NOT NULL REFERENCES expertise_fields(slug),
+    verification_level TEXT NOT NULL CHECK (verification_level IN ('legacy', 'basic', 'verified', 'expert')),
+    application_id UUID REFERENCES contributor_applications(contri_app_id) ON DELETE SET NULL,
+    verified_by UUID REFERENCES moderators(moderator_id) ON DELETE SET NULL,
+    verified_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    PRIMARY KEY (user_id, field)
+);
+
+-- Approval used to copy the proof links into expertise_fields; keep only the
+-- entries that are real fields.
+UPDATE contributors
+SET expertise_fields = ARRAY(
+    SELECT field FROM unnest(contributors.expertise_fields) AS field
+    WHERE field IN (SELECT slug FROM expertise_fields)
+);
+
+-- Contributors approved before verification keep the fields they had, marked
+-- legacy since no moderator checked them against the taxonomy.
+INSERT INTO contributor_expertise (user_id, field, verification_level, verified_at)
+SELECT contributors.user_id, field, 'legacy', COALESCE(contributors.created_at, CURRENT_TIMESTAMP)
+FROM contributors
+CROSS JOIN LATERAL unnest(contributors.expertise_fields) AS field
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE contributor_expertise;
+ALTER TABLE contributor_applications DROP COLUMN requested_fields;
+DROP TABLE expertise_fields;
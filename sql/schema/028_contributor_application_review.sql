@@ -0,0 +1,36 @@
+-- +goose Up
+-- Applications move pending -> under_review -> approved / rejected /
+-- needs_more_info; needs_more_info goes back to pending when the applicant
+-- updates it.
+ALTER TABLE contributor_applications DROP CONSTRAINT contributor_applications_status_check;
+ALTER TABLE contributor_applications ADD CONSTRAINT contributor_applications_status_check
+    CHECK (status IN ('pending', 'under_review', 'approved', 'rejected', 'needs_more_info'));
+
+ALTER TABLE contributor_applications ADD COLUMN reviewer_notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE contributor_applications ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Only the newest of any duplicate pending applications stays open. The
+-- superseded ones keep their reviewed_at, so they don't start a cooldown.
+UPDATE contributor_applications ca
+SET status = 'rejected',
+    reviewer_notes = 'Superseded by a newer application'
+WHERE ca.status = 'pending'
+  AND EXISTS (
+      SELECT 1 FROM contributor_applications newer
+      WHERE newer.user_id = ca.user_id
+        AND newer.status = 'pending'
+        AND (newer.created_at, newer.contri_app_id) > (ca.created_at, ca.contri_app_id)
+  );
+
+CREATE UNIQUE INDEX idx_contributor_applications_one_open
+    ON contributor_applications(user_id)
+    WHERE status IN ('pending', 'under_review', 'needs_more_info');
+
+-- +goose Down
+DROP INDEX idx_contributor_applications_one_open;
+ALTER TABLE contributor_applications DROP COLUMN updated_at;
+ALTER TABLE contributor_applications DROP COLUMN reviewer_notes;
+UPDATE contributor_applications SET status = 'pending' WHERE status IN ('under_review', 'needs_more_info');
+ALTER TABLE contributor_applications DROP CONSTRAINT contributor_applications_status_check;
+ALTER TABLE contributor_applications ADD CONSTRAINT contributor_applications_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected'));
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Files uploaded to back a contributor application. The bytes live in
+-- private storage under storage_key; only reviewing moderators get signed
+-- URLs for them. Rows outlive their user or application (SET NULL) so the
+-- purge job can still delete the stored file.
+CREATE TABLE application_documents (
+    document_id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    application_id UUID REFERENCES contributor_applications(contri_app_id) ON DELETE SET NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('identity', 'expertise')),
+    storage_key TEXT NOT NULL UNIQUE,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    original_name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Set when the application is decided; identity documents are deleted
+    -- once it passes.
+    delete_after TIMESTAMP
+);
+
+CREATE INDEX idx_application_documents_application ON application_documents(application_id);
+CREATE INDEX idx_application_documents_user ON application_documents(user_id);
+
+-- +goose Down
+DROP TABLE application_documents;
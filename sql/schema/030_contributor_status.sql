@@ -0,0 +1,33 @@
+-- +goose Up
+-- Contributor rights can be suspended (optionally until a date), revoked,
+-- or lapse when expires_at passes. Only active contributors can post.
+ALTER TABLE contributors ADD COLUMN status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'suspended', 'revoked', 'expired'));
+ALTER TABLE contributors ADD COLUMN status_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE contributors ADD COLUMN suspended_until TIMESTAMP;
+ALTER TABLE contributors ADD COLUMN expires_at TIMESTAMP;
+ALTER TABLE contributors ADD COLUMN renewal_reminded_at TIMESTAMP;
+
+-- Every status or expiry change; changed_by is NULL for changes made by the
+-- expiry job.
+CREATE TABLE contributor_status_history (
+    history_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES contributors(user_id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_by UUID REFERENCES moderators(moderator_id) ON DELETE SET NULL,
+    suspended_until TIMESTAMP,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_contributor_status_history_user ON contributor_status_history(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE contributor_status_history;
+ALTER TABLE contributors DROP COLUMN renewal_reminded_at;
+ALTER TABLE contributors DROP COLUMN expires_at;
+ALTER TABLE contributors DROP COLUMN suspended_until;
+ALTER TABLE contributors DROP COLUMN status_reason;
+ALTER TABLE contributors DROP COLUMN status;
@@ -0,0 +1,210 @@
+-- +goose Up
+-- A case groups every report on the same post, comment or user so
+-- moderators handle it once. target_key identifies the target; only one case
+-- per target is open at a time, and a reporter can have only one pending
+-- report in it.
+CREATE TABLE report_cases (
+    case_id UUID PRIMARY KEY,
+    target_key TEXT NOT NULL,
+    target_type TEXT NOT NULL CHECK (target_type IN ('post', 'comment', 'user')),
+    target_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    target_post_id UUID REFERENCES posts(post_id) ON DELETE CASCADE,
+    target_comment_id UUID REFERENCES comments(comment_id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    resolved_by UUID REFERENCES moderators(moderator_id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    suspend_days INT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_reported_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_report_cases_one_open ON report_cases(target_key) WHERE status = 'open';
+CREATE INDEX idx_report_cases_status ON report_cases(status, last_reported_at DESC);
+
+ALTER TABLE reports ADD COLUMN case_id UUID REFERENCES report_cases(case_id) ON DELETE CASCADE;
+
+-- Pending reports on the same target share an open case, keyed by the
+-- earliest report's ID.
+WITH keyed AS (
+    SELECT
+        r.*,
+        CASE
+            WHEN r.target_comment_id IS NOT NULL THEN 'comment:' || r.target_comment_id
+            WHEN r.target_post_id IS NOT NULL THEN 'post:' || r.target_post_id
+            ELSE 'user:' || r.target_user_id
+        END AS target_key
+    FROM reports r
+    WHERE r.status = 'pending' OR r.status IS NULL
+),
+firsts AS (
+    SELECT DISTINCT ON (target_key) *
+    FROM keyed
+    ORDER BY target_key, created_at, report_id
+)
+INSERT INTO report_cases (case_id, target_key, target_type, target_user_id, target_post_id, target_comment_id, created_at, last_reported_at)
+SELECT
+    f.report_id,
+    f.target_key,
+    split_part(f.target_key, ':', 1),
+    f.target_user_id,
+    f.target_post_id,
+    f.target_comment_id,
+    COALESCE(f.created_at, CURRENT_TIMESTAMP),
+    (SELECT COALESCE(MAX(k.created_at), CURRENT_TIMESTAMP) FROM keyed k WHERE k.target_key = f.target_key)
+FROM firsts f;
+
+UPDATE reports r
+SET case_id = rc.case_id
+FROM report_cases rc
+WHERE (r.status = 'pending' OR r.status IS NULL)
+  AND rc.target_key = CASE
+      WHEN r.target_comment_id IS NOT NULL THEN 'comment:' || r.target_comment_id
+      WHEN r.target_post_id IS NOT NULL THEN 'post:' || r.target_post_id
+      ELSE 'user:' || r.target_user_id
+  END;
+
+-- Reports that were already decided each become a closed case of their own.
+INSERT INTO report_cases (case_id, target_key, target_type, target_user_id, target_post_id, target_comment_id, status, resolved_by, resolved_at, suspend_days, created_at, last_reported_at)
+SELECT
+    r.report_id,
+    CASE
+        WHEN r.target_comment_id IS NOT NULL THEN 'comment:' || r.target_comment_id
+        WHEN r.target_post_id IS NOT NULL THEN 'post:' || r.target_post_id
+        ELSE 'user:' || r.target_user_id
+    END,
+    CASE
+        WHEN r.target_comment_id IS NOT NULL THEN 'comment'
+        WHEN r.target_post_id IS NOT NULL THEN 'post'
+        ELSE 'user'
+    END,
+    r.target_user_id,
+    r.target_post_id,
+    r.target_comment_id,
+    r.status,
+    r.reviewedby,
+    r.reviewed_at,
+    r.suspend_days,
+    COALESCE(r.created_at, CURRENT_TIMESTAMP),
+    COALESCE(r.created_at, CURRENT_TIMESTAMP)
+FROM reports r
+WHERE r.case_id IS NULL;
+
+UPDATE reports SET case_id = report_id WHERE case_id IS NULL;
+
+-- Repeat reports from the same reporter are dismissed; the earliest stays.
+UPDATE reports r
+SET status = 'dismissed', reviewed_at = CURRENT_TIMESTAMP
+WHERE r.status = 'pending'
+  AND EXISTS (
+      SELECT 1 FROM reports earlier
+      WHERE earlier.case_id = r.case_id
+        AND earlier.reported_by = r.reported_by
+        AND earlier.status = 'pending'
+        AND (earlier.created_at, earlier.report_id) < (r.created_at, r.report_id)
+  );
+
+ALTER TABLE reports ALTER COLUMN case_id SET NOT NULL;
+CREATE UNIQUE INDEX idx_reports_one_pending_per_reporter ON reports(case_id, reported_by) WHERE status = 'pending';
+
+-- An upheld case costs its target reputation once, however many reports it
+-- has. Cases backfilled from single reports keep the report's ID, so their
+-- ledger entries carry over.
+CREATE OR REPLACE VIEW reputation_sources AS
+SELECT
+    'upvote:' || upvotes.post_id || ':' || upvotes.user_id AS source_key,
+    posts.user_id,
+    'upvote_received' AS reason,
+    10 AS points,
+    posts.post_id,
+    COALESCE(upvotes.created_at, posts.created_at, CURRENT_TIMESTAMP) AS occurred_at
+FROM upvotes
+JOIN posts ON posts.post_id = upvotes.post_id
+WHERE upvotes.user_id <> posts.user_id
+UNION ALL
+SELECT
+    'comment:' || comments.comment_id,
+    posts.user_id,
+    'comment_received',
+    2,
+    posts.post_id,
+    comments.created_at
+FROM comments
+JOIN posts ON posts.post_id = comments.post_id
+WHERE comments.user_id <> posts.user_id
+UNION ALL
+SELECT
+    'application:' || contributor_applications.contri_app_id,
+    contributor_applications.user_id,
+    'contribution_accepted',
+    50,
+    NULL::uuid,
+    COALESCE(contributor_applications.reviewed_at, contributor_applications.created_at, CURRENT_TIMESTAMP)
+FROM contributor_applications
+WHERE contributor_applications.status = 'approved'
+UNION ALL
+SELECT
+    'report:' || report_cases.case_id,
+    report_cases.target_user_id,
+    'report_upheld',
+    -50,
+    report_cases.target_post_id,
+    COALESCE(report_cases.resolved_at, report_cases.created_at)
+FROM report_cases
+WHERE report_cases.status = 'resolved'
+  AND NOT EXISTS (
+      SELECT 1 FROM appeals
+      JOIN reports ON reports.report_id = appeals.target_report_id
+      WHERE reports.case_id = report_cases.case_id AND appeals.status = 'resolved'
+  );
+
+-- +goose Down
+CREATE OR REPLACE VIEW reputation_sources AS
+SELECT
+    'upvote:' || upvotes.post_id || ':' || upvotes.user_id AS source_key,
+    posts.user_id,
+    'upvote_received' AS reason,
+    10 AS points,
+    posts.post_id,
+    COALESCE(upvotes.created_at, posts.created_at, CURRENT_TIMESTAMP) AS occurred_at
+FROM upvotes
+JOIN posts ON posts.post_id = upvotes.post_id
+WHERE upvotes.user_id <> posts.user_id
+UNION ALL
+SELECT
+    'comment:' || comments.comment_id,
+    posts.user_id,
+    'comment_received',
+    2,
+    posts.post_id,
+    comments.created_at
+FROM comments
+JOIN posts ON posts.post_id = comments.post_id
+WHERE comments.user_id <> posts.user_id
+UNION ALL
+SELECT
+    'application:' || contributor_applications.contri_app_id,
+    contributor_applications.user_id,
+    'contribution_accepted',
+    50,
+    NULL::uuid,
+    COALESCE(contributor_applications.reviewed_at, contributor_applications.created_at, CURRENT_TIMESTAMP)
+FROM contributor_applications
+WHERE contributor_applications.status = 'approved'
+UNION ALL
+SELECT
+    'report:' || reports.report_id,
+    reports.target_user_id,
+    'report_upheld',
+    -50,
+    reports.target_post_id,
+    COALESCE(reports.reviewed_at, reports.created_at, CURRENT_TIMESTAMP)
+FROM reports
+WHERE reports.status = 'resolved'
+  AND NOT EXISTS (
+      SELECT 1 FROM appeals
+      WHERE appeals.target_report_id = reports.report_id AND appeals.status = 'resolved'
+  );
+
+DROP INDEX idx_reports_one_pending_per_reporter;
+ALTER TABLE reports DROP COLUMN case_id;
+DROP TABLE report_cases;
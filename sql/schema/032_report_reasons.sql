@@ -0,0 +1,31 @@
+-- +goose Up
+-- Moderator-configurable reasons a report can be filed under. severity runs
+-- from 1 (low) to 4 (critical) and orders the moderation queues; inactive
+-- reasons can't be chosen for new reports.
+CREATE TABLE report_reasons (
+    code TEXT PRIMARY KEY,
+    label TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    severity INT NOT NULL CHECK (severity BETWEEN 1 AND 4),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO report_reasons (code, label, description, severity, sort_order) VALUES
+    ('spam', 'Spam', 'Advertising, scams or repetitive content', 1, 10),
+    ('harassment', 'Harassment', 'Bullying, threats or targeted abuse', 3, 20),
+    ('misinformation', 'Misinformation', 'False or misleading claims', 2, 30),
+    ('medical_misinformation', 'Medical misinformation', 'False health or medical claims that could cause harm', 4, 40),
+    ('copyright', 'Copyright', 'Content used without the owner''s permission', 2, 50),
+    ('impersonation', 'Impersonation', 'Pretending to be another person or organisation', 3, 60),
+    ('other', 'Other', 'Something else; describe it in the details', 1, 100);
+
+-- reports.reason stays as the reporter's optional free-text detail.
+ALTER TABLE reports ADD COLUMN reason_code TEXT NOT NULL DEFAULT 'other' REFERENCES report_reasons(code);
+CREATE INDEX idx_reports_reason_code ON reports(reason_code, created_at);
+
+-- +goose Down
+ALTER TABLE reports DROP COLUMN reason_code;
+DROP TABLE report_reasons;
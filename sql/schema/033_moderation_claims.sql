@@ -0,0 +1,19 @@
+-- +goose Up
+-- A moderator claims a queue item (a report case, appeal or contributor
+-- application) while working it. Claims lapse at expires_at so abandoned
+-- items return to the queue; assigned_by is set when someone else handed the
+-- item to claimed_by.
+CREATE TABLE moderation_claims(
+    item_type TEXT NOT NULL CHECK (item_type IN ('report', 'appeal', 'application')),
+    item_id UUID NOT NULL,
+    claimed_by UUID NOT NULL REFERENCES moderators(moderator_id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES moderators(moderator_id) ON DELETE SET NULL,
+    claimed_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (item_type, item_id)
+);
+
+CREATE INDEX idx_moderation_claims_claimed_by ON moderation_claims(claimed_by, expires_at);
+
+-- +goose Down
+DROP TABLE moderation_claims;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- An append-only record of every moderator action. subject_user_id is the
+-- user the action was about; it has no foreign key so the history outlives
+-- purged accounts.
+CREATE TABLE moderation_actions(
+    action_id UUID PRIMARY KEY,
+    moderator_id UUID REFERENCES moderators(moderator_id),
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id UUID NOT NULL,
+    subject_user_id UUID,
+    before_state JSONB NOT NULL DEFAULT '{}',
+    after_state JSONB NOT NULL DEFAULT '{}',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_moderation_actions_created_at ON moderation_actions(created_at DESC);
+CREATE INDEX idx_moderation_actions_subject ON moderation_actions(subject_user_id, created_at DESC);
+CREATE INDEX idx_moderation_actions_moderator ON moderation_actions(moderator_id, created_at DESC);
+CREATE INDEX idx_moderation_actions_target ON moderation_actions(target_type, target_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION reject_moderation_action_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'moderation_actions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER moderation_actions_append_only
+    BEFORE UPDATE OR DELETE ON moderation_actions
+    FOR EACH ROW EXECUTE FUNCTION reject_moderation_action_change();
+
+CREATE TRIGGER moderation_actions_no_truncate
+    BEFORE TRUNCATE ON moderation_actions
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_moderation_action_change();
+
+-- +goose Down
+DROP TABLE moderation_actions;
+DROP FUNCTION reject_moderation_action_change();
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Moderators act on reported posts and comments when resolving a case:
+-- hide and remove take the content out of listings and show readers a
+-- tombstone instead; label keeps it visible with a warning. A takedown is
+-- active until restored_at is set, which happens when an appeal is upheld.
+CREATE TABLE content_takedowns(
+    takedown_id UUID PRIMARY KEY,
+    case_id UUID NOT NULL REFERENCES report_cases(case_id) ON DELETE CASCADE,
+    post_id UUID REFERENCES posts(post_id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES comments(comment_id) ON DELETE CASCADE,
+    action TEXT NOT NULL CHECK (action IN ('hide', 'remove', 'label')),
+    label TEXT NOT NULL DEFAULT '',
+    taken_down_by UUID NOT NULL REFERENCES moderators(moderator_id),
+    created_at TIMESTAMP NOT NULL,
+    restored_by UUID REFERENCES moderators(moderator_id),
+    restored_at TIMESTAMP,
+    CHECK ((post_id IS NULL) <> (comment_id IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_content_takedowns_active_post
+    ON content_takedowns(post_id) WHERE restored_at IS NULL;
+CREATE UNIQUE INDEX idx_content_takedowns_active_comment
+    ON content_takedowns(comment_id) WHERE restored_at IS NULL;
+CREATE INDEX idx_content_takedowns_case ON content_takedowns(case_id);
+
+-- +goose Down
+DROP TABLE content_takedowns;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- Resolved report cases give the reported user a strike. Strikes count
+-- towards the enforcement policy until they expire or are revoked by an
+-- upheld appeal.
+CREATE TABLE strikes(
+    strike_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    case_id UUID NOT NULL UNIQUE REFERENCES report_cases(case_id) ON DELETE CASCADE,
+    issued_by UUID NOT NULL REFERENCES moderators(moderator_id),
+    penalty TEXT NOT NULL CHECK (penalty IN ('none', 'warning', 'suspension', 'ban')),
+    suspend_days INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_by UUID REFERENCES moderators(moderator_id),
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_strikes_user ON strikes(user_id, expires_at);
+
+-- The single-row enforcement policy. levels maps strike counts to
+-- penalties: [{"strikes": 2, "penalty": "suspension", "suspend_days": 1}, ...];
+-- a user gets the penalty of the highest level their active strikes reach.
+CREATE TABLE enforcement_policy(
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    strike_expiry_days INT NOT NULL CHECK (strike_expiry_days > 0),
+    levels JSONB NOT NULL,
+    updated_by UUID REFERENCES moderators(moderator_id),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO enforcement_policy(strike_expiry_days, levels) VALUES (90, '[
+    {"strikes": 1, "penalty": "warning"},
+    {"strikes": 2, "penalty": "suspension", "suspend_days": 1},
+    {"strikes": 3, "penalty": "suspension", "suspend_days": 7},
+    {"strikes": 4, "penalty": "suspension", "suspend_days": 30},
+    {"strikes": 5, "penalty": "ban"}
+]');
+
+-- +goose Down
+DROP TABLE enforcement_policy;
+DROP TABLE strikes;
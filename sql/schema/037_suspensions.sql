@@ -0,0 +1,34 @@
+-- +goose Up
+-- Each suspension is its own row, so lifting one (e.g. on appeal) leaves the
+-- others in place. users.suspended_until is kept as the latest end among the
+-- active rows; a NULL ends_at is a permanent ban. All times are UTC.
+CREATE TABLE suspensions(
+    suspension_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    case_id UUID REFERENCES report_cases(case_id) ON DELETE SET NULL,
+    report_id UUID REFERENCES reports(report_id) ON DELETE SET NULL,
+    issued_by UUID REFERENCES moderators(moderator_id),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP,
+    reason TEXT NOT NULL DEFAULT '',
+    lifted_by UUID REFERENCES moderators(moderator_id),
+    lifted_by_appeal UUID REFERENCES appeals(appeal_id) ON DELETE SET NULL,
+    lifted_at TIMESTAMP,
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX idx_suspensions_user ON suspensions(user_id) WHERE lifted_at IS NULL;
+CREATE INDEX idx_suspensions_case ON suspensions(case_id);
+
+-- Carry over suspensions that are still running, without a source. No appeal
+-- can lift these, so moderators lift them by hand.
+INSERT INTO suspensions(suspension_id, user_id, starts_at, ends_at)
+SELECT md5(user_id::text || 'suspension')::uuid,
+       user_id,
+       LEAST(timezone('UTC', now()), suspended_until - INTERVAL '1 second'),
+       CASE WHEN suspended_until >= '9999-12-31' THEN NULL ELSE suspended_until END
+FROM users
+WHERE suspended_until > timezone('UTC', now());
+
+-- +goose Down
+DROP TABLE suspensions;
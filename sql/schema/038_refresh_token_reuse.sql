@@ -0,0 +1,17 @@
+-- +goose Up
+-- The refresh token a session rotated away from, and when, so a second tab
+-- refreshing with it moments later isn't mistaken for a stolen token.
+ALTER TABLE sessions ADD COLUMN previous_refresh_token_hash TEXT;
+ALTER TABLE sessions ADD COLUMN rotated_at TIMESTAMP;
+
+-- Refresh tokens from before sessions were persisted that have already been
+-- traded for a session, so each one can only be traded once.
+CREATE TABLE upgraded_refresh_tokens (
+    token_hash TEXT PRIMARY KEY,
+    upgraded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE upgraded_refresh_tokens;
+ALTER TABLE sessions DROP COLUMN rotated_at;
+ALTER TABLE sessions DROP COLUMN previous_refresh_token_hash;
@@ -0,0 +1,60 @@
+// Package storage keeps private files (such as contributor application
+// documents) out of public hosting. Files are only ever handed out through
+// short-lived signed URLs.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+type Storage interface {
+	// Put stores size bytes read from body under key, replacing any existing
+	// file.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that can fetch the file until expiry passes.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// FromEnv builds the backend named by STORAGE_BACKEND: "local" for
+// development, or "s3" for any S3-compatible service. There is no default,
+// since local files don't survive on serverless hosts. Local storage signs
+// its URLs with STORAGE_SIGNING_KEY.
+func FromEnv() (Storage, error) {
+	godotenv.Load(".env")
+
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "":
+		return nil, errors.New("STORAGE_BACKEND is not set")
+	case "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		baseURL := os.Getenv("STORAGE_LOCAL_BASE_URL")
+		if baseURL == "" {
+			baseURL = "http://localhost:8080/api/files"
+		}
+		return NewLocal(dir, baseURL, []byte(os.Getenv("STORAGE_SIGNING_KEY")))
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix marks a bearer credential as a personal API key rather than a JWT.
+const APIKeyPrefix = "exk_"
+
+// APIKeyResources lists the route groups an API key can be scoped to. Each
+// resource has a ":read" scope for GET requests and a ":write" scope for
+// everything else.
+var APIKeyResources = []string{
+	"posts",
+	"comments",
+	"upvotes",
+	"follows",
+	"blocks",
+	"feed",
+	"saved-posts",
+	"profile",
+	"search",
+	"reports",
+	"appeals",
+	"applications",
+	"admin",
+	"notifications",
+}
+
+func IsValidAPIKeyScope(scope string) bool {
+	resource, access, ok := strings.Cut(scope, ":")
+	if !ok || (access != "read" && access != "write") {
+		return false
+	}
+	for _, r := range APIKeyResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAPIKey returns a new plaintext key along with the short prefix that
+// is stored alongside its hash so users can tell their keys apart.
+func GenerateAPIKey() (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %v", err)
+	}
+	body := base64.RawURLEncoding.EncodeToString(secret)
+	return APIKeyPrefix + body, body[:8], nil
+}
+
+// HashToken returns the SHA-256 hex digest we store in place of API keys and
+// refresh tokens.
+func HashToken(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
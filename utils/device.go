@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DescribeDevice turns a User-Agent header into a short "Browser on OS" label
+// that's stable across browser updates.
+func DescribeDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	case strings.Contains(ua, "postman"):
+		browser = "Postman"
+	}
+
+	os := "unknown OS"
+	switch {
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "mac os x") || strings.Contains(ua, "macintosh"):
+		os = "macOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	return browser + " on " + os
+}
+
+// ClientIP returns the address of the client, preferring the proxy headers set
+// by Vercel and other load balancers.
+func ClientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		first, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(first)
+	}
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// LocationHint builds a coarse "City, Region, Country" string from the geo
+// headers added by Vercel or Cloudflare. It returns "" when none are present.
+func LocationHint(r *http.Request) string {
+	var parts []string
+	for _, header := range []string{"X-Vercel-IP-City", "X-Vercel-IP-Country-Region", "X-Vercel-IP-Country"} {
+		if value := r.Header.Get(header); value != "" {
+			if unescaped, err := url.QueryUnescape(value); err == nil {
+				value = unescaped
+			}
+			parts = append(parts, value)
+		}
+	}
+	if len(parts) == 0 {
+		if country := r.Header.Get("CF-IPCountry"); country != "" && country != "XX" {
+			parts = append(parts, country)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/MyoMyatMin/expertly-backend/pkg/database"
+	"github.com/google/uuid"
 )
 
 func GenerateUniqueUsername(name string, db *database.Queries, r *http.Request) (string, error) {
@@ -84,3 +85,7 @@ func checkSlugExists(slug string, db *database.Queries, r *http.Request) (bool,
 	}
 	return true, nil
 }
+
+// DeletedUserID is the placeholder account that owns comments left behind by
+// deleted users (see sql/schema/017_account_deletions.sql).
+var DeletedUserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Length limits for free-text profile fields, counted in characters.
+const (
+	MaxBioLength      = 500
+	MaxHeadlineLength = 120
+	MaxLocationLength = 100
+	MaxPronounsLength = 40
+)
+
+// SocialLinkPlatforms lists the networks a profile can link to.
+var SocialLinkPlatforms = []string{
+	"github",
+	"linkedin",
+	"x",
+	"mastodon",
+	"youtube",
+	"instagram",
+	"facebook",
+}
+
+// NormalizeProfileURL checks that a user-supplied link is an absolute http(s)
+// URL with a host and returns it in canonical form. An empty string is
+// allowed and means "no link".
+func NormalizeProfileURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	if len(raw) > 2048 {
+		return "", errors.New("url is too long")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q", raw)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("url %q must start with http:// or https://", raw)
+	}
+	if u.Host == "" || u.User != nil {
+		return "", fmt.Errorf("invalid url %q", raw)
+	}
+	return u.String(), nil
+}
+
+// NormalizeSocialLinks validates a platform -> URL map, dropping empty entries.
+func NormalizeSocialLinks(links map[string]string) (map[string]string, error) {
+	normalized := map[string]string{}
+	for platform, link := range links {
+		known := false
+		for _, p := range SocialLinkPlatforms {
+			if p == platform {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unsupported social link %q", platform)
+		}
+
+		link, err := NormalizeProfileURL(link)
+		if err != nil {
+			return nil, err
+		}
+		if link != "" {
+			normalized[platform] = link
+		}
+	}
+	return normalized, nil
+}
+
+// CheckTextLength reports an error naming field when value is too long.
+func CheckTextLength(field, value string, max int) error {
+	if utf8.RuneCountInString(value) > max {
+		return fmt.Errorf("%s must be at most %d characters", field, max)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MaxPostTags   = 5
+	MaxTagLength  = 30
+	tagSeparators = " _"
+)
+
+// NormalizeTags lowercases tags, turns spaces into hyphens and drops
+// duplicates, keeping the order they were given in. Tags may only contain
+// letters, digits and "-", "+", "#" or ".", so "C++" and "C#" survive.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		tag = strings.Join(strings.FieldsFunc(tag, func(r rune) bool {
+			return strings.ContainsRune(tagSeparators, r)
+		}), "-")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+		}
+		for _, r := range tag {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-+#.", r) {
+				return nil, fmt.Errorf("tag %q contains an invalid character", tag)
+			}
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxPostTags {
+		return nil, fmt.Errorf("a post can have at most %d tags", MaxPostTags)
+	}
+	return normalized, nil
+}